@@ -0,0 +1,828 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/derat/mbstats"
+)
+
+// This file contains the functions that run commands listed in commandGroups.
+// Each returns the program's exit code.
+
+func runEditor(r *runner) int {
+	o := r.opts
+	// Avoid reading the whole year's stats if possible.
+	stats := readIndexedEditors(r.dir(), o.year, func(ie *mbstats.EditorIndexEntry) bool {
+		return strings.EqualFold(ie.Name, r.arg)
+	})
+	es := findEditor(stats, r.arg)
+	if es == nil {
+		var ret int
+		if stats, _, ret = r.doSingleYearEditsCmd(o.year, ""); ret != 0 {
+			return ret
+		}
+		es = findEditor(stats, r.arg)
+	}
+	if es == nil {
+		fmt.Fprintf(os.Stderr, "No editor named %q in %d\n", r.arg, o.year)
+		if names := closestNames(stats, r.arg, 5); len(names) > 0 {
+			fmt.Fprintln(os.Stderr, "Closest names:", strings.Join(names, ", "))
+		}
+		return 1
+	}
+	return r.write(editorEditsTable(es))
+}
+
+func runEditorID(r *runner) int {
+	o := r.opts
+	v, err := strconv.Atoi(r.arg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Bad editor ID %q\n", r.arg)
+		return 2
+	}
+	id := mbstats.EditorID(v)
+	stats := readIndexedEditors(r.dir(), o.year, func(ie *mbstats.EditorIndexEntry) bool {
+		return ie.ID == id
+	})
+	if stats == nil {
+		var ret int
+		if stats, _, ret = r.doSingleYearEditsCmd(o.year, ""); ret != 0 {
+			return ret
+		}
+	}
+	for _, es := range stats {
+		if es.ID == id {
+			t := newTable(column{name: "id"}, column{name: "name", left: true})
+			t.add(es.ID, es.Name)
+			return r.write(t, editorEditsTable(&es))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "No editor with ID %d in %d\n", id, o.year)
+	return 1
+}
+
+func runEditorHistory(r *runner) int {
+	o := r.opts
+	dirStats, _, ret := r.doYearlyEditsCmd("")
+	if ret != 0 {
+		return ret
+	}
+	sets := []typeSet{nil}
+	names := []string{"edits"}
+	if o.editorHistoryTypes != "" {
+		more, err := splitTypeSets(o.editorHistoryTypes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed looking up %q: %v\n", o.editorHistoryTypes, err)
+			return 2
+		}
+		sets = append(sets, more...)
+		names = append(names, strings.Split(o.editorHistoryTypes, ",")...)
+	}
+	return r.printYearlyResults(dirStats, "%6.0f", names, func(ys *yearEditorStats) []float64 {
+		vals := make([]float64, len(sets))
+		if es := findEditor(ys.stats, r.arg); es != nil {
+			for i, ts := range sets {
+				vals[i] = float64(ts.count(es))
+			}
+		}
+		return vals
+	})
+}
+
+func runEditorList(r *runner) int {
+	o := r.opts
+	stats, ts, ret := r.doSingleYearEditsCmd(o.year, r.arg)
+	if ret != 0 {
+		return ret
+	}
+	order, err := parseEditorOrder(o.sort)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Bad -sort flag:", err)
+		return 2
+	}
+	ecs := getEditorCounts(stats, ts, editorFilters(ts, o.minCount, o.excludeBots))
+	sortEditorCounts(ecs, order, o.reverse)
+	t := newTable(column{name: "edits", format: "%5d"}, column{name: "name", left: true})
+	for _, ec := range ecs {
+		t.add(ec.count, ec.es.Name)
+	}
+	return r.write(t.limit(o.limit))
+}
+
+func runEditorPercentiles(r *runner) int {
+	stats, ts, ret := r.doSingleYearEditsCmd(r.opts.year, r.arg)
+	if ret != 0 {
+		return ret
+	}
+	pcts := []float64{50, 75, 90, 99, 100}
+	t := newTable(column{name: "percentile", left: true}, column{name: "edits", format: "%6.0f"})
+	for i, v := range getPercentiles(getEditCounts(stats, ts), pcts) {
+		label := fmt.Sprintf("p%v", pcts[i])
+		if pcts[i] == 100 {
+			label = "max"
+		}
+		t.add(label, v)
+	}
+	return r.write(t)
+}
+
+func runCompareEditors(r *runner) int {
+	o := r.opts
+	var yearStats []yearEditorStats
+	if o.compareRange {
+		dirStats, _, ret := r.doYearlyEditsCmd("")
+		if ret != 0 {
+			return ret
+		}
+		yearStats = dirStats[0].Values()
+	} else {
+		stats, _, ret := r.doSingleYearEditsCmd(o.year, "")
+		if ret != 0 {
+			return ret
+		}
+		yearStats = []yearEditorStats{{period: mbstats.YearPeriod(o.year), stats: stats}}
+	}
+	names := strings.Split(r.arg, ",")
+	counts := make([]map[mbstats.EditType]int, len(names))
+	for i, name := range names {
+		counts[i] = make(map[mbstats.EditType]int)
+		var found bool
+		for _, ys := range yearStats {
+			if es := findEditor(ys.stats, name); es != nil {
+				found = true
+				for et, cnt := range es.Edits {
+					counts[i][et] += int(cnt)
+				}
+			}
+		}
+		if !found {
+			fmt.Fprintf(os.Stderr, "No editor named %q\n", name)
+			return 1
+		}
+	}
+	return r.write(editorComparisonTable(names, counts))
+}
+
+func runWhois(r *runner) int {
+	hists, err := readEditorNames(filepath.Join(r.dir(), "editor-names.json"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed reading editor names:", err)
+		return 1
+	}
+	const dateLayout = "2006-01-02"
+	t := newTable(column{name: "id", format: "%8d"}, column{name: "first"},
+		column{name: "last"}, column{name: "name", left: true})
+	for _, hist := range hists {
+		var found bool
+		for _, n := range hist.Names {
+			if n.Name == r.arg {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		// The last name is the one that the account currently uses.
+		for _, n := range hist.Names {
+			t.add(hist.ID, n.First.Format(dateLayout), n.Last.Format(dateLayout), n.Name)
+		}
+	}
+	return r.write(t)
+}
+
+func runVoterList(r *runner) int {
+	o := r.opts
+	stats, _, ret := r.doSingleYearEditsCmd(o.year, "")
+	if ret != 0 {
+		return ret
+	}
+	voters, err := readVoterStats(filepath.Join(r.dir(), fmt.Sprintf("voters-%d.json", o.year)))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed reading voter stats:", err)
+		return 1
+	}
+	return r.write(voterListTable(voters, stats).limit(o.limit))
+}
+
+func runEditorHistogram(r *runner) int {
+	o := r.opts
+	patterns := []string{r.arg}
+	if o.splitTypes {
+		patterns = strings.Split(r.arg, ",")
+	}
+	sets := make([]typeSet, len(patterns))
+	for i, pattern := range patterns {
+		var err error
+		if sets[i], err = parseTypeSet(pattern); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed looking up %q: %v\n", pattern, err)
+			return 2
+		}
+	}
+	// Only the counts are needed, so avoid holding the whole year's stats in memory.
+	vals := make([][]int64, len(sets))
+	p := filepath.Join(r.dir(), fmt.Sprintf("editors-%d.json", o.year))
+	if err := streamEditorStats(r.ctx, p, r.dups, func(es *mbstats.EditorStats) error {
+		for i, ts := range sets {
+			if v := ts.count(es); v > 0 {
+				vals[i] = append(vals[i], int64(v))
+			}
+		}
+		return nil
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed reading editor stats:", err)
+		return 1
+	}
+	if !o.splitTypes {
+		return r.writeHist(r.hs.histogram(vals[0], nil))
+	}
+	if o.histQuantiles {
+		// Buckets would differ between types.
+		fmt.Fprintln(os.Stderr, "-histogram-quantiles can't be used with -split-types")
+		return 2
+	}
+	// Chart all of the histograms together, with a series for each type.
+	// Use the same range for all of them so their buckets line up.
+	var all []int64
+	for _, v := range vals {
+		all = append(all, v...)
+	}
+	shs := r.hs.resolve(all)
+	var tables []*table
+	var chart *table
+	for i, name := range patterns {
+		h := shs.histogram(vals[i], nil)
+		t := histTable(h)
+		t.title = name
+		tables = append(tables, t)
+		if ct := histChartTable(h, name); chart == nil {
+			chart = ct
+		} else {
+			chart.cols = append(chart.cols, ct.cols[1])
+			for j := range chart.rows {
+				chart.rows[j] = append(chart.rows[j], ct.rows[j][1])
+			}
+		}
+	}
+	return r.writeCharted(chart, barChart, tables...)
+}
+
+func runAgeHistogram(r *runner) int {
+	o := r.opts
+	stats, ts, ret := r.doSingleYearEditsCmd(o.year, r.arg)
+	if ret != 0 {
+		return ret
+	}
+	md, err := readMetadata(r.dir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed reading metadata:", err)
+		return 1
+	}
+	return r.writeHist(ageHistogram(stats, ts, md.YearStart(o.year+1)))
+}
+
+func runYearlyHistogram(r *runner) int {
+	dirStats, ts, ret := r.doYearlyEditsCmd(r.arg)
+	if ret != 0 {
+		return ret
+	}
+	t := yearlyHistogramTable(dirStats[0].Values(), ts, r.hs)
+	return r.writeCharted(t, barChart, t)
+}
+
+func runHeatmap(r *runner) int {
+	names := strings.Split(r.arg, ",")
+	if len(names) != 2 {
+		fmt.Fprintln(os.Stderr, "-heatmap requires two comma-separated edit types")
+		return 2
+	}
+	sets, err := splitTypeSets(r.arg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed looking up %q: %v\n", r.arg, err)
+		return 2
+	}
+	stats, _, ret := r.doSingleYearEditsCmd(r.opts.year, "")
+	if ret != 0 {
+		return ret
+	}
+	return r.write(editorHeatmap(stats, sets[0], sets[1], r.hs).table(names[0], names[1]))
+}
+
+func runTypeDiversity(r *runner) int {
+	stats, _, ret := r.doSingleYearEditsCmd(r.opts.year, "")
+	if ret != 0 {
+		return ret
+	}
+	return r.writeHist(typeDiversityHistogram(stats, r.hs))
+}
+
+func runNoteHistogram(r *runner) int {
+	notes, err := readNoteStats(filepath.Join(r.dir(), fmt.Sprintf("notes-%d.json", r.opts.year)))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed reading note stats:", err)
+		return 1
+	}
+	return r.writeHist(noteHistogram(notes, r.hs))
+}
+
+func runYearlyEdits(r *runner) int {
+	o := r.opts
+	dirStats, ret := doYearlySumCmd(r.ctx, r.args, o.minYear, o.maxYear, r.dups, r.arg,
+		func(ts typeSet) yearlyFunc {
+			return r.typeColumns(r.arg, ts, func(ts typeSet) yearlyFunc {
+				return func(ys *yearEditorStats) []float64 {
+					return []float64{float64(countEdits(ys.stats, ts))}
+				}
+			})
+		})
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%6.0f", r.typeNames(r.arg, "edits"), summedYearly)
+}
+
+func runYearlyEditors(r *runner) int {
+	o := r.opts
+	dirStats, ret := doYearlySumCmd(r.ctx, r.args, o.minYear, o.maxYear, r.dups, r.arg,
+		func(ts typeSet) yearlyFunc {
+			return r.typeColumns(r.arg, ts, func(ts typeSet) yearlyFunc {
+				return func(ys *yearEditorStats) []float64 {
+					return []float64{float64(countEditors(ys.stats, ts, o.minCount))}
+				}
+			})
+		})
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%5.0f", r.typeNames(r.arg, "editors"), summedYearly)
+}
+
+func runYearlyAge(r *runner) int {
+	dirStats, ts, ret := r.doYearlyEditsCmd(r.arg)
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%1.1f", r.typeNames(r.arg, "median_age", "mean_age"), r.typeColumns(r.arg, ts,
+		func(ts typeSet) yearlyFunc {
+			return func(ys *yearEditorStats) []float64 {
+				median, mean := getEditorAgeStats(ys.stats, ts, ys.end)
+				return []float64{median, mean}
+			}
+		}))
+}
+
+func runYearlyAutoRatio(r *runner) int {
+	dirStats, ts, ret := r.doYearlyEditsCmd(r.arg)
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%5.3f", r.typeNames(r.arg, "autoedit_ratio"), r.typeColumns(r.arg, ts,
+		func(ts typeSet) yearlyFunc {
+			return func(ys *yearEditorStats) []float64 {
+				return []float64{getAutoEditRatio(ys.stats, ts)}
+			}
+		}))
+}
+
+func runYearlyBotSplit(r *runner) int {
+	dirStats, _, ret := r.doYearlyEditsCmd("")
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%6.0f",
+		[]string{"human_edits", "bot_edits", "human_editors", "bot_editors"},
+		func(ys *yearEditorStats) []float64 { return getBotSplit(ys.stats) })
+}
+
+func runYearlyChurn(r *runner) int {
+	dirStats, _, ret := r.doYearlyEditsCmd("")
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%5.0f", []string{"editors", "lost", "new"},
+		crossYearly(dirStats, getYearlyChurn))
+}
+
+func runYearlyComebacks(r *runner) int {
+	gap := r.opts.comebackGap
+	if gap < 1 {
+		fmt.Fprintln(os.Stderr, "-comeback-gap must be positive")
+		return 2
+	}
+	dirStats, _, ret := r.doYearlyEditsCmd("")
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%5.0f", []string{"comebacks"}, crossYearly(dirStats,
+		func(all []yearEditorStats) [][]float64 {
+			return getYearlyComebacks(all, gap)
+		}))
+}
+
+func runYearlyConcentration(r *runner) int {
+	dirStats, ts, ret := r.doYearlyEditsCmd(r.arg)
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%5.1f", r.typeNames(r.arg, "top1_pct", "top5_pct", "top10_pct"), r.typeColumns(r.arg, ts,
+		func(ts typeSet) yearlyFunc {
+			return func(ys *yearEditorStats) []float64 {
+				return getTopShares(getEditCounts(ys.stats, ts), []float64{1, 5, 10})
+			}
+		}))
+}
+
+func runYearlyCumulative(r *runner) int {
+	dirStats, ts, ret := r.doYearlyEditsCmd(r.arg)
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%8.0f", r.typeNames(r.arg, "cumulative_edits"), r.typeColumns(r.arg, ts,
+		func(ts typeSet) yearlyFunc {
+			return crossYearly(dirStats, func(yearStats []yearEditorStats) [][]float64 {
+				return getYearlyCumulative(yearStats, ts)
+			})
+		}))
+}
+
+func runYearlyDormant(r *runner) int {
+	dirStats, _, ret := r.doYearlyEditsCmd("")
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%5.0f",
+		[]string{"dormant_1y", "dormant_2y", "dormant_5y"}, crossYearly(dirStats, getYearlyDormant))
+}
+
+func runYearlyGini(r *runner) int {
+	dirStats, ts, ret := r.doYearlyEditsCmd(r.arg)
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%5.3f", r.typeNames(r.arg, "gini"), r.typeColumns(r.arg, ts,
+		func(ts typeSet) yearlyFunc {
+			return func(ys *yearEditorStats) []float64 {
+				return []float64{getGini(getEditCounts(ys.stats, ts))}
+			}
+		}))
+}
+
+func runYearlyGrowth(r *runner) int {
+	dirStats, ts, ret := r.doYearlyEditsCmd(r.arg)
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%+6.1f", r.typeNames(r.arg, "edits_change_pct", "editors_change_pct"), r.typeColumns(r.arg, ts,
+		func(ts typeSet) yearlyFunc {
+			return crossYearly(dirStats, func(yearStats []yearEditorStats) [][]float64 {
+				return getYearlyGrowth(yearStats, ts)
+			})
+		}))
+}
+
+func runYearlyNewEditors(r *runner) int {
+	o := r.opts
+	if o.newEditorsBy != "created" && o.newEditorsBy != "first-edit" {
+		fmt.Fprintf(os.Stderr, "Bad -new-editors-by value %q\n", o.newEditorsBy)
+		return 2
+	}
+	byCreated := o.newEditorsBy == "created"
+	dirStats, _, ret := r.doYearlyEditsCmd("")
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%5.0f", []string{"new_editors"}, crossYearly(dirStats,
+		func(all []yearEditorStats) [][]float64 {
+			return getYearlyNewEditors(all, byCreated, o.minCount)
+		}))
+}
+
+func runYearlyNewcomerEdits(r *runner) int {
+	dirStats, ts, ret := r.doYearlyEditsCmd(r.arg)
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%5.1f", r.typeNames(r.arg, "newcomer_pct", "veteran_pct"), r.typeColumns(r.arg, ts,
+		func(ts typeSet) yearlyFunc {
+			return func(ys *yearEditorStats) []float64 {
+				return getNewcomerSplit(ys, ts)
+			}
+		}))
+}
+
+func runYearlyNotes(r *runner) int {
+	dirStats, _, ret := r.doYearlyEditsCmd("")
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%6.0f",
+		[]string{"note_writers", "notes", "notes_per_100_edits"}, getNoteSummary)
+}
+
+func runYearlyPercentiles(r *runner) int {
+	dirStats, ts, ret := r.doYearlyEditsCmd(r.arg)
+	if ret != 0 {
+		return ret
+	}
+	pcts := []float64{25, 50, 75, 90, 99}
+	return r.printYearlyResults(dirStats, "%6.0f", r.typeNames(r.arg, "p25", "p50", "p75", "p90", "p99"), r.typeColumns(r.arg, ts,
+		func(ts typeSet) yearlyFunc {
+			return func(ys *yearEditorStats) []float64 {
+				return getPercentiles(getEditCounts(ys.stats, ts), pcts)
+			}
+		}))
+}
+
+func runYearlySummary(r *runner) int {
+	dirStats, ts, ret := r.doYearlyEditsCmd(r.arg)
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%7.1f", r.typeNames(r.arg, "mean", "median", "stddev", "max"), r.typeColumns(r.arg, ts,
+		func(ts typeSet) yearlyFunc {
+			return func(ys *yearEditorStats) []float64 {
+				return getSummary(getEditCounts(ys.stats, ts))
+			}
+		}))
+}
+
+func runYearlyVoters(r *runner) int {
+	dirStats, _, ret := r.doYearlyEditsCmd("")
+	if ret != 0 {
+		return ret
+	}
+	return r.printYearlyResults(dirStats, "%6.0f",
+		[]string{"voters", "votes", "votes_per_100_edits"}, getVoterSummary)
+}
+
+func runMonthlyEdits(r *runner) int {
+	dirStats, ts, ret := r.doMonthlyEditsCmd(r.arg)
+	if ret != 0 {
+		return ret
+	}
+	return r.printMonthlyResults(dirStats, "%6.0f", r.typeNames(r.arg, "edits"),
+		r.typeColumns(r.arg, ts, func(ts typeSet) yearlyFunc {
+			return func(ys *yearEditorStats) []float64 {
+				return []float64{float64(countEdits(ys.stats, ts))}
+			}
+		}))
+}
+
+func runMonthlyEditors(r *runner) int {
+	dirStats, ts, ret := r.doMonthlyEditsCmd(r.arg)
+	if ret != 0 {
+		return ret
+	}
+	return r.printMonthlyResults(dirStats, "%5.0f", r.typeNames(r.arg, "editors"),
+		r.typeColumns(r.arg, ts, func(ts typeSet) yearlyFunc {
+			return func(ys *yearEditorStats) []float64 {
+				return []float64{float64(countEditors(ys.stats, ts, r.opts.minCount))}
+			}
+		}))
+}
+
+func runAgeEdits(r *runner) int {
+	o := r.opts
+	stats, ts, ret := r.doSingleYearEditsCmd(o.year, r.arg)
+	if ret != 0 {
+		return ret
+	}
+	md, err := readMetadata(r.dir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed reading metadata:", err)
+		return 1
+	}
+	tables, err := ageEditsTables(stats, ts, md.YearStart(o.year+1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed computing correlation:", err)
+		return 1
+	}
+	return r.write(tables...)
+}
+
+func runCorrelations(r *runner) int {
+	o := r.opts
+	method, err := parseCorrMethod(o.corrMethod)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Bad -correlation-method flag:", err)
+		return 2
+	}
+	stats, _, ret := r.doSingleYearEditsCmd(o.year, "")
+	if ret != 0 {
+		return ret
+	}
+	var t *table
+	switch o.corrFormat {
+	case "list":
+		t, err = editTypeCorrelationsTable(stats, method, o.corrThreshold)
+	case "matrix":
+		t, err = editTypeCorrelationMatrix(stats, method)
+		// The matrix was historically always written as CSV.
+		if r.format == formatText {
+			r.format = formatCSV
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Bad -correlation-format value %q\n", o.corrFormat)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed computing correlations:", err)
+		return 1
+	}
+	return r.write(t)
+}
+
+func runEditTypeCounts(r *runner) int {
+	stats, _, ret := r.doSingleYearEditsCmd(r.opts.year, "")
+	if ret != 0 {
+		return ret
+	}
+	return r.write(editTypeCountsTable(stats, r.opts.minEditors))
+}
+
+func runLifespans(r *runner) int {
+	dirStats, _, ret := r.doYearlyEditsCmd("")
+	if ret != 0 {
+		return ret
+	}
+	return r.write(lifespanTables(dirStats[0].Values())...)
+}
+
+func runLorenz(r *runner) int {
+	o := r.opts
+	stats, ts, ret := r.doSingleYearEditsCmd(o.year, r.arg)
+	if ret != 0 {
+		return ret
+	}
+	t := newTable(column{name: "editors_pct", format: "%6.2f"}, column{name: "edits_pct", format: "%6.2f"})
+	for _, pt := range getLorenz(getEditCounts(stats, ts), o.lorenzPoints) {
+		t.add(pt[0], pt[1])
+	}
+	return r.write(t)
+}
+
+func runOverlap(r *runner) int {
+	var y1, y2 int
+	if _, err := fmt.Sscanf(r.arg, "%d,%d", &y1, &y2); err != nil {
+		fmt.Fprintf(os.Stderr, "Bad -overlap value %q (want e.g. 2019,2023)\n", r.arg)
+		return 2
+	}
+	stats1, ts, ret := r.doSingleYearEditsCmd(y1, r.opts.overlapType)
+	if ret != 0 {
+		return ret
+	}
+	stats2, _, ret := r.doSingleYearEditsCmd(y2, "")
+	if ret != 0 {
+		return ret
+	}
+	both, only1, only2 := getOverlap(stats1, stats2, ts)
+	t := newTable(column{name: "set", left: true}, column{name: "editors", format: "%6d"})
+	t.add("both", both)
+	t.add(fmt.Sprintf("only %d", y1), only1)
+	t.add(fmt.Sprintf("only %d", y2), only2)
+	return r.write(t)
+}
+
+func runPowerLaw(r *runner) int {
+	o := r.opts
+	if o.powerLawMin < 1 {
+		fmt.Fprintln(os.Stderr, "-power-law-min must be positive")
+		return 2
+	}
+	stats, ts, ret := r.doSingleYearEditsCmd(o.year, r.arg)
+	if ret != 0 {
+		return ret
+	}
+	return r.write(distFitsTable(getEditCounts(stats, ts), float64(o.powerLawMin)))
+}
+
+func runRetention(r *runner) int {
+	dirStats, _, ret := r.doYearlyEditsCmd("")
+	if ret != 0 {
+		return ret
+	}
+	return r.write(retentionTable(dirStats[0].Values()))
+}
+
+func runSeasonality(r *runner) int {
+	dirStats, ts, ret := r.doMonthlyEditsCmd(r.arg)
+	if ret != 0 {
+		return ret
+	}
+	// Print the mean, the percent difference from the mean of all months, and
+	// the number of complete years in which the month was above average.
+	t := newTable(column{name: "month", left: true}, column{name: "mean", format: "%8.1f"},
+		column{name: "rel_pct", format: "%+6.1f%%"}, column{name: "above", format: "%2d"},
+		column{name: "years", format: "of %d"})
+	for _, ms := range getSeasonality(dirStats[0].Values(), ts) {
+		t.add(ms.month.String()[:3], ms.mean, ms.rel, ms.above, ms.years)
+	}
+	return r.write(t)
+}
+
+func runSurvival(r *runner) int {
+	if r.opts.survivalYears < 1 {
+		fmt.Fprintln(os.Stderr, "-survival-years must be positive")
+		return 2
+	}
+	dirStats, _, ret := r.doYearlyEditsCmd("")
+	if ret != 0 {
+		return ret
+	}
+	return r.write(survivalTable(getSurvival(dirStats[0].Values(), r.opts.survivalYears)))
+}
+
+func runTypeTrends(r *runner) int {
+	dirStats, _, ret := r.doYearlyEditsCmd("")
+	if ret != 0 {
+		return ret
+	}
+	// Print the slope in edits per year and as a percentage of the mean.
+	t := newTable(column{name: "slope", format: "%+9.1f"},
+		column{name: "slope_pct", format: "%+7.1f%%"}, column{name: "type", left: true})
+	for _, tt := range getTypeTrends(dirStats[0].Values()) {
+		t.add(tt.slope, 100*tt.slope/tt.mean, mbstats.EditTypeName(tt.et))
+	}
+	return r.write(t)
+}
+
+func runWeekdays(r *runner) int {
+	days, err := readWeekdays(filepath.Join(r.dir(), "weekdays.json"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed reading weekday counts:", err)
+		return 1
+	}
+	md, err := readMetadata(r.dir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed reading metadata:", err)
+		return 1
+	}
+	return r.write(weekdaysTable(days, md, r.opts.minYear, r.opts.maxYear))
+}
+
+func runServe(r *runner) int {
+	o := r.opts
+	if o.serveCacheYears < 1 {
+		fmt.Fprintln(os.Stderr, "-serve-cache-years must be positive")
+		return 2
+	}
+	srv, err := newServer(r.ctx, r.dir(), r.dups, o.serveCacheYears)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed initializing server:", err)
+		return 1
+	}
+	fmt.Fprintln(os.Stderr, "Serving dashboard at", r.arg)
+	hs := &http.Server{Addr: r.arg, Handler: srv.handler(),
+		BaseContext: func(net.Listener) context.Context { return r.ctx }}
+	go func() {
+		<-r.ctx.Done()
+		hs.Shutdown(context.Background())
+	}()
+	if err := hs.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		fmt.Fprintln(os.Stderr, "Failed serving:", err)
+		return 1
+	}
+	return 0
+}
+
+func runListTypes(r *runner) int {
+	return r.write(editTypesTable(r.arg))
+}
+
+func runCheck(r *runner) int {
+	ret := 0
+	for _, dir := range r.args {
+		errs := mbstats.ValidateDir(dir)
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if len(errs) > 0 {
+			ret = 1
+		} else {
+			fmt.Fprintln(r.stdout, dir+": OK")
+		}
+	}
+	return ret
+}
+
+func runSelfTest(r *runner) int {
+	errs := selfTest()
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if len(errs) > 0 {
+		return 1
+	}
+	fmt.Fprintln(r.stdout, "OK")
+	return 0
+}
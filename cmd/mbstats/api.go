@@ -0,0 +1,97 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// handleAPI handles REST requests under /api (which has already been stripped from req's path).
+// All endpoints return JSON arrays of objects:
+//
+//	/years                       years with stats
+//	/year/<year>/edit-types      editors and edits for each edit type
+//	/year/<year>/editors         editors ranked by edits (accepts "type" and "limit")
+//	/year/<year>/editor/<name>   named editor's edits for each edit type
+//	/editor/<name>/history       named editor's yearly edits (accepts "type")
+func (srv *server) handleAPI(w http.ResponseWriter, req *http.Request) error {
+	// Split the escaped path so that names can contain (escaped) slashes.
+	parts := strings.Split(strings.Trim(req.URL.EscapedPath(), "/"), "/")
+	for i, p := range parts {
+		var err error
+		if parts[i], err = url.PathUnescape(p); err != nil {
+			return badRequest("bad path component %q", p)
+		}
+	}
+	notFound := &httpError{http.StatusNotFound, fmt.Sprintf("unknown endpoint %q", req.URL.Path)}
+
+	switch {
+	case len(parts) == 1 && parts[0] == "years":
+		return srv.handleYears(w, req)
+
+	case len(parts) >= 3 && parts[0] == "year":
+		if _, err := strconv.Atoi(parts[1]); err != nil {
+			return badRequest("bad year %q", parts[1])
+		}
+		// Pass the year to the handlers as a query parameter.
+		q := req.URL.Query()
+		q.Set("year", parts[1])
+		req.URL.RawQuery = q.Encode()
+		year, err := srv.yearParam(req)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case len(parts) == 3 && parts[2] == "edit-types":
+			stats, err := srv.yearStats(req.Context(), year)
+			if err != nil {
+				return err
+			}
+			return writeTable(w, req, editTypeCountsTable(stats, 1), nil, barChart)
+		case len(parts) == 3 && parts[2] == "editors":
+			return srv.handleLeaderboard(w, req)
+		case len(parts) == 4 && parts[2] == "editor":
+			stats, err := srv.yearStats(req.Context(), year)
+			if err != nil {
+				return err
+			}
+			es := findEditor(stats, parts[3])
+			if es == nil {
+				return &httpError{http.StatusNotFound, fmt.Sprintf("no editor named %q in %d", parts[3], year)}
+			}
+			return writeTable(w, req, editorEditsTable(es), nil, barChart)
+		}
+		return notFound
+
+	case len(parts) == 3 && parts[0] == "editor" && parts[2] == "history":
+		ts, err := typeParam(req)
+		if err != nil {
+			return err
+		}
+		t := newTable(column{name: "year", left: true}, column{name: "edits"})
+		var found bool
+		for _, year := range srv.years {
+			stats, err := srv.yearStats(req.Context(), year)
+			if err != nil {
+				return err
+			}
+			var edits float64
+			if es := findEditor(stats, parts[1]); es != nil {
+				edits = float64(ts.count(es))
+				found = true
+			}
+			t.add(srv.md.YearLabel(year), edits)
+		}
+		if !found {
+			return &httpError{http.StatusNotFound, fmt.Sprintf("no editor named %q", parts[1])}
+		}
+		return writeTable(w, req, t, nil, lineChart)
+	}
+	return notFound
+}
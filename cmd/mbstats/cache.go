@@ -0,0 +1,142 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/derat/mbstats"
+	"github.com/derat/mbstats/internal/fileutil"
+	"github.com/derat/mbstats/internal/logutil"
+)
+
+// cacheStats controls whether editor records decoded from JSON files are
+// cached in gob files under the user's cache directory, which are much faster
+// to decode. Input directories are never written.
+var cacheStats = true
+
+// statsCacheVersion is incremented when the cache format changes.
+const statsCacheVersion = 3
+
+// statsCacheMagic is written at the beginning of each cache file, before any
+// gob-encoded data. Files that don't start with it (including files written
+// in older formats) are ignored rather than decoded, since gob silently skips
+// mismatched fields and could otherwise produce empty records.
+var statsCacheMagic = fmt.Sprintf("mbstats stats cache v%d\n", statsCacheVersion)
+
+// statsCacheHeader is gob-encoded after statsCacheMagic.
+// It is followed by a gob-encoded mbstats.EditorStats for each record.
+type statsCacheHeader struct {
+	Path    string    // source file's absolute path
+	Stats   int       // mbstats.StatsVersion of cached (i.e. migrated) records
+	ModTime time.Time // source file's modification time
+	Size    int64     // source file's size
+}
+
+// statsCachePath returns the path of the cache file for the JSON file at p,
+// along with p's absolute path. Cache files are named by a hash of the
+// absolute path so that stats from different directories don't collide.
+func statsCachePath(p string) (cachePath, absPath string, err error) {
+	if absPath, err = filepath.Abs(p); err != nil {
+		return "", "", err
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(absPath))
+	return filepath.Join(dir, "mbstats", hex.EncodeToString(sum[:16])+".gob"), absPath, nil
+}
+
+// readStatsCache calls fn with each record cached for the JSON file at p,
+// which has info fi. used is false if the cache is missing or out of date,
+// in which case fn is not called. If used is true, err contains any error
+// encountered while reading the cache or returned by fn.
+func readStatsCache(p string, fi os.FileInfo, fn func(es *mbstats.EditorStats) error) (used bool, err error) {
+	cp, abs, err := statsCachePath(p)
+	if err != nil {
+		logutil.Debugf("Not using cache for %v: %v", p, err)
+		return false, nil
+	}
+	f, err := os.Open(cp)
+	if err != nil {
+		return false, nil
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	magic := make([]byte, len(statsCacheMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != statsCacheMagic {
+		logutil.Debugf("Ignoring cache with bad format for %v", p)
+		return false, nil
+	}
+	dec := gob.NewDecoder(r)
+	var hdr statsCacheHeader
+	if err := dec.Decode(&hdr); err != nil {
+		logutil.Debugf("Ignoring bad cache for %v: %v", p, err)
+		return false, nil
+	}
+	if hdr.Path != abs || hdr.Stats != mbstats.StatsVersion || !hdr.ModTime.Equal(fi.ModTime()) || hdr.Size != fi.Size() {
+		logutil.Debugf("Ignoring stale cache for %v", p)
+		return false, nil
+	}
+	for {
+		var es mbstats.EditorStats // gob merges maps into existing values
+		if err := dec.Decode(&es); err == io.EOF {
+			return true, nil
+		} else if err != nil {
+			return true, err
+		}
+		if err := fn(&es); err != nil {
+			return true, err
+		}
+	}
+}
+
+// statsCacheWriter atomically writes a cache file for a JSON file.
+type statsCacheWriter struct {
+	of  *fileutil.OutputFile
+	enc *gob.Encoder
+}
+
+// newStatsCacheWriter returns a statsCacheWriter for the JSON file at p, which has info fi.
+func newStatsCacheWriter(p string, fi os.FileInfo) (*statsCacheWriter, error) {
+	cp, abs, err := statsCachePath(p)
+	if err != nil {
+		return nil, err
+	}
+	of, err := fileutil.CreateOutputFile(cp)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(of, statsCacheMagic); err != nil {
+		of.Abort()
+		return nil, err
+	}
+	cw := &statsCacheWriter{of, gob.NewEncoder(of)}
+	if err := cw.enc.Encode(&statsCacheHeader{abs, mbstats.StatsVersion, fi.ModTime(), fi.Size()}); err != nil {
+		of.Abort()
+		return nil, err
+	}
+	return cw, nil
+}
+
+// add writes es to the cache.
+func (cw *statsCacheWriter) add(es *mbstats.EditorStats) error {
+	return cw.enc.Encode(es)
+}
+
+// commit moves the cache file into place.
+func (cw *statsCacheWriter) commit() error { return cw.of.Commit() }
+
+// abort deletes the cache file.
+func (cw *statsCacheWriter) abort() { cw.of.Abort() }
@@ -0,0 +1,268 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/derat/mbstats/internal/fileutil"
+)
+
+const (
+	chartWidth     = 800
+	chartHeight    = 400
+	chartLeft      = 70 // left margin containing y-axis labels
+	chartRight     = 20
+	chartTop       = 20
+	chartBottom    = 40 // bottom margin containing x-axis labels
+	chartYTicks    = 5
+	chartMaxLabels = 20 // maximum x-axis labels to draw
+)
+
+// chartType describes how series are drawn in charts.
+type chartType string
+
+const (
+	lineChart chartType = "line"
+	barChart  chartType = "bar"
+)
+
+// parseChartType parses a chartType from s.
+func parseChartType(s string) (chartType, error) {
+	switch ct := chartType(s); ct {
+	case lineChart, barChart:
+		return ct, nil
+	default:
+		return "", fmt.Errorf("unknown chart type %q", s)
+	}
+}
+
+// chartColors contains colors used for successive series in charts.
+var chartColors = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+	"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+}
+
+// writeSVGChartFile writes an SVG chart of t to a file at path p.
+// See writeSVGChart.
+func writeSVGChartFile(p string, t *table, ct chartType) error {
+	of, err := fileutil.CreateOutputFile(p)
+	if err != nil {
+		return err
+	}
+	if err := writeSVGChart(of, t, ct); err != nil {
+		of.Abort()
+		return err
+	}
+	return of.Commit()
+}
+
+// chartLayout describes the positions of elements in a chart of a table.
+// t's first column contains x-axis labels and each following column
+// contains a float64 series.
+type chartLayout struct {
+	t              *table
+	ct             chartType
+	min, max, step float64 // y-axis range and distance between gridlines
+	slot           float64 // horizontal space per row in bar charts
+	barWidth       float64 // width of each series' bars in bar charts
+}
+
+const (
+	chartPlotWidth  = chartWidth - chartLeft - chartRight
+	chartPlotHeight = chartHeight - chartTop - chartBottom
+)
+
+// newChartLayout returns a chartLayout for a chart of type ct of t.
+func newChartLayout(t *table, ct chartType) (*chartLayout, error) {
+	if len(t.cols) < 2 || len(t.rows) == 0 {
+		return nil, fmt.Errorf("no data to chart")
+	}
+
+	// Include zero in the y-axis so that differences between values
+	// aren't exaggerated.
+	var min, max float64
+	for _, row := range t.rows {
+		for _, v := range row[1:] {
+			if f, ok := chartValue(v); ok {
+				min = math.Min(min, f)
+				max = math.Max(max, f)
+			}
+		}
+	}
+	if max == min {
+		max = min + 1
+	}
+	step := niceStep((max - min) / chartYTicks)
+	slot := float64(chartPlotWidth) / float64(len(t.rows))
+	return &chartLayout{
+		t:        t,
+		ct:       ct,
+		min:      math.Floor(min/step) * step,
+		max:      math.Ceil(max/step) * step,
+		step:     step,
+		slot:     slot,
+		barWidth: 0.8 * slot / float64(len(t.cols)-1),
+	}, nil
+}
+
+// x returns the horizontal position of row i.
+func (l *chartLayout) x(i int) float64 {
+	if l.ct == barChart {
+		return chartLeft + (float64(i)+0.5)*l.slot
+	}
+	if len(l.t.rows) == 1 {
+		return chartLeft + chartPlotWidth/2
+	}
+	return chartLeft + float64(i)*chartPlotWidth/float64(len(l.t.rows)-1)
+}
+
+// y returns the vertical position of value v.
+func (l *chartLayout) y(v float64) float64 {
+	return chartTop + chartPlotHeight*(l.max-v)/(l.max-l.min)
+}
+
+// barX returns the left edge of the bar for row i in column c.
+func (l *chartLayout) barX(i, c int) float64 {
+	return l.x(i) - 0.4*l.slot + float64(c-1)*l.barWidth
+}
+
+// baseline returns the vertical position of the x-axis.
+func (l *chartLayout) baseline() float64 {
+	return l.y(math.Max(l.min, 0))
+}
+
+// yTicks returns the values at which horizontal gridlines are drawn.
+func (l *chartLayout) yTicks() []float64 {
+	var ticks []float64
+	for i := 0; i <= int(math.Round((l.max-l.min)/l.step)); i++ {
+		ticks = append(ticks, l.min+float64(i)*l.step)
+	}
+	return ticks
+}
+
+// labelEvery returns the interval between rows that receive x-axis labels.
+func (l *chartLayout) labelEvery() int {
+	return (len(l.t.rows) + chartMaxLabels - 1) / chartMaxLabels
+}
+
+// legendY returns the vertical position of column c's legend entry.
+func (l *chartLayout) legendY(c int) int {
+	return chartTop + 8 + 16*(c-1)
+}
+
+// writeSVGChart writes an SVG chart of type ct to w.
+// t's first column contains x-axis labels and each following column
+// contains a float64 series. Missing values leave gaps in lines and bars.
+func writeSVGChart(w io.Writer, t *table, ct chartType) error {
+	l, err := newChartLayout(t, ct)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" `+
+		`font-family="sans-serif" font-size="12">`+"\n", chartWidth, chartHeight)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="white"/>`+"\n", chartWidth, chartHeight)
+
+	// Draw horizontal gridlines and y-axis labels.
+	for _, v := range l.yTicks() {
+		y := l.y(v)
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#ddd"/>`+"\n",
+			chartLeft, y, chartWidth-chartRight, y)
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" dominant-baseline="middle">%s</text>`+"\n",
+			chartLeft-6, y, strconv.FormatFloat(v, 'f', -1, 64))
+	}
+
+	// Draw x-axis labels, skipping some if there are too many.
+	every := l.labelEvery()
+	for i, row := range t.rows {
+		if i%every != 0 {
+			continue
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n",
+			l.x(i), chartHeight-chartBottom+18, html.EscapeString(fmt.Sprint(row[0])))
+	}
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black"/>`+"\n",
+		chartLeft, chartTop, chartLeft, chartHeight-chartBottom)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="black"/>`+"\n",
+		chartLeft, l.baseline(), chartWidth-chartRight, l.baseline())
+
+	// Draw each series as bars or as one or more polylines, plus a legend entry.
+	for c := 1; c < len(t.cols); c++ {
+		color := chartColors[(c-1)%len(chartColors)]
+		if ct == barChart {
+			zero := l.baseline()
+			for i, row := range t.rows {
+				if f, ok := chartValue(row[c]); ok {
+					y := l.y(f)
+					fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n",
+						l.barX(i, c), math.Min(y, zero), l.barWidth, math.Abs(zero-y), color)
+				}
+			}
+		} else {
+			var pts [][2]float64
+			flush := func() {
+				if len(pts) == 1 {
+					fmt.Fprintf(&b, `<circle r="3" cx="%.1f" cy="%.1f" fill="%s"/>`+"\n",
+						pts[0][0], pts[0][1], color)
+				} else if len(pts) > 1 {
+					strs := make([]string, len(pts))
+					for i, pt := range pts {
+						strs[i] = fmt.Sprintf("%.1f,%.1f", pt[0], pt[1])
+					}
+					fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`+"\n",
+						color, strings.Join(strs, " "))
+				}
+				pts = pts[:0]
+			}
+			for i, row := range t.rows {
+				if f, ok := chartValue(row[c]); ok {
+					pts = append(pts, [2]float64{l.x(i), l.y(f)})
+				} else {
+					flush()
+				}
+			}
+			flush()
+		}
+
+		if len(t.cols) > 2 {
+			y := l.legendY(c)
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="10" height="10" fill="%s"/>`+"\n",
+				chartLeft+10, y-5, color)
+			fmt.Fprintf(&b, `<text x="%d" y="%d" dominant-baseline="middle">%s</text>`+"\n",
+				chartLeft+26, y, html.EscapeString(t.cols[c].name))
+		}
+	}
+
+	b.WriteString("</svg>\n")
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// chartValue returns v as a float64 and true if it can be charted.
+func chartValue(v interface{}) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok && !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// niceStep returns a round number (1, 2, or 5 times a power of 10) close to v.
+func niceStep(v float64) float64 {
+	exp := math.Pow(10, math.Floor(math.Log10(v)))
+	switch f := v / exp; {
+	case f <= 1:
+		return exp
+	case f <= 2:
+		return 2 * exp
+	case f <= 5:
+		return 5 * exp
+	default:
+		return 10 * exp
+	}
+}
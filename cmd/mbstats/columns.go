@@ -0,0 +1,115 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"sort"
+
+	"github.com/derat/mbstats"
+	gostats "github.com/montanaflynn/stats"
+)
+
+// editColumns contains per-editor edit counts in a columnar layout, with a
+// dense slice of counts for each edit type. This is much faster to scan than
+// looking up each type in each editor's map when the same counts are examined
+// repeatedly (e.g. when comparing all pairs of types or computing percentiles).
+type editColumns struct {
+	types   []mbstats.EditType    // types with edits, in ascending order
+	counts  []gostats.Float64Data // parallel to types, indexed by editor
+	editors int                   // number of editors
+}
+
+// newEditColumns returns an editColumns containing the edits in stats with types
+// in ts. Editors are indexed by their positions in stats.
+func newEditColumns(stats []mbstats.EditorStats, ts typeSet) *editColumns {
+	indexes := make(map[mbstats.EditType]int) // indexes into ec.types
+	for i := range stats {
+		for et := range stats[i].Edits {
+			if ts.has(et) {
+				indexes[et] = 0
+			}
+		}
+	}
+	ec := &editColumns{types: make([]mbstats.EditType, 0, len(indexes)), editors: len(stats)}
+	for et := range indexes {
+		ec.types = append(ec.types, et)
+	}
+	sort.Slice(ec.types, func(i, j int) bool { return ec.types[i] < ec.types[j] })
+
+	ec.counts = make([]gostats.Float64Data, len(ec.types))
+	for i, et := range ec.types {
+		indexes[et] = i
+		ec.counts[i] = make(gostats.Float64Data, len(stats))
+	}
+	for i := range stats {
+		for et, cnt := range stats[i].Edits {
+			if j, ok := indexes[et]; ok {
+				ec.counts[j][i] = float64(cnt)
+			}
+		}
+	}
+	return ec
+}
+
+// editorTotals returns each editor's total edits across all of ec's types.
+func (ec *editColumns) editorTotals() gostats.Float64Data {
+	sums := make(gostats.Float64Data, ec.editors)
+	for _, col := range ec.counts {
+		for i, v := range col {
+			sums[i] += v
+		}
+	}
+	return sums
+}
+
+// totals is like editorTotals but omits editors without any edits.
+func (ec *editColumns) totals() gostats.Float64Data {
+	sums := ec.editorTotals()
+	totals := sums[:0]
+	for _, v := range sums {
+		if v > 0 {
+			totals = append(totals, v)
+		}
+	}
+	return totals
+}
+
+// typeTotals returns the total number of edits of each of ec's types.
+// The returned slice is parallel to ec.types.
+func (ec *editColumns) typeTotals() []int {
+	totals := make([]int, len(ec.types))
+	for i, col := range ec.counts {
+		for _, v := range col {
+			totals[i] += int(v)
+		}
+	}
+	return totals
+}
+
+// typeEditors returns the number of editors with edits of each of ec's types.
+// The returned slice is parallel to ec.types.
+func (ec *editColumns) typeEditors() []int {
+	editors := make([]int, len(ec.types))
+	for i, col := range ec.counts {
+		for _, v := range col {
+			if v > 0 {
+				editors[i]++
+			}
+		}
+	}
+	return editors
+}
+
+// typesUsed returns the number of distinct types used by each editor.
+func (ec *editColumns) typesUsed() []int {
+	used := make([]int, ec.editors)
+	for _, col := range ec.counts {
+		for i, v := range col {
+			if v > 0 {
+				used[i]++
+			}
+		}
+	}
+	return used
+}
@@ -0,0 +1,62 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/derat/mbstats"
+	gostats "github.com/montanaflynn/stats"
+)
+
+func TestEditColumns(t *testing.T) {
+	const (
+		a mbstats.EditType = 1
+		b mbstats.EditType = 2
+		c mbstats.EditType = 5
+	)
+	stats := []mbstats.EditorStats{
+		{ID: 1, Edits: map[mbstats.EditType]int32{a: 3, c: 1}},
+		{ID: 2}, // no edits
+		{ID: 3, Edits: map[mbstats.EditType]int32{b: 2, c: 4}},
+		{ID: 4, Edits: map[mbstats.EditType]int32{a: 0}},
+	}
+
+	ec := newEditColumns(stats, nil)
+	if want := []mbstats.EditType{a, b, c}; !reflect.DeepEqual(ec.types, want) {
+		t.Errorf("types = %v; want %v", ec.types, want)
+	}
+	if want := []gostats.Float64Data{{3, 0, 0, 0}, {0, 0, 2, 0}, {1, 0, 4, 0}}; !reflect.DeepEqual(ec.counts, want) {
+		t.Errorf("counts = %v; want %v", ec.counts, want)
+	}
+	if got, want := ec.editorTotals(), (gostats.Float64Data{4, 0, 6, 0}); !reflect.DeepEqual(got, want) {
+		t.Errorf("editorTotals() = %v; want %v", got, want)
+	}
+	if got, want := ec.totals(), (gostats.Float64Data{4, 6}); !reflect.DeepEqual(got, want) {
+		t.Errorf("totals() = %v; want %v", got, want)
+	}
+	if got, want := ec.typeTotals(), []int{3, 2, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("typeTotals() = %v; want %v", got, want)
+	}
+	if got, want := ec.typeEditors(), []int{1, 1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("typeEditors() = %v; want %v", got, want)
+	}
+	if got, want := ec.typesUsed(), []int{2, 0, 2, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("typesUsed() = %v; want %v", got, want)
+	}
+
+	// Only the requested types should be included.
+	ec = newEditColumns(stats, typeSet{a: {}, c: {}})
+	if want := []mbstats.EditType{a, c}; !reflect.DeepEqual(ec.types, want) {
+		t.Errorf("types with typeSet = %v; want %v", ec.types, want)
+	}
+	if got, want := ec.totals(), (gostats.Float64Data{4, 4}); !reflect.DeepEqual(got, want) {
+		t.Errorf("totals() with typeSet = %v; want %v", got, want)
+	}
+
+	if ec := newEditColumns(nil, nil); len(ec.types) != 0 || len(ec.totals()) != 0 {
+		t.Errorf("newEditColumns(nil, nil) = %+v; want empty", ec)
+	}
+}
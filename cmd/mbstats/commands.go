@@ -0,0 +1,366 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// inputDirs describes the input dirs accepted by a command.
+type inputDirs int
+
+const (
+	oneDir    inputDirs = iota // exactly one input dir
+	multiDirs                  // one or more input dirs, with a column printed for each
+	noDirs                     // no input dirs
+)
+
+// command describes a subcommand like "mbstats yearly edits".
+// Each command can also be run by passing its action flag (e.g. "-yearly-edits TYPE"),
+// which was the original interface.
+type command struct {
+	name   string            // name within group, or empty if group has a single command
+	action string            // action flag, e.g. "yearly-edits"
+	arg    string            // positional argument, e.g. "TYPE" (empty if none or "[NAME]" if optional)
+	dirs   inputDirs         // input dirs accepted by the command
+	opts   []string          // names of additional flags accepted by the command
+	run    func(*runner) int // runs the command and returns the exit code
+	desc   string            // description, also used as the action flag's usage
+}
+
+// optionalArg returns true if c's positional argument may be omitted.
+func (c *command) optionalArg() bool { return strings.HasPrefix(c.arg, "[") }
+
+// argUsage describes c's positional arguments, e.g. "<TYPE> <INPUT_DIR>...".
+func (c *command) argUsage() string {
+	var parts []string
+	if c.optionalArg() {
+		parts = append(parts, c.arg)
+	} else if c.arg != "" {
+		parts = append(parts, "<"+c.arg+">")
+	}
+	switch c.dirs {
+	case oneDir:
+		parts = append(parts, "<INPUT_DIR>")
+	case multiDirs:
+		parts = append(parts, "<INPUT_DIR>...")
+	}
+	return strings.Join(parts, " ")
+}
+
+// commandGroup describes a group of related subcommands.
+type commandGroup struct {
+	name string
+	desc string
+	cmds []command
+}
+
+// Flags accepted by similar commands.
+var (
+	singleYearOpts = []string{"year", "duplicates", "format", "o"}
+	multiYearOpts  = []string{"min-year", "max-year", "duplicates", "format", "o"}
+	chartOpts      = []string{"chart", "chart-out", "chart-type", "gnuplot"}
+	yearlyOpts     = concatOpts(multiYearOpts, chartOpts, []string{"events", "event-window", "split-types"})
+	monthlyOpts    = concatOpts([]string{"min-month", "max-month", "duplicates", "format", "o", "split-types"}, chartOpts)
+	histStyleOpts  = []string{"histogram-percent", "histogram-unicode", "color"}
+	histOpts       = concatOpts(singleYearOpts, chartOpts, histStyleOpts,
+		[]string{"histogram-min", "histogram-max", "histogram-buckets", "histogram-quantiles", "histogram-weighted"})
+
+	// commonOpts are accepted by all commands.
+	commonOpts = []string{"q", "v", "cache"}
+)
+
+// concatOpts returns a new slice containing the elements of lists.
+func concatOpts(lists ...[]string) []string {
+	var all []string
+	for _, l := range lists {
+		all = append(all, l...)
+	}
+	return all
+}
+
+var commandGroups = []commandGroup{
+	{"editor", "Stats about individual editors", []command{
+		{"show", "editor", "NAME", oneDir, singleYearOpts, runEditor,
+			"Print edit type counts for the named editor (case-insensitive)"},
+		{"id", "editor-id", "ID", oneDir, singleYearOpts, runEditorID,
+			"Print name and edit type counts for the editor with the specified ID"},
+		{"history", "editor-history", "NAME", multiDirs, concatOpts(yearlyOpts, []string{"editor-history-types"}), runEditorHistory,
+			"Print yearly total edits for the named editor"},
+		{"list", "editor-list", "TYPE", oneDir, concatOpts(singleYearOpts, []string{"sort", "reverse", "limit", "min-count", "exclude-bots"}), runEditorList,
+			"Print editor names and edits for specified edit type"},
+		{"percentiles", "editor-percentiles", "TYPE", oneDir, singleYearOpts, runEditorPercentiles,
+			"Print percentiles of per-editor edit counts for specified edit type"},
+		{"compare", "compare-editors", "NAMES", oneDir, concatOpts(singleYearOpts, []string{"compare-range", "min-year", "max-year"}), runCompareEditors,
+			"Print edit type counts for comma-separated editors side by side"},
+		{"whois", "whois", "NAME", oneDir, []string{"format", "o"}, runWhois,
+			"Print name history of editors who have used the specified name"},
+		{"voters", "voter-list", "", oneDir, concatOpts(singleYearOpts, []string{"limit"}), runVoterList,
+			"Print votes, edits, and votes per edit for each voter (requires read-mbdump -tables=vote)"},
+	}},
+	{"histogram", "Histograms of per-editor values", []command{
+		{"edits", "editor-histogram", "TYPE", oneDir, concatOpts(histOpts, []string{"split-types"}), runEditorHistogram,
+			"Print editor edit-count histogram for specified edit type"},
+		{"age", "age-histogram", "TYPE", oneDir, concatOpts(singleYearOpts, chartOpts, histStyleOpts), runAgeHistogram,
+			"Print histogram of account ages in years of editors with specified edit type"},
+		{"yearly", "yearly-histogram", "TYPE", oneDir, concatOpts(multiYearOpts, chartOpts, []string{"histogram-min",
+			"histogram-max", "histogram-buckets", "histogram-quantiles", "histogram-weighted"}), runYearlyHistogram,
+			"Print editor edit-count histograms for specified edit type for each year side by side"},
+		{"heatmap", "heatmap", "TYPE1,TYPE2", oneDir, concatOpts(singleYearOpts, []string{"histogram-min",
+			"histogram-max", "histogram-buckets", "histogram-quantiles", "histogram-unicode"}), runHeatmap,
+			"Print 2D histogram of per-editor edit counts for two comma-separated edit types"},
+		{"types", "type-diversity", "", oneDir, histOpts, runTypeDiversity,
+			"Print histogram of number of distinct edit types used by each editor"},
+		{"notes", "note-histogram", "", oneDir, histOpts, runNoteHistogram,
+			"Print histogram of per-editor edit note counts (requires read-mbdump -tables=edit_note)"},
+	}},
+	{"yearly", "Stats for each year", []command{
+		{"edits", "yearly-edits", "TYPE", multiDirs, yearlyOpts, runYearlyEdits,
+			"Print yearly edits of specified type"},
+		{"editors", "yearly-editors", "TYPE", multiDirs, concatOpts(yearlyOpts, []string{"min-count"}), runYearlyEditors,
+			"Print yearly editors for specified edit type"},
+		{"age", "yearly-age", "TYPE", multiDirs, yearlyOpts, runYearlyAge,
+			"Print yearly median and mean account age in years of editors with specified edit type"},
+		{"autoedit-ratio", "yearly-autoedit-ratio", "TYPE", multiDirs, yearlyOpts, runYearlyAutoRatio,
+			"Print yearly fraction of edits of specified type that were autoedits"},
+		{"bot-split", "yearly-bot-split", "", multiDirs, yearlyOpts, runYearlyBotSplit,
+			"Print yearly human and bot edits followed by human and bot editors"},
+		{"churn", "yearly-churn", "", multiDirs, yearlyOpts, runYearlyChurn,
+			"Print yearly active editors, previous year's editors who never returned, and new editors"},
+		{"comebacks", "yearly-comebacks", "", multiDirs, concatOpts(yearlyOpts, []string{"comeback-gap"}), runYearlyComebacks,
+			"Print yearly editors returning after being inactive for -comeback-gap or more years"},
+		{"concentration", "yearly-concentration", "TYPE", multiDirs, yearlyOpts, runYearlyConcentration,
+			"Print yearly percent of edits of specified type made by top 1%, 5%, and 10% of editors"},
+		{"cumulative", "yearly-cumulative", "TYPE", multiDirs, yearlyOpts, runYearlyCumulative,
+			"Print running total of edits of specified type through each year"},
+		{"dormant", "yearly-dormant", "", multiDirs, yearlyOpts, runYearlyDormant,
+			"Print yearly editors who haven't logged in for 1, 2, and 5 or more years"},
+		{"gini", "yearly-gini", "TYPE", multiDirs, yearlyOpts, runYearlyGini,
+			"Print yearly Gini coefficient of per-editor edit counts for specified edit type"},
+		{"growth", "yearly-growth", "TYPE", multiDirs, yearlyOpts, runYearlyGrowth,
+			"Print yearly percent change in edits and editors for specified edit type"},
+		{"new-editors", "yearly-new-editors", "", multiDirs, concatOpts(yearlyOpts, []string{"new-editors-by", "min-count"}), runYearlyNewEditors,
+			"Print yearly new editors (see -new-editors-by and -min-count)"},
+		{"newcomer-edits", "yearly-newcomer-edits", "TYPE", multiDirs, yearlyOpts, runYearlyNewcomerEdits,
+			"Print yearly percent of edits of specified type by editors with accounts created that year and earlier"},
+		{"notes", "yearly-notes", "", multiDirs, yearlyOpts, runYearlyNotes,
+			"Print yearly note writers, edit notes, and notes per 100 edits (requires read-mbdump -tables=edit_note)"},
+		{"percentiles", "yearly-percentiles", "TYPE", multiDirs, yearlyOpts, runYearlyPercentiles,
+			"Print yearly p25, p50, p75, p90, and p99 of per-editor edits of specified type"},
+		{"summary", "yearly-summary", "TYPE", multiDirs, yearlyOpts, runYearlySummary,
+			"Print yearly mean, median, standard deviation, and max of per-editor edits of specified type"},
+		{"voters", "yearly-voters", "", multiDirs, yearlyOpts, runYearlyVoters,
+			"Print yearly voters, votes, and votes per 100 edits (requires read-mbdump -tables=vote)"},
+	}},
+	{"monthly", "Stats for each month", []command{
+		{"edits", "monthly-edits", "TYPE", multiDirs, monthlyOpts, runMonthlyEdits,
+			"Print monthly edits of specified type (requires read-mbdump -monthly)"},
+		{"editors", "monthly-editors", "TYPE", multiDirs, concatOpts(monthlyOpts, []string{"min-count"}), runMonthlyEditors,
+			"Print monthly editors for specified edit type (requires read-mbdump -monthly)"},
+	}},
+	{"analyze", "Other analyses", []command{
+		{"age-edits", "age-edits", "TYPE", oneDir, singleYearOpts, runAgeEdits,
+			"Print edit counts of specified type by editors' account ages in years"},
+		{"correlations", "correlations", "", oneDir, concatOpts(singleYearOpts,
+			[]string{"correlation-threshold", "correlation-format", "correlation-method"}), runCorrelations,
+			"Print correlations between per-editor counts of different edit types"},
+		{"edit-types", "edit-type-counts", "", oneDir, concatOpts(singleYearOpts, []string{"min-editors"}), runEditTypeCounts,
+			"Print editors and edits for each edit type"},
+		{"lifespans", "lifespans", "", oneDir, concatOpts(multiYearOpts, histStyleOpts), runLifespans,
+			"Print distribution of years between editors' first and last active years"},
+		{"lorenz", "lorenz", "TYPE", oneDir, concatOpts(singleYearOpts, []string{"lorenz-points"}), runLorenz,
+			"Print Lorenz curve of per-editor edit counts for specified edit type"},
+		{"overlap", "overlap", "YEAR1,YEAR2", oneDir, []string{"overlap-type", "duplicates", "format", "o"}, runOverlap,
+			"Print editors active in both, only the first, and only the second of two comma-separated years"},
+		{"power-law", "power-law", "TYPE", oneDir, concatOpts(singleYearOpts, []string{"power-law-min"}), runPowerLaw,
+			"Fit power-law and log-normal distributions to per-editor edits of specified type"},
+		{"retention", "retention", "", oneDir, multiYearOpts, runRetention,
+			"Print percent of editors first active in each year who were active in later years"},
+		{"seasonality", "seasonality", "TYPE", oneDir, []string{"min-month", "max-month", "duplicates", "format", "o"}, runSeasonality,
+			"Print mean monthly edits of specified type for each calendar month (requires read-mbdump -monthly)"},
+		{"survival", "survival", "", oneDir, concatOpts(multiYearOpts, []string{"survival-years"}), runSurvival,
+			"Print fraction of editors still active each year after their first active year"},
+		{"type-trends", "type-trends", "", oneDir, multiYearOpts, runTypeTrends,
+			"Print edit types ranked by linear trend in yearly edits"},
+		{"weekdays", "weekdays", "", oneDir, []string{"min-year", "max-year", "format", "o"}, runWeekdays,
+			"Print yearly percent of edits on each day of the week and on weekends (requires read-mbdump -weekdays)"},
+	}},
+	{"serve", "Serve an interactive dashboard and JSON API", []command{
+		{"", "serve", "ADDR", oneDir, []string{"duplicates", "serve-cache-years"}, runServe,
+			"Address (e.g. :8080) at which to serve an interactive dashboard and JSON API for the input dir"},
+	}},
+	{"types", "List known edit types", []command{
+		{"", "list-types", "[PATTERN]", noDirs, []string{"format", "o"}, runListTypes,
+			"Print IDs and names of edit types, optionally filtered by a substring or regular expression arg (no input dir needed)"},
+	}},
+	{"check", "Check input dirs for problems", []command{
+		{"", "check", "", multiDirs, nil, runCheck,
+			"Check input dirs for missing metadata, unparseable files, and inconsistent records"},
+	}},
+	{"selftest", "Check consistency of edit type metadata and dump schemas", []command{
+		{"", "selftest", "", noDirs, nil, runSelfTest,
+			"Check consistency of edit type metadata and dump schemas (no input dir needed)"},
+	}},
+}
+
+// printCommands writes a list of subcommands to w.
+func printCommands(w io.Writer) {
+	fmt.Fprintln(w, "Usage: mbstats <GROUP> [COMMAND] [flag]... [ARG] <INPUT_DIR>...")
+	fmt.Fprintln(w, "Run a subcommand with -h to see its flags.")
+	for _, g := range commandGroups {
+		fmt.Fprintf(w, "\n%v: %v\n", g.name, g.desc)
+		for _, c := range g.cmds {
+			fmt.Fprintf(w, "  %-30s %v\n", strings.Join(strings.Fields(g.name+" "+c.name+" "+c.arg), " "), c.desc)
+		}
+	}
+}
+
+// parseArgs parses args (excluding the program name) and returns a runner for the
+// requested command. args may either name a subcommand (see parseCommandArgs) or
+// use the original flag-based interface (see parseLegacyArgs). If the program
+// should exit immediately (e.g. after printing help or due to a usage error),
+// code is the non-negative exit code; otherwise it is -1.
+func parseArgs(args []string) (r *runner, code int) {
+	if len(args) > 0 && args[0] == "help" {
+		printCommands(os.Stdout)
+		return nil, 0
+	}
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		for i := range commandGroups {
+			if commandGroups[i].name == args[0] {
+				return parseCommandArgs(&commandGroups[i], args[1:])
+			}
+		}
+	}
+	return parseLegacyArgs(args)
+}
+
+// parseCommandArgs parses args (following the group name) for a subcommand in group.
+// Only the flags listed in the command's opts (plus commonOpts) are accepted.
+func parseCommandArgs(group *commandGroup, args []string) (*runner, int) {
+	var cmd *command
+	cmdName := group.name
+	if len(group.cmds) == 1 && group.cmds[0].name == "" {
+		cmd = &group.cmds[0]
+	} else {
+		if len(args) > 0 {
+			for i := range group.cmds {
+				if group.cmds[i].name == args[0] {
+					cmd = &group.cmds[i]
+				}
+			}
+		}
+		if cmd == nil {
+			printCommands(os.Stderr)
+			return nil, 2
+		}
+		cmdName += " " + cmd.name
+		args = args[1:]
+	}
+
+	// Register all options in a scratch set and copy the command's flags from it.
+	var opts options
+	all := flag.NewFlagSet("", flag.ContinueOnError)
+	opts.register(all)
+	fs := flag.NewFlagSet("mbstats "+cmdName, flag.ContinueOnError)
+	for _, name := range concatOpts(cmd.opts, commonOpts) {
+		f := all.Lookup(name)
+		fs.Var(f.Value, f.Name, f.Usage)
+	}
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintln(out, strings.TrimSpace("Usage: mbstats "+cmdName+" [flag]... "+cmd.argUsage()))
+		fmt.Fprintln(out, cmd.desc+".")
+		fmt.Fprintln(out)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err == flag.ErrHelp {
+		return nil, 0
+	} else if err != nil {
+		return nil, 2
+	}
+
+	r := &runner{cmd: cmd, opts: &opts, fs: fs, args: fs.Args()}
+	if cmd.arg != "" && len(r.args) > 0 {
+		r.arg, r.args = r.args[0], r.args[1:]
+	} else if cmd.arg != "" && !cmd.optionalArg() {
+		fs.Usage()
+		return nil, 2
+	}
+	return r, -1
+}
+
+// parseLegacyArgs parses args as flags, with the command selected by its action flag.
+// All options are accepted regardless of the command.
+func parseLegacyArgs(args []string) (*runner, int) {
+	var opts options
+	fs := flag.NewFlagSet("mbstats", flag.ContinueOnError)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintln(out, "Usage: mbstats [flag]... <INPUT_DIR>...")
+		fmt.Fprintln(out, "Generate MusicBrainz stats using JSON data written by read-mbdump.")
+		fmt.Fprintln(out, "Yearly actions accept multiple input dirs and print a column for each.")
+		fmt.Fprintln(out, "Edit type names are case-insensitive and may also be numeric IDs.")
+		fmt.Fprintln(out, "Edit types may also be categories like ARTIST_* or regular expressions like")
+		fmt.Fprintln(out, "'RELEASE_(CREATE|EDIT)' to aggregate all matching types. Comma-separated lists")
+		fmt.Fprintln(out, "of types are aggregated too unless -split-types is passed. ALL matches all types.")
+		fmt.Fprintln(out, `Actions are also available as subcommands; run "mbstats help" to list them.`)
+		fmt.Fprintln(out)
+		fs.PrintDefaults()
+	}
+	opts.register(fs)
+
+	// Commands with optional args are selected by bool flags, with the arg
+	// taken from the positional args.
+	actions := make(map[string]*command)
+	for i := range commandGroups {
+		for j := range commandGroups[i].cmds {
+			cmd := &commandGroups[i].cmds[j]
+			if cmd.arg == "" || cmd.optionalArg() {
+				fs.Bool(cmd.action, false, cmd.desc)
+			} else {
+				fs.String(cmd.action, "", cmd.desc)
+			}
+			actions[cmd.action] = cmd
+		}
+	}
+	if err := fs.Parse(args); err == flag.ErrHelp {
+		return nil, 0
+	} else if err != nil {
+		return nil, 2
+	}
+
+	r := &runner{opts: &opts, fs: fs, args: fs.Args()}
+	var names []string
+	fs.Visit(func(f *flag.Flag) {
+		if cmd := actions[f.Name]; cmd != nil {
+			if val := f.Value.String(); val != "" && val != "false" {
+				r.cmd = cmd
+				if cmd.arg != "" && !cmd.optionalArg() {
+					r.arg = val
+				}
+				names = append(names, "-"+f.Name)
+			}
+		}
+	})
+	switch {
+	case len(names) > 1:
+		fmt.Fprintln(os.Stderr, "Only one action may be specified (got "+strings.Join(names, ", ")+")")
+		return nil, 2
+	case r.cmd == nil && len(r.args) == 0:
+		fs.Usage()
+		return nil, 2
+	case r.cmd == nil:
+		fmt.Fprintln(os.Stderr, "No action specified (e.g. -editor-histogram ARTIST_CREATE)")
+		return nil, 2
+	}
+	if r.cmd.optionalArg() && len(r.args) > 0 {
+		r.arg, r.args = r.args[0], r.args[1:]
+	}
+	return r, -1
+}
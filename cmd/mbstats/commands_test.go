@@ -0,0 +1,127 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommandGroups(t *testing.T) {
+	var opts options
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	opts.register(fs)
+
+	actions := make(map[string]bool)
+	for _, g := range commandGroups {
+		names := make(map[string]bool)
+		for _, c := range g.cmds {
+			if names[c.name] {
+				t.Errorf("%q group has multiple %q commands", g.name, c.name)
+			}
+			names[c.name] = true
+			if c.name == "" && len(g.cmds) != 1 {
+				t.Errorf("%q group has unnamed command alongside others", g.name)
+			}
+			if actions[c.action] {
+				t.Errorf("Multiple commands use action %q", c.action)
+			}
+			actions[c.action] = true
+			if fs.Lookup(c.action) != nil {
+				t.Errorf("Action %q is also an option", c.action)
+			}
+			if c.run == nil || c.desc == "" {
+				t.Errorf("%q command is missing run func or description", c.action)
+			}
+			for _, name := range concatOpts(c.opts, commonOpts) {
+				if fs.Lookup(name) == nil {
+					t.Errorf("%q command accepts unregistered flag %q", c.action, name)
+				}
+			}
+		}
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args    string
+		action  string // expected action, or empty if parsing should fail
+		arg     string // expected r.arg
+		rest    string // expected r.args, space-separated
+		minYear int    // expected -min-year value
+	}{
+		{"-yearly-edits ALL -min-year 2010 a b", "yearly-edits", "ALL", "a b", 2010},
+		{"yearly edits -min-year 2010 ALL a b", "yearly-edits", "ALL", "a b", 2010},
+		{"-yearly-churn a", "yearly-churn", "", "a", 2000},
+		{"yearly churn a", "yearly-churn", "", "a", 2000},
+		{"-editor-id 123 a", "editor-id", "123", "a", 2000},
+		{"editor id 123 a", "editor-id", "123", "a", 2000},
+		{"-serve :8080 a", "serve", ":8080", "a", 2000},
+		{"serve :8080 a", "serve", ":8080", "a", 2000},
+		{"-list-types", "list-types", "", "", 2000},
+		{"-list-types ARTIST", "list-types", "ARTIST", "", 2000},
+		{"types", "list-types", "", "", 2000},
+		{"types ARTIST", "list-types", "ARTIST", "", 2000},
+		{"-selftest", "selftest", "", "", 2000},
+		{"selftest", "selftest", "", "", 2000},
+		{"check a b", "check", "", "a b", 2000},
+		{"-yearly-edits ALL -yearly-churn a", "", "", "", 0}, // multiple actions
+		{"-min-year 2010 a", "", "", "", 0},                  // no action
+		{"yearly bogus ALL a", "", "", "", 0},                // unknown command
+		{"yearly edits", "", "", "", 0},                      // missing arg
+		{"yearly edits -year 2010 ALL a", "", "", "", 0},     // unaccepted flag
+	} {
+		r, code := parseArgs(strings.Fields(tc.args))
+		if tc.action == "" {
+			if code != 2 {
+				t.Errorf("parseArgs(%q) returned code %d; want 2", tc.args, code)
+			}
+			continue
+		}
+		if code != -1 {
+			t.Errorf("parseArgs(%q) returned code %d", tc.args, code)
+			continue
+		}
+		if r.cmd.action != tc.action || r.arg != tc.arg || strings.Join(r.args, " ") != tc.rest ||
+			r.opts.minYear != tc.minYear {
+			t.Errorf("parseArgs(%q) = {%q %q %q min-year=%d}; want {%q %q %q min-year=%d}",
+				tc.args, r.cmd.action, r.arg, strings.Join(r.args, " "), r.opts.minYear,
+				tc.action, tc.arg, tc.rest, tc.minYear)
+		}
+	}
+}
+
+func TestParseArgs_Equivalent(t *testing.T) {
+	// Check that each subcommand selects the same command as its action flag.
+	for _, g := range commandGroups {
+		for i := range g.cmds {
+			c := &g.cmds[i]
+			var legacy, sub []string
+			if c.arg == "" || c.optionalArg() {
+				legacy = []string{"-" + c.action}
+			} else {
+				legacy = []string{"-" + c.action, "X"}
+			}
+			sub = strings.Fields(g.name + " " + c.name)
+			if c.arg != "" && !c.optionalArg() {
+				sub = append(sub, "X")
+			}
+			if c.dirs != noDirs {
+				legacy = append(legacy, "dir")
+				sub = append(sub, "dir")
+			}
+			lr, lcode := parseArgs(legacy)
+			sr, scode := parseArgs(sub)
+			if lcode != -1 || scode != -1 {
+				t.Errorf("parseArgs(%q) and parseArgs(%q) returned codes %d and %d", legacy, sub, lcode, scode)
+				continue
+			}
+			if lr.cmd != c || sr.cmd != c || lr.arg != sr.arg || !reflect.DeepEqual(lr.args, sr.args) {
+				t.Errorf("parseArgs(%q) and parseArgs(%q) returned different commands: {%q %q %q} vs. {%q %q %q}", legacy, sub, lr.cmd.action, lr.arg, lr.args, sr.cmd.action, sr.arg, sr.args)
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	gostats "github.com/montanaflynn/stats"
+)
+
+// corrMethod describes how correlation coefficients are computed.
+type corrMethod string
+
+const (
+	corrPearson  corrMethod = "pearson"  // Pearson product-moment correlation
+	corrSpearman corrMethod = "spearman" // Pearson correlation of ranks
+	corrKendall  corrMethod = "kendall"  // Kendall's tau-b
+)
+
+// parseCorrMethod parses a corrMethod from s.
+func parseCorrMethod(s string) (corrMethod, error) {
+	switch m := corrMethod(s); m {
+	case corrPearson, corrSpearman, corrKendall:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown method %q", s)
+	}
+}
+
+// correlator returns a function that computes the correlation coefficient
+// between cols[i] and cols[j] using method. All columns must have the same length.
+// The returned function is safe for concurrent use.
+func correlator(cols []gostats.Float64Data, method corrMethod) (func(i, j int) float64, error) {
+	switch method {
+	case corrPearson:
+		return pearsonCorrelator(cols), nil
+	case corrSpearman:
+		ranks := make([]gostats.Float64Data, len(cols))
+		for i, col := range cols {
+			ranks[i] = rankValues(col)
+		}
+		return pearsonCorrelator(ranks), nil
+	case corrKendall:
+		return kendallCorrelator(cols), nil
+	default:
+		return nil, fmt.Errorf("unknown method %q", method)
+	}
+}
+
+// pearsonCorrelator returns a function that computes Pearson correlation coefficients
+// between columns. Each column's mean and standard deviation are precomputed rather than
+// being recomputed for every pair as gostats.Pearson would. As with gostats.Pearson,
+// 0 is returned if either column is constant.
+func pearsonCorrelator(cols []gostats.Float64Data) func(i, j int) float64 {
+	means := make([]float64, len(cols))
+	sdevs := make([]float64, len(cols))
+	for i, col := range cols {
+		means[i], _ = gostats.Mean(col)
+		sdevs[i], _ = gostats.StandardDeviationPopulation(col)
+	}
+	return func(i, j int) float64 {
+		if sdevs[i] == 0 || sdevs[j] == 0 {
+			return 0
+		}
+		var s float64
+		for k, v := range cols[i] {
+			s += (v - means[i]) * (cols[j][k] - means[j])
+		}
+		return s / float64(len(cols[i])) / (sdevs[i] * sdevs[j])
+	}
+}
+
+// rankValues returns the 1-based ranks of vals in ascending order.
+// Tied values receive the average of their ranks.
+func rankValues(vals gostats.Float64Data) gostats.Float64Data {
+	idxs := make([]int, len(vals))
+	for i := range idxs {
+		idxs[i] = i
+	}
+	sort.Slice(idxs, func(i, j int) bool { return vals[idxs[i]] < vals[idxs[j]] })
+
+	ranks := make(gostats.Float64Data, len(vals))
+	for start := 0; start < len(idxs); {
+		end := start + 1
+		for end < len(idxs) && vals[idxs[end]] == vals[idxs[start]] {
+			end++
+		}
+		rank := float64(start+1+end) / 2 // average of start+1 through end
+		for _, idx := range idxs[start:end] {
+			ranks[idx] = rank
+		}
+		start = end
+	}
+	return ranks
+}
+
+// kendallCorrelator returns a function that computes Kendall's tau-b between
+// columns using Knight's O(n log n) algorithm. 0 is returned if either column is constant.
+//
+// The columns must not contain negative values. Since edit counts are sparse, only
+// rows that are non-zero in at least one of the two columns are sorted: a row that is
+// zero in both columns can't be discordant with any other row, so such rows only
+// contribute to the tie counts.
+func kendallCorrelator(cols []gostats.Float64Data) func(i, j int) float64 {
+	nonzero := make([][]int, len(cols)) // ascending indexes of non-zero values in each column
+	ties := make([]float64, len(cols))  // number of tied pairs within each column
+	for i, col := range cols {
+		for k, v := range col {
+			if v != 0 {
+				nonzero[i] = append(nonzero[i], k)
+			}
+		}
+		ties[i] = countTiedPairs(col)
+	}
+
+	return func(i, j int) float64 {
+		n := float64(len(cols[i]))
+		pairs := n * (n - 1) / 2
+		if ties[i] == pairs || ties[j] == pairs {
+			return 0
+		}
+
+		// Sort the non-zero rows by x and then y.
+		rows := mergeIndexes(nonzero[i], nonzero[j])
+		pts := make([][2]float64, len(rows))
+		for k, r := range rows {
+			pts[k] = [2]float64{cols[i][r], cols[j][r]}
+		}
+		sort.Slice(pts, func(a, b int) bool {
+			if pts[a][0] != pts[b][0] {
+				return pts[a][0] < pts[b][0]
+			}
+			return pts[a][1] < pts[b][1]
+		})
+
+		// Count pairs that are tied in both columns.
+		zeros := n - float64(len(pts))
+		joint := zeros * (zeros - 1) / 2
+		for start := 0; start < len(pts); {
+			end := start + 1
+			for end < len(pts) && pts[end] == pts[start] {
+				end++
+			}
+			t := float64(end - start)
+			joint += t * (t - 1) / 2
+			start = end
+		}
+
+		// Discordant pairs are those whose y values are out of order.
+		ys := make([]float64, len(pts))
+		for k, p := range pts {
+			ys[k] = p[1]
+		}
+		disc := sortCountingSwaps(ys, make([]float64, len(ys)))
+
+		return (pairs - ties[i] - ties[j] + joint - 2*disc) /
+			math.Sqrt((pairs-ties[i])*(pairs-ties[j]))
+	}
+}
+
+// countTiedPairs returns the number of pairs of equal values in vals.
+func countTiedPairs(vals gostats.Float64Data) float64 {
+	sorted := append(gostats.Float64Data(nil), vals...)
+	sort.Float64s(sorted)
+	var cnt float64
+	for start := 0; start < len(sorted); {
+		end := start + 1
+		for end < len(sorted) && sorted[end] == sorted[start] {
+			end++
+		}
+		t := float64(end - start)
+		cnt += t * (t - 1) / 2
+		start = end
+	}
+	return cnt
+}
+
+// mergeIndexes returns the union of a and b, which must be sorted in ascending order.
+func mergeIndexes(a, b []int) []int {
+	merged := make([]int, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			merged, a = append(merged, a[0]), a[1:]
+		case a[0] > b[0]:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, a[0]), a[1:], b[1:]
+		}
+	}
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
+
+// sortCountingSwaps sorts vals in ascending order using a stable merge sort and
+// returns the number of swaps that an insertion sort would need, i.e. the number
+// of pairs that were out of order. tmp must be at least as long as vals.
+func sortCountingSwaps(vals, tmp []float64) float64 {
+	if len(vals) < 2 {
+		return 0
+	}
+	mid := len(vals) / 2
+	swaps := sortCountingSwaps(vals[:mid], tmp[:mid]) + sortCountingSwaps(vals[mid:], tmp[mid:])
+	i, j, k := 0, mid, 0
+	for i < mid && j < len(vals) {
+		if vals[i] <= vals[j] {
+			tmp[k], i = vals[i], i+1
+		} else {
+			tmp[k], j = vals[j], j+1
+			swaps += float64(mid - i)
+		}
+		k++
+	}
+	k += copy(tmp[k:], vals[i:mid])
+	copy(tmp[k:], vals[j:])
+	copy(vals, tmp[:len(vals)])
+	return swaps
+}
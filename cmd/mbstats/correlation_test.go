@@ -0,0 +1,117 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	gostats "github.com/montanaflynn/stats"
+)
+
+func TestRankValues(t *testing.T) {
+	for _, tc := range []struct {
+		vals, want gostats.Float64Data
+	}{
+		{gostats.Float64Data{}, gostats.Float64Data{}},
+		{gostats.Float64Data{7}, gostats.Float64Data{1}},
+		{gostats.Float64Data{3, 1, 2}, gostats.Float64Data{3, 1, 2}},
+		{gostats.Float64Data{1, 2, 2, 3}, gostats.Float64Data{1, 2.5, 2.5, 4}},
+		{gostats.Float64Data{5, 5, 5}, gostats.Float64Data{2, 2, 2}},
+		{gostats.Float64Data{0, 4, 0, 4, 0, 1}, gostats.Float64Data{2, 5.5, 2, 5.5, 2, 4}},
+	} {
+		if got := rankValues(tc.vals); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("rankValues(%v) = %v; want %v", tc.vals, got, tc.want)
+		}
+	}
+}
+
+// bruteTauB computes Kendall's tau-b between x and y by comparing all pairs.
+func bruteTauB(x, y []float64) float64 {
+	var conc, disc, tx, ty float64
+	for i := range x {
+		for j := i + 1; j < len(x); j++ {
+			dx, dy := x[i]-x[j], y[i]-y[j]
+			if dx == 0 {
+				tx++
+			}
+			if dy == 0 {
+				ty++
+			}
+			if p := dx * dy; p > 0 {
+				conc++
+			} else if p < 0 {
+				disc++
+			}
+		}
+	}
+	n := float64(len(x))
+	pairs := n * (n - 1) / 2
+	if tx == pairs || ty == pairs {
+		return 0
+	}
+	return (conc - disc) / math.Sqrt((pairs-tx)*(pairs-ty))
+}
+
+func TestKendallCorrelator_BruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		// Use small, mostly-zero counts so there are many ties, like real edit counts.
+		n := 2 + r.Intn(60)
+		x := make(gostats.Float64Data, n)
+		y := make(gostats.Float64Data, n)
+		for k := 0; k < n; k++ {
+			if r.Intn(3) > 0 {
+				x[k] = float64(r.Intn(5))
+			}
+			if r.Intn(3) > 0 {
+				y[k] = float64(r.Intn(5))
+			}
+		}
+		got := kendallCorrelator([]gostats.Float64Data{x, y})(0, 1)
+		if want := bruteTauB(x, y); math.Abs(got-want) > 1e-9 {
+			t.Errorf("Trial %d: tau-b of %v and %v is %v; want %v", trial, x, y, got, want)
+		}
+	}
+}
+
+func TestCorrelator(t *testing.T) {
+	const eps = 1e-12
+	for _, tc := range []struct {
+		x, y   gostats.Float64Data
+		method corrMethod
+		want   float64
+	}{
+		// Expected values for these inputs are given in SciPy's documentation
+		// for spearmanr and kendalltau.
+		{gostats.Float64Data{1, 2, 3, 4, 5}, gostats.Float64Data{5, 6, 7, 8, 7}, corrSpearman, 0.8207826816681233},
+		{gostats.Float64Data{12, 2, 1, 12, 2}, gostats.Float64Data{1, 4, 7, 1, 0}, corrKendall, -0.4714045207910317},
+		// These were computed independently from the definitions.
+		{gostats.Float64Data{1, 2, 3, 4, 5}, gostats.Float64Data{5, 6, 7, 8, 7}, corrKendall, 0.7378647873726218},
+		{gostats.Float64Data{12, 2, 1, 12, 2}, gostats.Float64Data{1, 4, 7, 1, 0}, corrSpearman, -0.5407380704358752},
+		{gostats.Float64Data{0, 0, 1, 2, 5, 0, 3}, gostats.Float64Data{1, 0, 0, 3, 4, 0, 3}, corrSpearman, 0.8349908101841638},
+		{gostats.Float64Data{0, 0, 1, 2, 5, 0, 3}, gostats.Float64Data{1, 0, 0, 3, 4, 0, 3}, corrKendall, 0.7431605356175384},
+		// Perfect correlations.
+		{gostats.Float64Data{1, 2, 3, 4}, gostats.Float64Data{2, 4, 6, 8}, corrPearson, 1},
+		{gostats.Float64Data{1, 2, 3, 4}, gostats.Float64Data{1, 4, 9, 16}, corrSpearman, 1},
+		{gostats.Float64Data{1, 2, 3, 4}, gostats.Float64Data{4, 3, 2, 1}, corrKendall, -1},
+		// Constant columns produce 0.
+		{gostats.Float64Data{3, 3, 3}, gostats.Float64Data{1, 2, 3}, corrPearson, 0},
+		{gostats.Float64Data{3, 3, 3}, gostats.Float64Data{1, 2, 3}, corrSpearman, 0},
+		{gostats.Float64Data{0, 0, 0}, gostats.Float64Data{1, 2, 3}, corrKendall, 0},
+	} {
+		corr, err := correlator([]gostats.Float64Data{tc.x, tc.y}, tc.method)
+		if err != nil {
+			t.Fatalf("correlator(%v) failed: %v", tc.method, err)
+		}
+		if got := corr(0, 1); math.Abs(got-tc.want) > eps {
+			t.Errorf("%v correlation of %v and %v is %v; want %v", tc.method, tc.x, tc.y, got, tc.want)
+		}
+		if got := corr(1, 0); math.Abs(got-tc.want) > eps {
+			t.Errorf("%v correlation of %v and %v is %v; want %v", tc.method, tc.y, tc.x, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/derat/mbstats"
+	gostats "github.com/montanaflynn/stats"
+)
+
+// eventTable returns a comparison of the values computed by fn before and after
+// each of events. The first value returned by fn is compared between the window
+// years preceding each event's year and the window years following it, and the
+// p-value from Welch's t-test is included. dirs and dirStats are as described
+// for yearlyTable.
+func eventTable(dirs []string, dirStats []*mbstats.Series[yearEditorStats],
+	events []event, window int, fn yearlyFunc) *table {
+	cols := []column{
+		{name: "event", left: true},
+		{name: "year", format: "%7s"},
+		{name: "before", format: "%10.1f"},
+		{name: "after", format: "%10.1f"},
+		{name: "change", format: "%+7.1f%%"},
+		{name: "p", format: "%6.3f"},
+	}
+	if len(dirs) > 1 {
+		cols = append(cols, column{name: "dir", left: true})
+	}
+	t := newTable(cols...)
+	t.header = true
+
+	for _, ev := range events {
+		for i, s := range dirStats {
+			all := s.Values()
+			if len(all) == 0 {
+				continue
+			}
+			// All years in a directory start in the same month.
+			evYear := ev.time.Year()
+			if ev.time.Month() < all[0].start.Month() {
+				evYear--
+			}
+			yearLabel := strconv.Itoa(evYear)
+
+			var before, after gostats.Float64Data
+			for j := range all {
+				ys := &all[j]
+				switch {
+				case ys.period.Year == evYear:
+					yearLabel = ys.label
+				case ys.period.Year >= evYear-window && ys.period.Year < evYear:
+					before = append(before, fn(ys)[0])
+				case ys.period.Year > evYear && ys.period.Year <= evYear+window:
+					after = append(after, fn(ys)[0])
+				}
+			}
+
+			mb, _ := gostats.Mean(before)
+			ma, _ := gostats.Mean(after)
+			change := math.NaN()
+			if len(before) > 0 && len(after) > 0 && mb != 0 {
+				change = (ma - mb) / mb * 100
+			}
+			row := []interface{}{ev.label, yearLabel, mb, ma, change, welchTTest(before, after)}
+			if len(dirs) > 1 {
+				row = append(row, dirs[i])
+			}
+			t.add(row...)
+		}
+	}
+	return t
+}
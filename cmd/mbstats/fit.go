@@ -0,0 +1,149 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"math"
+	"sort"
+
+	gostats "github.com/montanaflynn/stats"
+)
+
+// distFit describes a distribution fitted to per-editor edit counts.
+type distFit struct {
+	params []float64 // distribution-specific parameters
+	ks     float64   // Kolmogorov-Smirnov statistic (max CDF difference)
+}
+
+// fitPowerLaw fits a discrete power law to the values in counts that are at least
+// xmin using the continuous approximation to the maximum-likelihood estimator from
+// Clauset, Shalizi, and Newman (2009). params contains the exponent alpha.
+func fitPowerLaw(counts gostats.Float64Data, xmin float64) distFit {
+	vals := tail(counts, xmin)
+	if len(vals) == 0 {
+		return distFit{[]float64{math.NaN()}, math.NaN()}
+	}
+	var sum float64
+	for _, v := range vals {
+		sum += math.Log(v / (xmin - 0.5))
+	}
+	alpha := 1 + float64(len(vals))/sum
+	cdf := func(x float64) float64 { return 1 - math.Pow((x+0.5)/(xmin-0.5), 1-alpha) }
+	return distFit{[]float64{alpha}, ksStat(vals, cdf)}
+}
+
+// fitLogNormal fits a log-normal distribution truncated at xmin to the values in counts
+// that are at least xmin. As in fitPowerLaw, the discrete counts are approximated by a
+// continuous distribution, truncated at xmin-0.5. params contains the mean and standard
+// deviation of the logarithms of the untruncated distribution. They are NaN if there
+// are fewer than two distinct values or if the maximum-likelihood estimate doesn't exist.
+func fitLogNormal(counts gostats.Float64Data, xmin float64) distFit {
+	nan := distFit{[]float64{math.NaN(), math.NaN()}, math.NaN()}
+	vals := tail(counts, xmin)
+	logs := make([]float64, len(vals))
+	for i, v := range vals {
+		logs[i] = math.Log(v)
+	}
+	lower := xmin - 0.5
+	mu, sigma := fitTruncNormal(logs, math.Log(lower))
+	if math.IsNaN(mu) || math.IsNaN(sigma) {
+		return nan
+	}
+	// The CDF is normalized by the probability mass above the truncation point.
+	norm := func(x float64) float64 { return 0.5 * math.Erfc(-(math.Log(x)-mu)/(sigma*math.Sqrt2)) }
+	f0 := norm(lower)
+	cdf := func(x float64) float64 { return (norm(x+0.5) - f0) / (1 - f0) }
+	return distFit{[]float64{mu, sigma}, ksStat(vals, cdf)}
+}
+
+// fitTruncNormal returns maximum-likelihood estimates of the mean and standard deviation
+// of a normal distribution left-truncated at a, given samples ys (all greater than a).
+// NaNs are returned if ys has fewer than two distinct values or if no estimate exists
+// (i.e. the samples decay at least as quickly as an exponential distribution would).
+//
+// The truncated normal distribution is an exponential family, so the estimates
+// match its mean and variance to the samples'. With alpha = (a-mu)/sigma and
+// lambda = pdf(alpha)/(1-cdf(alpha)), these are mu + sigma*lambda and
+// sigma^2 * (1 + alpha*lambda - lambda^2). The ratio (mean-a)/stddev depends only on
+// alpha and decreases monotonically, so alpha is found by bisection.
+func fitTruncNormal(ys []float64, a float64) (mu, sigma float64) {
+	if len(ys) < 2 {
+		return math.NaN(), math.NaN()
+	}
+	mean, _ := gostats.Mean(ys)
+	sd, _ := gostats.StandardDeviationPopulation(ys)
+	if sd == 0 {
+		return math.NaN(), math.NaN()
+	}
+
+	lambda := func(alpha float64) float64 {
+		return math.Exp(-alpha*alpha/2) / math.Sqrt(2*math.Pi) / (0.5 * math.Erfc(alpha/math.Sqrt2))
+	}
+	ratio := func(alpha float64) float64 {
+		l := lambda(alpha)
+		return (l - alpha) / math.Sqrt(1+alpha*l-l*l)
+	}
+
+	const maxAlpha = 30 // lambda can't be computed accurately beyond this
+	want := (mean - a) / sd
+	if want >= ratio(-maxAlpha) {
+		return mean, sd // truncation is negligible
+	} else if want <= ratio(maxAlpha) {
+		return math.NaN(), math.NaN()
+	}
+	lo, hi := float64(-maxAlpha), float64(maxAlpha)
+	for i := 0; i < 200 && hi-lo > 1e-12; i++ {
+		if mid := (lo + hi) / 2; ratio(mid) > want {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	alpha := (lo + hi) / 2
+	sigma = (mean - a) / (lambda(alpha) - alpha)
+	return a - sigma*alpha, sigma
+}
+
+// tail returns the values in counts that are at least xmin in ascending order.
+func tail(counts gostats.Float64Data, xmin float64) []float64 {
+	var vals []float64
+	for _, v := range counts {
+		if v >= xmin {
+			vals = append(vals, v)
+		}
+	}
+	sort.Float64s(vals)
+	return vals
+}
+
+// ksStat returns the maximum difference between the empirical CDF of vals
+// (which must be sorted in ascending order) and cdf at each distinct value.
+func ksStat(vals []float64, cdf func(x float64) float64) float64 {
+	var d float64
+	for i := 0; i < len(vals); i++ {
+		// Advance to the last occurrence of the value.
+		for i+1 < len(vals) && vals[i+1] == vals[i] {
+			i++
+		}
+		emp := float64(i+1) / float64(len(vals))
+		d = math.Max(d, math.Abs(emp-cdf(vals[i])))
+	}
+	return d
+}
+
+// distFitsTable fits power-law and log-normal distributions to the values in
+// counts that are at least xmin and returns a table containing their parameters
+// and KS statistics. Smaller KS statistics indicate better fits.
+func distFitsTable(counts gostats.Float64Data, xmin float64) *table {
+	pl := fitPowerLaw(counts, xmin)
+	ln := fitLogNormal(counts, xmin)
+	n := len(tail(counts, xmin))
+	t := newTable(column{name: "model", left: true}, column{name: "editors"},
+		column{name: "alpha", format: "%.3f"}, column{name: "mu", format: "%.3f"},
+		column{name: "sigma", format: "%.3f"}, column{name: "ks", format: "%.4f"})
+	t.header = true
+	t.add("power law", n, pl.params[0], nil, nil, pl.ks)
+	t.add("log-normal", n, nil, ln.params[0], ln.params[1], ln.ks)
+	return t
+}
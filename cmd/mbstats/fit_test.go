@@ -0,0 +1,97 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	gostats "github.com/montanaflynn/stats"
+)
+
+// truncNormalQuantiles returns n evenly-spaced quantiles of a normal distribution
+// with the supplied mean and standard deviation that's left-truncated at a.
+func truncNormalQuantiles(mu, sigma, a float64, n int) []float64 {
+	cdf := func(x float64) float64 { return 0.5 * math.Erfc(-(x-mu)/(sigma*math.Sqrt2)) }
+	f0 := cdf(a)
+	ys := make([]float64, n)
+	for i := range ys {
+		p := f0 + (float64(i)+0.5)/float64(n)*(1-f0)
+		ys[i] = mu + sigma*math.Sqrt2*math.Erfinv(2*p-1)
+	}
+	return ys
+}
+
+func TestFitTruncNormal(t *testing.T) {
+	const (
+		n   = 100000
+		eps = 0.001
+	)
+	for _, tc := range []struct{ mu, sigma, a float64 }{
+		{1, 0.8, 0.5},   // moderate truncation
+		{-1, 1.5, 0.5},  // most of the distribution is truncated
+		{2, 1, -3},      // negligible truncation
+		{0.5, 2, -0.69}, // log(0.5), as used by fitLogNormal with xmin 1
+	} {
+		ys := truncNormalQuantiles(tc.mu, tc.sigma, tc.a, n)
+		mu, sigma := fitTruncNormal(ys, tc.a)
+		if math.Abs(mu-tc.mu) > eps || math.Abs(sigma-tc.sigma) > eps {
+			t.Errorf("fitTruncNormal(%v, %v, %v samples) = (%0.4f, %0.4f); want (%v, %v)",
+				tc.mu, tc.sigma, tc.a, mu, sigma, tc.mu, tc.sigma)
+		}
+	}
+
+	// Without truncation, the estimates are just the samples' mean and standard deviation.
+	ys := []float64{4, 5, 6, 7, 8}
+	if mu, sigma := fitTruncNormal(ys, -100); mu != 6 || math.Abs(sigma-math.Sqrt2) > 1e-12 {
+		t.Errorf("fitTruncNormal(%v, -100) = (%v, %v); want (6, %v)", ys, mu, sigma, math.Sqrt2)
+	}
+
+	for _, tc := range []struct {
+		ys []float64
+		a  float64
+	}{
+		{nil, 0},
+		{[]float64{3}, 0},
+		{[]float64{3, 3, 3}, 0},
+		// Samples that are spread out relative to their distance from the truncation
+		// point (like an exponential distribution's) have no estimate.
+		{[]float64{0.01, 0.02, 0.05, 1, 5, 20}, 0},
+	} {
+		if mu, sigma := fitTruncNormal(tc.ys, tc.a); !math.IsNaN(mu) || !math.IsNaN(sigma) {
+			t.Errorf("fitTruncNormal(%v, %v) = (%v, %v); want (NaN, NaN)", tc.ys, tc.a, mu, sigma)
+		}
+	}
+}
+
+func TestFitLogNormal(t *testing.T) {
+	// Generate counts by rounding samples from a log-normal distribution that's
+	// truncated at xmin-0.5, matching the continuity correction used by fitLogNormal.
+	const (
+		mu, sigma = 1.5, 1.2
+		xmin      = 3
+	)
+	ys := truncNormalQuantiles(mu, sigma, math.Log(xmin-0.5), 100000)
+	counts := make(gostats.Float64Data, len(ys))
+	for i, y := range ys {
+		counts[i] = math.Floor(math.Exp(y) + 0.5)
+	}
+	// Values below xmin should be ignored.
+	counts = append(counts, 1, 1, 2, 2, 2)
+
+	fit := fitLogNormal(counts, xmin)
+	if math.Abs(fit.params[0]-mu) > 0.05 || math.Abs(fit.params[1]-sigma) > 0.05 {
+		t.Errorf("fitLogNormal(..., %v) params = %0.4f; want [%v %v]", xmin, fit.params, mu, sigma)
+	}
+	if fit.ks > 0.01 {
+		t.Errorf("fitLogNormal(..., %v) KS statistic = %0.4f; want <= 0.01", xmin, fit.ks)
+	}
+
+	for _, counts := range []gostats.Float64Data{nil, {1, 2}, {5, 5, 5}} {
+		fit := fitLogNormal(counts, xmin)
+		if !math.IsNaN(fit.params[0]) || !math.IsNaN(fit.params[1]) || !math.IsNaN(fit.ks) {
+			t.Errorf("fitLogNormal(%v, %v) = %v; want NaNs", counts, xmin, fit)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/derat/mbstats/internal/fileutil"
+)
+
+// writeGnuplotFile writes a gnuplot script charting t to a file at path p.
+// See writeGnuplot.
+func writeGnuplotFile(p string, t *table, ct chartType) error {
+	of, err := fileutil.CreateOutputFile(p)
+	if err != nil {
+		return err
+	}
+	if err := writeGnuplot(of, t, ct); err != nil {
+		of.Abort()
+		return err
+	}
+	return of.Commit()
+}
+
+// writeGnuplot writes a gnuplot script to w that charts t with type ct.
+// t is interpreted as described by writeSVGChart. The data is included in the
+// script as a datablock, so the script can be run without any other files.
+func writeGnuplot(w io.Writer, t *table, ct chartType) error {
+	if len(t.cols) < 2 || len(t.rows) == 0 {
+		return fmt.Errorf("no data to chart")
+	}
+
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated by mbstats. Run with \"gnuplot -p <file>\" or add\n")
+	b.WriteString("# \"set terminal\" and \"set output\" commands to write an image.\n")
+	b.WriteString("$data << EOD\n")
+	for i, col := range t.cols {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(quote(col.name))
+	}
+	b.WriteString("\n")
+	for _, row := range t.rows {
+		b.WriteString(quote(fmt.Sprint(row[0])))
+		for _, v := range row[1:] {
+			if f, ok := chartValue(v); ok {
+				b.WriteString(" " + formatValue(f))
+			} else {
+				b.WriteString(" ?")
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("EOD\n\n")
+
+	b.WriteString("set datafile missing \"?\"\n")
+	b.WriteString("set key autotitle columnheader\n")
+	b.WriteString("set grid ytics\n")
+	fmt.Fprintf(&b, "set xlabel %s\n", quote(t.cols[0].name))
+	if len(t.rows) > chartMaxLabels {
+		b.WriteString("set xtics rotate by -45\n")
+	}
+	if len(t.cols) == 2 {
+		b.WriteString("unset key\n")
+	}
+	switch ct {
+	case barChart:
+		b.WriteString("set style data histograms\n")
+		b.WriteString("set style fill solid border -1\n")
+		fmt.Fprintf(&b, "plot for [i=2:%d] $data using i:xtic(1)\n", len(t.cols))
+	default:
+		fmt.Fprintf(&b, "plot for [i=2:%d] $data using 0:i:xtic(1) with linespoints\n", len(t.cols))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,135 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/derat/mbstats/histogram"
+)
+
+// heatmap implements a two-dimensional histogram.
+type heatmap struct {
+	x, y *histogram.Histogram // buckets along each axis, also counting each axis's values
+	// counts is indexed by y and then x bucket, with underflow
+	// in the first row or column and overflow in the last.
+	counts [][]int
+}
+
+// newHeatmap returns a heatmap of the points (xs[i], ys[i]). hs is used
+// independently for each axis to determine its buckets. Points are unweighted.
+func newHeatmap(xs, ys []int64, hs *histSpec) *heatmap {
+	axis := *hs
+	axis.weighted = false
+	hm := &heatmap{x: axis.histogram(xs, nil), y: axis.histogram(ys, nil)}
+	hm.counts = make([][]int, len(hm.y.Buckets)+2)
+	for i := range hm.counts {
+		hm.counts[i] = make([]int, len(hm.x.Buckets)+2)
+	}
+	for i := range xs {
+		hm.counts[hm.y.Index(ys[i])+1][hm.x.Index(xs[i])+1]++
+	}
+	return hm
+}
+
+// shown returns the indexes into a row or column of heatmap.counts for h's axis
+// that should be shown. Underflow and overflow are omitted if they're empty.
+func shown(h *histogram.Histogram) []int {
+	var idxs []int
+	if h.Underflow > 0 {
+		idxs = append(idxs, 0)
+	}
+	for i := range h.Buckets {
+		idxs = append(idxs, i+1)
+	}
+	if h.Overflow > 0 {
+		idxs = append(idxs, len(h.Buckets)+1)
+	}
+	return idxs
+}
+
+// table returns a table with a row for each y bucket and a column for each
+// x bucket. xName and yName describe the axes. When written as text, the table
+// is formatted as by write.
+func (hm *heatmap) table(xName, yName string) *table {
+	xLabels, yLabels := hm.x.Labels(), hm.y.Labels()
+	xIdxs, yIdxs := shown(hm.x), shown(hm.y)
+	cols := []column{{name: yName + ` \ ` + xName, left: true}}
+	for _, i := range xIdxs {
+		cols = append(cols, column{name: xLabels[i]})
+	}
+	t := newTable(cols...)
+	for _, i := range yIdxs {
+		row := []interface{}{yLabels[i]}
+		for _, j := range xIdxs {
+			row = append(row, hm.counts[i][j])
+		}
+		t.add(row...)
+	}
+	t.text = func(w io.Writer) error { return hm.write(w, xName, yName, &textHistStyle) }
+	return t
+}
+
+// Characters used to shade cells with increasing counts.
+var (
+	asciiShades   = []string{" ", ".", ":", "*", "#"}
+	unicodeShades = []string{" ", "░", "▒", "▓", "█"}
+)
+
+// write writes a text representation of hm to w, with x buckets in columns and
+// y buckets in rows. Cells are shaded based on the logarithm of their counts.
+func (hm *heatmap) write(w io.Writer, xName, yName string, style *histogram.Style) error {
+	xLabels, yLabels := hm.x.Labels(), hm.y.Labels()
+	xIdxs, yIdxs := shown(hm.x), shown(hm.y)
+
+	var labelWidth, cellWidth, maxCount int
+	for _, i := range yIdxs {
+		if n := len(yLabels[i]); n > labelWidth {
+			labelWidth = n
+		}
+		for _, j := range xIdxs {
+			if c := hm.counts[i][j]; c > maxCount {
+				maxCount = c
+			}
+		}
+	}
+	for _, i := range xIdxs {
+		if n := len(xLabels[i]); n > cellWidth {
+			cellWidth = n
+		}
+	}
+	shades := asciiShades
+	if style.Unicode {
+		shades = unicodeShades
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v (rows) vs. %v (columns)\n", yName, xName)
+	fmt.Fprintf(&b, "%*s |", labelWidth, "")
+	for _, i := range xIdxs {
+		fmt.Fprintf(&b, " %*s", cellWidth, xLabels[i])
+	}
+	b.WriteString("\n")
+	for _, i := range yIdxs {
+		fmt.Fprintf(&b, "%*s |", labelWidth, yLabels[i])
+		for _, j := range xIdxs {
+			level := 0
+			if c := hm.counts[i][j]; c > 0 {
+				level = len(shades) - 1
+				if maxCount > 1 {
+					frac := math.Log(float64(c)) / math.Log(float64(maxCount))
+					level = 1 + int(math.Round(frac*float64(len(shades)-2)))
+				}
+			}
+			b.WriteString(" " + strings.Repeat(shades[level], cellWidth))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "(%q = 1, %q = %d, logarithmic)\n", shades[1], shades[len(shades)-1], maxCount)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
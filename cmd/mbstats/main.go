@@ -5,156 +5,224 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/derat/mbstats"
+	"github.com/derat/mbstats/internal/logutil"
 )
 
 func main() {
-	flag.Usage = func() {
-		fmt.Fprintln(flag.CommandLine.Output(), "Usage: mbstats [flag]... <INPUT_DIR>")
-		fmt.Fprintln(flag.CommandLine.Output(), "Generate MusicBrainz stats using JSON data written by read-mbdump.")
-		fmt.Fprintln(flag.CommandLine.Output())
-		flag.PrintDefaults()
+	r, code := parseArgs(os.Args[1:])
+	if code >= 0 {
+		os.Exit(code)
 	}
-	year := flag.Int("year", time.Now().Year()-1, "Year to display stats from (for applicable actions)")
-	minYear := flag.Int("min-year", 2000, "Minimum year to display stats from (for applicable actions)")
-	maxYear := flag.Int("max-year", time.Now().Year()-1, "Maximum year to display stats from (for applicable actions)")
-	editor := flag.String("editor", "", "Print edit type counts for the named editor")
-	editorHist := flag.String("editor-histogram", "", "Print editor edit-count histogram for specified edit type")
-	editorList := flag.String("editor-list", "", "Print editor names and edits for specified edit type")
-	histMin := flag.Int("histogram-min", 1, "Minimum value for histograms")
-	histMax := flag.Int("histogram-max", 100, "Maximum value for histograms")
-	histBuckets := flag.Int("histogram-buckets", 10, "Buckets to use for histograms")
-	yearlyAge := flag.String("yearly-age", "", "Print yearly median and mean account age in years of editors with specified edit type")
-	yearlyEditors := flag.String("yearly-editors", "", "Print yearly editors for specified edit type")
-	yearlyEdits := flag.String("yearly-edits", "", "Print yearly edits of specified type")
-	flag.Parse()
 
-	os.Exit(func() int {
-		if flag.NArg() != 1 {
-			flag.Usage()
-			return 2
-		}
-		jsonDir := flag.Arg(0)
-
-		switch {
-		case *editor != "":
-			stats, _, ret := doSingleYearEditsCmd(jsonDir, *year, "")
-			if ret != 0 {
-				return ret
-			}
-			for _, es := range stats {
-				if es.Name == *editor {
-					for et, cnt := range es.Edits {
-						fmt.Printf("%-37s  %5d\n", mbstats.EditTypeName(et), cnt)
-					}
-					break
-				}
-			}
-			return 0
-
-		case *editorHist != "":
-			stats, et, ret := doSingleYearEditsCmd(jsonDir, *year, *editorHist)
-			if ret != 0 {
-				return ret
-			}
-			printEditorHistogram(os.Stdout, stats, et, *histMin, *histMax, *histBuckets)
-			return 0
-
-		case *editorList != "":
-			stats, et, ret := doSingleYearEditsCmd(jsonDir, *year, *editorList)
-			if ret != 0 {
-				return ret
-			}
-			for _, es := range stats {
-				if cnt := es.Edits[et]; cnt > 0 {
-					fmt.Printf("%5d  %v\n", cnt, es.Name)
-				}
-			}
-			return 0
-
-		case *yearlyAge != "":
-			yearStats, et, ret := doYearlyEditsCmd(jsonDir, *minYear, *maxYear, *yearlyAge)
-			if ret != 0 {
-				return ret
-			}
-			for _, ys := range yearStats {
-				end := time.Date(ys.year+1, 1, 1, 0, 0, 0, 0, time.UTC)
-				median, mean := getEditorAgeStats(ys.stats, et, end)
-				fmt.Printf("%4d  %1.1f  %1.1f\n", ys.year, median, mean)
-			}
-			return 0
-
-		case *yearlyEditors != "":
-			yearStats, et, ret := doYearlyEditsCmd(jsonDir, *minYear, *maxYear, *yearlyEditors)
-			if ret != 0 {
-				return ret
-			}
-			for _, ys := range yearStats {
-				fmt.Printf("%4d  %5d\n", ys.year, countEditors(ys.stats, et))
-			}
-			return 0
+	// Cancel long-running reads on the first interrupt so that partially-written
+	// output files can be cleaned up. Subsequent interrupts kill the process as usual.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	r.ctx = ctx
+	os.Exit(r.run())
+}
 
-		case *yearlyEdits != "":
-			yearStats, et, ret := doYearlyEditsCmd(jsonDir, *minYear, *maxYear, *yearlyEdits)
-			if ret != 0 {
-				return ret
-			}
-			for _, ys := range yearStats {
-				fmt.Printf("%4d  %6d\n", ys.year, countEditTypes(ys.stats)[et])
-			}
-			return 0
+// options contains the values of flags that modify the behavior of commands.
+// Commands' action flags are registered separately by parseLegacyArgs.
+type options struct {
+	year               int
+	log                *logutil.Flags
+	outPath            string
+	minYear            int
+	maxYear            int
+	minMonth           string
+	maxMonth           string
+	compareRange       bool
+	corrThreshold      float64
+	corrFormat         string
+	corrMethod         string
+	cache              bool
+	chartFile          string
+	color              string
+	chartOut           string
+	chartType          string
+	gnuplotFile        string
+	format             string
+	serveCacheYears    int
+	dups               string
+	minEditors         int
+	editorHistoryTypes string
+	histMin            int
+	histMax            int
+	eventsFile         string
+	eventWindow        int
+	histBuckets        int
+	histPercent        bool
+	histUnicode        bool
+	histWeighted       bool
+	histQuantiles      bool
+	limit              int
+	lorenzPoints       int
+	overlapType        string
+	powerLawMin        int
+	reverse            bool
+	sort               string
+	splitTypes         bool
+	survivalYears      int
+	newEditorsBy       string
+	minCount           int
+	excludeBots        bool
+	comebackGap        int
+}
 
-		default:
-			fmt.Fprintln(os.Stderr, "No action specified (e.g. -editor-histogram ARTIST_CREATE)")
-			return 2
-		}
-	}())
+// register registers flags for o's fields in fs.
+func (o *options) register(fs *flag.FlagSet) {
+	fs.IntVar(&o.year, "year", time.Now().Year()-1, "Year to display stats from (for applicable actions, using starting year for non-calendar years)")
+	o.log = logutil.AddFlags(fs)
+	fs.StringVar(&o.outPath, "o", "", "File to write results to instead of stdout (parent dirs are created)")
+	fs.IntVar(&o.minYear, "min-year", 2000, "Minimum year to display stats from (for applicable actions)")
+	fs.IntVar(&o.maxYear, "max-year", time.Now().Year()-1, "Maximum year to display stats from (for applicable actions)")
+	fs.StringVar(&o.minMonth, "min-month", "", "Minimum month (YYYY-MM) to display stats from for monthly actions")
+	fs.StringVar(&o.maxMonth, "max-month", "", "Maximum month (YYYY-MM) to display stats from for monthly actions")
+	fs.BoolVar(&o.compareRange, "compare-range", false, "Sum -compare-editors counts across -min-year to -max-year instead of using -year")
+	fs.Float64Var(&o.corrThreshold, "correlation-threshold", 0.5, "Minimum absolute correlation coefficient to print in list format")
+	fs.StringVar(&o.corrFormat, "correlation-format", "list", `Correlation output format ("list" or "matrix")`)
+	fs.StringVar(&o.corrMethod, "correlation-method", "pearson", `Correlation coefficient ("pearson", "spearman", or "kendall")`)
+	fs.BoolVar(&o.cache, "cache", true, "Cache decoded editor stats under the user cache dir to speed up later runs")
+	fs.StringVar(&o.chartFile, "chart", "", "SVG file to write a chart of yearly, monthly, or histogram results to")
+	fs.StringVar(&o.color, "color", "auto", `Whether to color histogram bars ("auto", "always", or "never")`)
+	fs.StringVar(&o.chartOut, "chart-out", "", "PNG file to write a chart of yearly, monthly, or histogram results to")
+	fs.StringVar(&o.chartType, "chart-type", "", `Chart type for -chart, -chart-out, and -gnuplot ("line" or "bar"; default depends on action)`)
+	fs.StringVar(&o.gnuplotFile, "gnuplot", "", "File to write a gnuplot script charting yearly, monthly, or histogram results to")
+	fs.StringVar(&o.format, "format", "text", `Output format ("text", "csv", "tsv", "json", "markdown", or "vega" for charts)`)
+	fs.IntVar(&o.serveCacheYears, "serve-cache-years", 5, "Maximum number of years of editor stats kept in memory by -serve")
+	fs.StringVar(&o.dups, "duplicates", "error", `How to handle duplicate editor records ("error", "sum", "newer")`)
+	fs.IntVar(&o.minEditors, "min-editors", 1, "Minimum editors for edit types to be printed by -edit-type-counts")
+	fs.StringVar(&o.editorHistoryTypes, "editor-history-types", "", "Comma-separated edit types to print additional -editor-history columns for")
+	fs.IntVar(&o.histMin, "histogram-min", 0, "Minimum value for histograms (smallest value if unset)")
+	fs.IntVar(&o.histMax, "histogram-max", 0, "Maximum value for histograms (99th percentile if unset)")
+	fs.StringVar(&o.eventsFile, "events", "", "CSV file with date,label rows for before/after analysis in yearly actions")
+	fs.IntVar(&o.eventWindow, "event-window", 3, "Years before and after each event to compare")
+	fs.IntVar(&o.histBuckets, "histogram-buckets", 10, "Buckets to use for histograms")
+	fs.BoolVar(&o.histPercent, "histogram-percent", false, "Print each histogram bucket's percentage of the total count")
+	fs.BoolVar(&o.histUnicode, "histogram-unicode", false, "Draw histogram bars using Unicode block characters")
+	fs.BoolVar(&o.histWeighted, "histogram-weighted", false, "Count editors' edits (or notes for -note-histogram) in histogram buckets instead of editors")
+	fs.BoolVar(&o.histQuantiles, "histogram-quantiles", false, "Choose histogram bucket boundaries from quantiles of the data instead of -histogram-min and -histogram-max")
+	fs.IntVar(&o.limit, "limit", 0, "Maximum rows to print for list actions like -editor-list and -voter-list (0 for all)")
+	fs.IntVar(&o.lorenzPoints, "lorenz-points", 100, "Number of Lorenz curve segments to print")
+	fs.StringVar(&o.overlapType, "overlap-type", allTypes, "Edit type used to determine activity for -overlap")
+	fs.IntVar(&o.powerLawMin, "power-law-min", 1, "Minimum per-editor edits to include in -power-law fits")
+	fs.BoolVar(&o.reverse, "reverse", false, "Reverse the order of -editor-list")
+	fs.StringVar(&o.sort, "sort", "count", `Order of -editor-list ("count", "name", or "created")`)
+	fs.BoolVar(&o.splitTypes, "split-types", false, "Print separate results for each comma-separated edit type instead of aggregating them")
+	fs.IntVar(&o.survivalYears, "survival-years", 10, "Maximum years to print for -survival")
+	fs.StringVar(&o.newEditorsBy, "new-editors-by", "created", `Whether editors are new when their account was "created" or at their "first-edit"`)
+	fs.IntVar(&o.minCount, "min-count", 1, "Minimum edits of specified type for editors to be listed or counted (for applicable actions)")
+	fs.BoolVar(&o.excludeBots, "exclude-bots", false, "Exclude bot accounts from -editor-list")
+	fs.IntVar(&o.comebackGap, "comeback-gap", 2, "Minimum consecutive inactive years for -yearly-comebacks")
 }
 
 // doSingleYearEditsCmd contains common code for commands that read a single year's editor stats.
-// If editName is non-empty, it will be parsed and the corresponding edit type will be returned.
+// Duplicate editor records are handled according to dups.
+// If editName is non-empty, it will be parsed by parseTypeSet and the matched edit types
+// will be returned.
 // If the returned int is non-zero, a failure occurred and it should be used as the exit code.
-func doSingleYearEditsCmd(jsonDir string, year int, editName string) (
-	[]mbstats.EditorStats, mbstats.EditType, int) {
-	var et mbstats.EditType
+func doSingleYearEditsCmd(ctx context.Context, jsonDir string, year int, dups dupPolicy,
+	editName string) ([]mbstats.EditorStats, typeSet, int) {
+	var ts typeSet
 	if editName != "" {
 		var err error
-		if et, err = mbstats.NamedEditType(editName); err != nil {
+		if ts, err = parseTypeSet(editName); err != nil {
 			fmt.Fprintf(os.Stderr, "Failed looking up %q: %v\n", editName, err)
-			return nil, 0, 2
+			return nil, nil, 2
 		}
 	}
-	stats, err := readEditorStats(filepath.Join(jsonDir, fmt.Sprintf("editors-%d.json", year)))
+	stats, err := readEditorStats(ctx, filepath.Join(jsonDir, fmt.Sprintf("editors-%d.json", year)), dups)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Failed reading editor stats:", err)
-		return nil, 0, 1
+		return nil, nil, 1
 	}
-	return stats, et, 0
+	return stats, ts, 0
 }
 
 // doYearlyEditsCmd contains common code for commands that read multiple years' editor stats.
-// If editName is non-empty, it will be parsed and the corresponding edit type will be returned.
+// Stats are read from each of jsonDirs, and the returned slice contains an element for each dir.
+// Duplicate editor records are handled according to dups.
+// If editName is non-empty, it will be parsed by parseTypeSet and the matched edit types
+// will be returned.
 // If the returned int is non-zero, a failure occurred and it should be used as the exit code.
-func doYearlyEditsCmd(jsonDir string, minYear, maxYear int, editName string) (
-	[]yearEditorStats, mbstats.EditType, int) {
-	var et mbstats.EditType
+func doYearlyEditsCmd(ctx context.Context, jsonDirs []string, minYear, maxYear int, dups dupPolicy,
+	editName string) ([]*mbstats.Series[yearEditorStats], typeSet, int) {
+	dirStats, ts, ret := doPeriodEditsCmd(jsonDirs, func(dir string) (*mbstats.Series[yearEditorStats], error) {
+		return readAllEditorStats(ctx, dir, minYear, maxYear, dups)
+	}, editName)
+	if ret != 0 {
+		return nil, nil, ret
+	}
+	if err := checkYearLabels(dirStats); err != nil {
+		fmt.Fprintln(os.Stderr, "Incompatible input dirs:", err)
+		return nil, nil, 2
+	}
+	return dirStats, ts, 0
+}
+
+// doYearlySumCmd is similar to doYearlyEditsCmd, but each dir's stats are streamed
+// to the function returned by getFn as described by sumAllEditorStats, and the
+// returned yearEditorStats only contain summed values.
+func doYearlySumCmd(ctx context.Context, jsonDirs []string, minYear, maxYear int, dups dupPolicy,
+	editName string, getFn func(ts typeSet) yearlyFunc) ([]*mbstats.Series[yearEditorStats], int) {
+	var ts typeSet
 	if editName != "" {
 		var err error
-		if et, err = mbstats.NamedEditType(editName); err != nil {
+		if ts, err = parseTypeSet(editName); err != nil {
 			fmt.Fprintf(os.Stderr, "Failed looking up %q: %v\n", editName, err)
-			return nil, 0, 2
+			return nil, 2
 		}
 	}
-	stats, err := readAllEditorStats(jsonDir, minYear, maxYear)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "Failed reading editor stats:", err)
-		return nil, 0, 1
+	fn := getFn(ts)
+	dirStats, _, ret := doPeriodEditsCmd(jsonDirs, func(dir string) (*mbstats.Series[yearEditorStats], error) {
+		return sumAllEditorStats(ctx, dir, minYear, maxYear, dups, fn)
+	}, "")
+	if ret != 0 {
+		return nil, ret
+	}
+	if err := checkYearLabels(dirStats); err != nil {
+		fmt.Fprintln(os.Stderr, "Incompatible input dirs:", err)
+		return nil, 2
+	}
+	return dirStats, 0
+}
+
+// summedYearly is a yearlyFunc that returns values computed by sumAllEditorStats.
+func summedYearly(ys *yearEditorStats) []float64 { return ys.sums }
+
+// doPeriodEditsCmd is similar to doYearlyEditsCmd, but read is called to read
+// each dir's stats (e.g. for months instead of years).
+func doPeriodEditsCmd(jsonDirs []string, read func(dir string) (*mbstats.Series[yearEditorStats], error),
+	editName string) ([]*mbstats.Series[yearEditorStats], typeSet, int) {
+	var ts typeSet
+	if editName != "" {
+		var err error
+		if ts, err = parseTypeSet(editName); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed looking up %q: %v\n", editName, err)
+			return nil, nil, 2
+		}
+	}
+	dirStats := make([]*mbstats.Series[yearEditorStats], len(jsonDirs))
+	for i, dir := range jsonDirs {
+		var err error
+		if dirStats[i], err = read(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed reading editor stats from %v: %v\n", dir, err)
+			return nil, nil, 1
+		}
 	}
-	return stats, et, 0
+	return dirStats, ts, 0
 }
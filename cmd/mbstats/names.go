@@ -0,0 +1,89 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/derat/mbstats"
+)
+
+// findEditor returns the editor in stats named name, ignoring case.
+// An editor whose name matches exactly is preferred. nil is returned if no
+// editor matches.
+func findEditor(stats []mbstats.EditorStats, name string) *mbstats.EditorStats {
+	var found *mbstats.EditorStats
+	for i := range stats {
+		es := &stats[i]
+		if es.Name == name {
+			return es
+		} else if found == nil && strings.EqualFold(es.Name, name) {
+			found = es
+		}
+	}
+	return found
+}
+
+// closestNames returns up to n names from stats with the smallest
+// case-insensitive edit distances to name, in ascending order by distance.
+func closestNames(stats []mbstats.EditorStats, name string, n int) []string {
+	type match struct {
+		name string
+		dist int
+	}
+	lower := strings.ToLower(name)
+	matches := make([]match, 0, len(stats))
+	for _, es := range stats {
+		if es.Name != "" {
+			matches = append(matches, match{es.Name, editDistance(lower, strings.ToLower(es.Name))})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].dist != matches[j].dist {
+			return matches[i].dist < matches[j].dist
+		}
+		return matches[i].name < matches[j].name
+	})
+	if len(matches) > n {
+		matches = matches[:n]
+	}
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.name
+	}
+	return names
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
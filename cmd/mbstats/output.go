@@ -0,0 +1,368 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// outputFormat describes the format in which results are written.
+type outputFormat string
+
+const (
+	formatText outputFormat = "text"     // human-readable aligned columns
+	formatCSV  outputFormat = "csv"      // comma-separated values with header rows
+	formatJSON outputFormat = "json"     // arrays of objects keyed by column name
+	formatMD   outputFormat = "markdown" // GitHub-flavored Markdown tables
+	formatTSV  outputFormat = "tsv"      // unpadded tab-separated values with header rows
+	formatVega outputFormat = "vega"     // Vega-Lite chart specs (only supported for charts)
+)
+
+// parseOutputFormat parses an outputFormat from s.
+func parseOutputFormat(s string) (outputFormat, error) {
+	switch f := outputFormat(s); f {
+	case formatText, formatCSV, formatJSON, formatMD, formatTSV, formatVega:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown format %q", s)
+	}
+}
+
+// column describes a column within a table.
+type column struct {
+	name   string                     // header used by machine-readable formats
+	format string                     // fmt format for formatText, e.g. "%5.1f" ("%v" if empty)
+	text   func(v interface{}) string // if non-nil, used instead of format
+	left   bool                       // left-align values in formatText
+}
+
+// table contains tabular results that can be written in any outputFormat.
+// Cells may be nil or NaN to indicate missing values.
+type table struct {
+	title  string // optional title written before the table
+	cols   []column
+	rows   [][]interface{}
+	header bool                    // also write column names in formatText
+	text   func(w io.Writer) error // if non-nil, used to write the table in formatText
+}
+
+// newTable returns a new table with the supplied columns.
+func newTable(cols ...column) *table {
+	return &table{cols: cols}
+}
+
+// add appends a row containing vals, which must be parallel to t.cols.
+func (t *table) add(vals ...interface{}) {
+	t.rows = append(t.rows, vals)
+}
+
+// limit truncates t to its first n rows. Nothing is done if n is not positive.
+func (t *table) limit(n int) *table {
+	if n > 0 && len(t.rows) > n {
+		t.rows = t.rows[:n]
+	}
+	return t
+}
+
+// writeTables writes tables to w in format f, separating them with blank lines.
+// formatJSON produces a single document: an array of row objects if there is
+// only one table, or an array of objects with "rows" and (optional) "title"
+// properties otherwise.
+func writeTables(w io.Writer, f outputFormat, tables ...*table) error {
+	switch f {
+	case formatJSON:
+		return writeJSON(w, tables)
+	case formatVega:
+		return fmt.Errorf("%v format is only supported for yearly, monthly, and histogram actions", f)
+	}
+	for i, t := range tables {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		var err error
+		switch f {
+		case formatCSV:
+			err = t.writeCSV(w)
+		case formatMD:
+			err = t.writeMarkdown(w)
+		case formatTSV:
+			err = t.writeTSV(w)
+		default:
+			err = t.writeText(w)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// missing returns true if v represents a missing value.
+func missing(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	f, ok := v.(float64)
+	return ok && math.IsNaN(f)
+}
+
+// formatValue formats v for machine-readable formats.
+// Missing values are formatted as empty strings.
+func formatValue(v interface{}) string {
+	if missing(v) {
+		return ""
+	}
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// textValue formats v for human-readable formats.
+func (col *column) textValue(v interface{}) string {
+	switch {
+	case col.text != nil:
+		return col.text(v)
+	case col.format != "":
+		return fmt.Sprintf(col.format, v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// writeText writes t to w as aligned columns. Missing values are written as "-".
+func (t *table) writeText(w io.Writer) error {
+	if t.title != "" {
+		if _, err := fmt.Fprintln(w, t.title+":"); err != nil {
+			return err
+		}
+	}
+	if t.text != nil {
+		return t.text(w)
+	}
+
+	cells := make([][]string, 0, len(t.rows)+1)
+	if t.header {
+		hdr := make([]string, len(t.cols))
+		for i, col := range t.cols {
+			hdr[i] = col.name
+		}
+		cells = append(cells, hdr)
+	}
+	for _, row := range t.rows {
+		strs := make([]string, len(row))
+		for i, v := range row {
+			if missing(v) {
+				strs[i] = "-"
+			} else {
+				strs[i] = t.cols[i].textValue(v)
+			}
+		}
+		cells = append(cells, strs)
+	}
+
+	widths := make([]int, len(t.cols))
+	for _, row := range cells {
+		for i, s := range row {
+			if n := utf8.RuneCountInString(s); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	for _, row := range cells {
+		strs := make([]string, len(row))
+		for i, s := range row {
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(s))
+			if t.cols[i].left {
+				strs[i] = s + pad
+			} else {
+				strs[i] = pad + s
+			}
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(strings.Join(strs, "  "), " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarkdown writes t to w as a Markdown table. If t has a title, it is
+// written in bold before the table. Missing values are written as empty cells.
+func (t *table) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+	if t.title != "" {
+		b.WriteString("**" + t.title + "**\n\n")
+	}
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, s := range cells {
+			b.WriteString(" " + strings.ReplaceAll(s, "|", `\|`) + " |")
+		}
+		b.WriteString("\n")
+	}
+	cells := make([]string, len(t.cols))
+	for i, col := range t.cols {
+		cells[i] = col.name
+	}
+	writeRow(cells)
+	for i, col := range t.cols {
+		if col.left {
+			cells[i] = ":---"
+		} else {
+			cells[i] = "---:"
+		}
+	}
+	writeRow(cells)
+	for _, vals := range t.rows {
+		for i, v := range vals {
+			cells[i] = ""
+			if !missing(v) {
+				cells[i] = strings.TrimSpace(t.cols[i].textValue(v))
+			}
+		}
+		writeRow(cells)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeCSV writes t to w as CSV with a header row.
+// If t has a title, it is written in its own row first.
+func (t *table) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if t.title != "" {
+		cw.Write([]string{t.title})
+	}
+	row := make([]string, len(t.cols))
+	for i, col := range t.cols {
+		row[i] = col.name
+	}
+	cw.Write(row)
+	for _, vals := range t.rows {
+		for i, v := range vals {
+			row[i] = formatValue(v)
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSON writes tables to w as described by writeTables.
+func writeJSON(w io.Writer, tables []*table) error {
+	var b bytes.Buffer
+	if len(tables) == 1 {
+		if err := tables[0].appendJSON(&b); err != nil {
+			return err
+		}
+	} else {
+		b.WriteString("[")
+		for i, t := range tables {
+			if i > 0 {
+				b.WriteString(",\n")
+			}
+			b.WriteString("{")
+			if t.title != "" {
+				title, _ := marshalJSON(t.title)
+				fmt.Fprintf(&b, `"title":%s,`, title)
+			}
+			b.WriteString(`"rows":`)
+			if err := t.appendJSON(&b); err != nil {
+				return err
+			}
+			b.WriteString("}")
+		}
+		b.WriteString("]")
+	}
+	b.WriteString("\n")
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// appendJSON appends t's rows to b as an array of objects keyed by column name.
+// Properties are written in column order, and missing values are written as null.
+func (t *table) appendJSON(b *bytes.Buffer) error {
+	b.WriteString("[")
+	for i, vals := range t.rows {
+		if i > 0 {
+			b.WriteString(",\n")
+		}
+		b.WriteString("{")
+		for j, v := range vals {
+			if j > 0 {
+				b.WriteString(",")
+			}
+			name, err := marshalJSON(t.cols[j].name)
+			if err != nil {
+				return err
+			}
+			// JSON can't represent infinite values either.
+			val := []byte("null")
+			if f, ok := v.(float64); !missing(v) && !(ok && math.IsInf(f, 0)) {
+				if val, err = marshalJSON(v); err != nil {
+					return err
+				}
+			}
+			b.Write(name)
+			b.WriteString(":")
+			b.Write(val)
+		}
+		b.WriteString("}")
+	}
+	b.WriteString("]")
+	return nil
+}
+
+// marshalJSON is like json.Marshal but doesn't escape characters like '<'
+// that are significant in HTML, so labels like "<5" remain readable.
+func marshalJSON(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// tsvReplacer replaces characters that can't appear within TSV fields.
+var tsvReplacer = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+
+// writeTSV writes t to w as tab-separated values with a header row.
+// Unlike writeCSV, fields are never quoted; tabs and newlines are replaced by spaces.
+// If t has a title, it is written in its own row first.
+func (t *table) writeTSV(w io.Writer) error {
+	var b strings.Builder
+	if t.title != "" {
+		b.WriteString(tsvReplacer.Replace(t.title) + "\n")
+	}
+	for i, col := range t.cols {
+		if i > 0 {
+			b.WriteString("\t")
+		}
+		b.WriteString(tsvReplacer.Replace(col.name))
+	}
+	b.WriteString("\n")
+	for _, vals := range t.rows {
+		for i, v := range vals {
+			if i > 0 {
+				b.WriteString("\t")
+			}
+			b.WriteString(tsvReplacer.Replace(formatValue(v)))
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,246 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/derat/mbstats/internal/fileutil"
+)
+
+// writePNGChartFile writes a PNG chart of t to a file at path p.
+// See writePNGChart.
+func writePNGChartFile(p string, t *table, ct chartType) error {
+	of, err := fileutil.CreateOutputFile(p)
+	if err != nil {
+		return err
+	}
+	if err := writePNGChart(of, t, ct); err != nil {
+		of.Abort()
+		return err
+	}
+	return of.Commit()
+}
+
+// writePNGChart writes a PNG chart of type ct to w.
+// The chart has the same layout as the one written by writeSVGChart, but text is
+// drawn using a small built-in bitmap font that only supports ASCII characters
+// (with lowercase letters drawn as uppercase).
+func writePNGChart(w io.Writer, t *table, ct chartType) error {
+	l, err := newChartLayout(t, ct)
+	if err != nil {
+		return err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	grid := color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+
+	// Draw horizontal gridlines and y-axis labels.
+	for _, v := range l.yTicks() {
+		y := int(math.Round(l.y(v)))
+		fillRect(img, chartLeft, y, chartWidth-chartRight, y+1, grid)
+		drawText(img, strconv.FormatFloat(v, 'f', -1, 64), chartLeft-6, y, alignEnd, color.Black)
+	}
+
+	// Draw x-axis labels, skipping some if there are too many.
+	every := l.labelEvery()
+	for i, row := range t.rows {
+		if i%every == 0 {
+			drawText(img, fmt.Sprint(row[0]), int(math.Round(l.x(i))),
+				chartHeight-chartBottom+14, alignMiddle, color.Black)
+		}
+	}
+	fillRect(img, chartLeft, chartTop, chartLeft+1, chartHeight-chartBottom, color.Black)
+	base := int(math.Round(l.baseline()))
+	fillRect(img, chartLeft, base, chartWidth-chartRight, base+1, color.Black)
+
+	// Draw each series as bars or as one or more lines, plus a legend entry.
+	for c := 1; c < len(t.cols); c++ {
+		col := parseHexColor(chartColors[(c-1)%len(chartColors)])
+		if ct == barChart {
+			zero := l.baseline()
+			for i, row := range t.rows {
+				if f, ok := chartValue(row[c]); ok {
+					x, y := l.barX(i, c), l.y(f)
+					fillRect(img, int(math.Round(x)), int(math.Round(math.Min(y, zero))),
+						int(math.Round(x+l.barWidth)), int(math.Round(math.Max(y, zero))), col)
+				}
+			}
+		} else {
+			var pts [][2]float64
+			flush := func() {
+				if len(pts) == 1 {
+					fillCircle(img, pts[0][0], pts[0][1], 3, col)
+				}
+				for i := 1; i < len(pts); i++ {
+					drawLine(img, pts[i-1][0], pts[i-1][1], pts[i][0], pts[i][1], col)
+				}
+				pts = pts[:0]
+			}
+			for i, row := range t.rows {
+				if f, ok := chartValue(row[c]); ok {
+					pts = append(pts, [2]float64{l.x(i), l.y(f)})
+				} else {
+					flush()
+				}
+			}
+			flush()
+		}
+
+		if len(t.cols) > 2 {
+			y := l.legendY(c)
+			fillRect(img, chartLeft+10, y-5, chartLeft+20, y+5, col)
+			drawText(img, t.cols[c].name, chartLeft+26, y, alignStart, color.Black)
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// parseHexColor parses a color like "#1f77b4".
+func parseHexColor(s string) color.RGBA {
+	v, _ := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32)
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}
+}
+
+// fillRect fills the rectangle with corners (x0, y0) (inclusive) and (x1, y1) (exclusive).
+func fillRect(img draw.Image, x0, y0, x1, y1 int, c color.Color) {
+	draw.Draw(img, image.Rect(x0, y0, x1, y1), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// fillCircle fills a circle with radius r centered at (cx, cy).
+func fillCircle(img draw.Image, cx, cy, r float64, c color.Color) {
+	for y := int(math.Floor(cy - r)); y <= int(math.Ceil(cy+r)); y++ {
+		for x := int(math.Floor(cx - r)); x <= int(math.Ceil(cx+r)); x++ {
+			if dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy; dx*dx+dy*dy <= r*r {
+				img.Set(x, y, c)
+			}
+		}
+	}
+}
+
+// drawLine draws a line two pixels wide from (x0, y0) to (x1, y1).
+func drawLine(img draw.Image, x0, y0, x1, y1 float64, c color.Color) {
+	steps := int(math.Ceil(2 * math.Max(math.Abs(x1-x0), math.Abs(y1-y0))))
+	for i := 0; i <= steps; i++ {
+		frac := 0.0
+		if steps > 0 {
+			frac = float64(i) / float64(steps)
+		}
+		fillCircle(img, x0+frac*(x1-x0), y0+frac*(y1-y0), 1, c)
+	}
+}
+
+// textAlign describes how text is horizontally positioned by drawText.
+type textAlign int
+
+const (
+	alignStart textAlign = iota
+	alignMiddle
+	alignEnd
+)
+
+const (
+	glyphWidth  = 3 // columns in each glyph in pngFont
+	glyphHeight = 5 // rows in each glyph in pngFont
+	glyphScale  = 2 // pixels per glyph column or row
+)
+
+// drawText draws s vertically centered at y and horizontally positioned
+// relative to x according to align.
+func drawText(img draw.Image, s string, x, y int, align textAlign, c color.Color) {
+	s = strings.ToUpper(s)
+	const advance = (glyphWidth + 1) * glyphScale
+	width := utf8.RuneCountInString(s)*advance - glyphScale
+	switch align {
+	case alignMiddle:
+		x -= width / 2
+	case alignEnd:
+		x -= width
+	}
+	y -= glyphHeight * glyphScale / 2
+	for _, r := range s {
+		glyph, ok := pngFont[r]
+		if !ok {
+			glyph = pngFont['?']
+		}
+		for row, bits := range strings.Split(glyph, "/") {
+			for col, b := range bits {
+				if b == '#' {
+					fillRect(img, x+col*glyphScale, y+row*glyphScale,
+						x+(col+1)*glyphScale, y+(row+1)*glyphScale, c)
+				}
+			}
+		}
+		x += advance
+	}
+}
+
+// pngFont contains glyphs used by drawText. Each glyph consists of
+// glyphHeight slash-separated rows of glyphWidth pixels, with '#' for
+// pixels that are drawn.
+var pngFont = map[rune]string{
+	' ':  ".../.../.../.../...",
+	'0':  "###/#.#/#.#/#.#/###",
+	'1':  ".#./##./.#./.#./###",
+	'2':  "###/..#/###/#../###",
+	'3':  "###/..#/.##/..#/###",
+	'4':  "#.#/#.#/###/..#/..#",
+	'5':  "###/#../###/..#/###",
+	'6':  "###/#../###/#.#/###",
+	'7':  "###/..#/.#./.#./.#.",
+	'8':  "###/#.#/###/#.#/###",
+	'9':  "###/#.#/###/..#/###",
+	'A':  ".#./#.#/###/#.#/#.#",
+	'B':  "##./#.#/##./#.#/##.",
+	'C':  ".##/#../#../#../.##",
+	'D':  "##./#.#/#.#/#.#/##.",
+	'E':  "###/#../##./#../###",
+	'F':  "###/#../##./#../#..",
+	'G':  ".##/#../#.#/#.#/.##",
+	'H':  "#.#/#.#/###/#.#/#.#",
+	'I':  "###/.#./.#./.#./###",
+	'J':  "..#/..#/..#/#.#/.#.",
+	'K':  "#.#/#.#/##./#.#/#.#",
+	'L':  "#../#../#../#../###",
+	'M':  "#.#/###/###/#.#/#.#",
+	'N':  "##./#.#/#.#/#.#/#.#",
+	'O':  ".#./#.#/#.#/#.#/.#.",
+	'P':  "##./#.#/##./#../#..",
+	'Q':  ".#./#.#/#.#/##./.##",
+	'R':  "##./#.#/##./#.#/#.#",
+	'S':  ".##/#../.#./..#/##.",
+	'T':  "###/.#./.#./.#./.#.",
+	'U':  "#.#/#.#/#.#/#.#/###",
+	'V':  "#.#/#.#/#.#/#.#/.#.",
+	'W':  "#.#/#.#/###/###/#.#",
+	'X':  "#.#/#.#/.#./#.#/#.#",
+	'Y':  "#.#/#.#/.#./.#./.#.",
+	'Z':  "###/..#/.#./#../###",
+	'-':  ".../.../###/.../...",
+	'+':  ".../.#./###/.#./...",
+	'.':  ".../.../.../.../.#.",
+	',':  ".../.../.../.#./#..",
+	':':  ".../.#./.../.#./...",
+	'/':  "..#/..#/.#./#../#..",
+	'<':  "..#/.#./#../.#./..#",
+	'>':  "#../.#./..#/.#./#..",
+	'=':  ".../###/.../###/...",
+	'%':  "#.#/..#/.#./#../#.#",
+	'(':  ".#./#../#../#../.#.",
+	')':  ".#./..#/..#/..#/.#.",
+	'_':  ".../.../.../.../###",
+	'\'': ".#./.#./.../.../...",
+	'?':  "###/..#/.#./.../.#.",
+}
@@ -0,0 +1,61 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"testing"
+)
+
+func TestWritePNGChart(t *testing.T) {
+	tbl := newTable(column{name: "year"}, column{name: "edits"}, column{name: "editors"})
+	tbl.add("2020", 10.0, 4.0)
+	tbl.add("2021", math.NaN(), 6.0)
+	tbl.add("2022", 30.0, 5.0)
+
+	for _, ct := range []chartType{lineChart, barChart} {
+		var b bytes.Buffer
+		if err := writePNGChart(&b, tbl, ct); err != nil {
+			t.Fatalf("writePNGChart(..., %v) failed: %v", ct, err)
+		}
+		img, err := png.Decode(&b)
+		if err != nil {
+			t.Fatalf("Failed decoding %v chart: %v", ct, err)
+		}
+		if got := img.Bounds().Size(); got.X != chartWidth || got.Y != chartHeight {
+			t.Errorf("%v chart is %vx%v; want %vx%v", ct, got.X, got.Y, chartWidth, chartHeight)
+		}
+
+		// Each series should be drawn somewhere (outside of the legend, too).
+		l, _ := newChartLayout(tbl, ct)
+		for c := 1; c < len(tbl.cols); c++ {
+			want := parseHexColor(chartColors[c-1])
+			var found bool
+			for y := chartTop; y < chartHeight-chartBottom && !found; y++ {
+				for x := chartLeft + 40; x < chartWidth-chartRight && !found; x++ {
+					r, g, b, _ := img.At(x, y).RGBA()
+					found = uint8(r>>8) == want.R && uint8(g>>8) == want.G && uint8(b>>8) == want.B
+				}
+			}
+			if !found {
+				t.Errorf("%v chart doesn't contain %q series", ct, tbl.cols[c].name)
+			}
+		}
+
+		// The bar for 2022's edits should be drawn down to the x-axis.
+		if ct == barChart {
+			x := int(l.barX(2, 1) + l.barWidth/2)
+			y := int(l.baseline()) - 1
+			if r, g, b, _ := img.At(x, y).RGBA(); uint8(r>>8) != 0x1f || uint8(g>>8) != 0x77 || uint8(b>>8) != 0xb4 {
+				t.Errorf("Bar chart pixel at (%d, %d) isn't filled", x, y)
+			}
+		}
+	}
+
+	if err := writePNGChart(&bytes.Buffer{}, newTable(column{name: "year"}), lineChart); err == nil {
+		t.Error("writePNGChart unexpectedly succeeded for empty table")
+	}
+}
@@ -4,67 +4,615 @@
 package main
 
 import (
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
-	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/derat/mbstats"
+	"github.com/derat/mbstats/internal/logutil"
 )
 
+// dupPolicy describes how multiple records for the same editor within a
+// single year's stats (e.g. from concatenated or merged files) are handled.
+type dupPolicy string
+
+const (
+	dupError dupPolicy = "error" // return an error
+	dupSum   dupPolicy = "sum"   // add the records' edit counts
+	dupNewer dupPolicy = "newer" // use the record that appears last
+)
+
+// parseDupPolicy parses a dupPolicy from s.
+func parseDupPolicy(s string) (dupPolicy, error) {
+	switch p := dupPolicy(s); p {
+	case dupError, dupSum, dupNewer:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown policy %q", s)
+	}
+}
+
 // readEditorStats reads the specified editor-<year>.json file written by read-mbdump.
-func readEditorStats(p string) ([]mbstats.EditorStats, error) {
-	f, err := os.Open(p)
-	if err != nil {
+// Duplicate records for the same editor are handled according to dups.
+func readEditorStats(ctx context.Context, p string, dups dupPolicy) ([]mbstats.EditorStats, error) {
+	var stats []mbstats.EditorStats
+	indexes := make(map[mbstats.EditorID]int) // indexes into stats
+	var nconflicts int
+	if err := readEditorRecords(ctx, p, func(es *mbstats.EditorStats) error {
+		i, ok := indexes[es.ID]
+		if !ok {
+			indexes[es.ID] = len(stats)
+			stats = append(stats, *es)
+			return nil
+		}
+
+		nconflicts++
+		switch dups {
+		case dupSum:
+			prev := &stats[i]
+			prev.Edits = mbstats.AddCounts(prev.Edits, es.Edits)
+			prev.AutoEdits = mbstats.AddCounts(prev.AutoEdits, es.AutoEdits)
+		case dupNewer:
+			stats[i] = *es
+		default:
+			return fmt.Errorf("duplicate records for editor %d", es.ID)
+		}
+		return nil
+	}); err != nil {
 		return nil, err
 	}
+	if nconflicts > 0 {
+		logutil.Infof("Resolved %d duplicate record(s) in %v using %q policy", nconflicts, p, dups)
+	}
+	logutil.Debugf("Read %d editor(s) from %v", len(stats), p)
+	return stats, nil
+}
+
+// streamEditorStats is like readEditorStats, but it calls fn with each editor's
+// stats instead of returning all of them. Only editor IDs are held in memory
+// if dups is dupError; other policies require reading all of the stats first.
+func streamEditorStats(ctx context.Context, p string, dups dupPolicy,
+	fn func(es *mbstats.EditorStats) error) error {
+	if dups != dupError {
+		stats, err := readEditorStats(ctx, p, dups)
+		if err != nil {
+			return err
+		}
+		for i := range stats {
+			if err := fn(&stats[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	seen := make(map[mbstats.EditorID]struct{})
+	if err := readEditorRecords(ctx, p, func(es *mbstats.EditorStats) error {
+		if _, ok := seen[es.ID]; ok {
+			return fmt.Errorf("duplicate records for editor %d", es.ID)
+		}
+		seen[es.ID] = struct{}{}
+		return fn(es)
+	}); err != nil {
+		return err
+	}
+	logutil.Debugf("Streamed %d editor(s) from %v", len(seen), p)
+	return nil
+}
+
+// readEditorRecords calls fn with each record from the editor-<year>.json file at p.
+// Records from older stats directories are migrated to mbstats.StatsVersion, and
+// editor information is filled in from the directory's editors file.
+// If cacheStats is true, the records are read from or written to a cache.
+// Reading stops with ctx's error once ctx is done.
+func readEditorRecords(ctx context.Context, p string, fn func(es *mbstats.EditorStats) error) error {
+	md, err := readMetadata(filepath.Dir(p))
+	if err != nil {
+		return err
+	}
+	editors, err := readEditors(filepath.Dir(p))
+	if err != nil {
+		return err
+	}
+	f, fi, err := mbstats.OpenStatsFile(p)
+	if err != nil {
+		return err
+	}
 	defer f.Close()
 
+	// Stop early (without caching partial results) if ctx is done.
+	// Cached records don't include editor information, so it's filled in here.
+	checkFn := func(es *mbstats.EditorStats) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if ed, ok := editors[es.ID]; ok {
+			es.SetEditor(ed)
+		}
+		return fn(es)
+	}
+
+	var cw *statsCacheWriter
+	if cacheStats {
+		if used, err := readStatsCache(p, fi, checkFn); used {
+			logutil.Debugf("Used cached records for %v", p)
+			return err
+		}
+		if cw, err = newStatsCacheWriter(p, fi); err != nil {
+			logutil.Debugf("Not caching records for %v: %v", p, err)
+		}
+	}
+
+	if err := mbstats.ReadEditorStatsVersion(f, md.Version, func(es mbstats.EditorStats) error {
+		if cw != nil {
+			if err := cw.add(&es); err != nil {
+				logutil.Debugf("Failed caching records for %v: %v", p, err)
+				cw.abort()
+				cw = nil
+			}
+		}
+		return checkFn(&es)
+	}); err != nil {
+		if cw != nil {
+			cw.abort()
+		}
+		return err
+	}
+
+	if cw != nil {
+		if err := cw.commit(); err != nil {
+			logutil.Debugf("Failed caching records for %v: %v", p, err)
+		}
+	}
+	return nil
+}
+
+// readIndexedEditors uses dir's editor index (see mbstats.EditorIndexFile) to read
+// the records from dir's editors-<year>.json file for editors for whom match
+// returns true. This is much faster than reading the whole file. nil is returned
+// if the index is missing or out of date, in which case the caller should fall
+// back to reading the file.
+func readIndexedEditors(dir string, year int, match func(ie *mbstats.EditorIndexEntry) bool) []mbstats.EditorStats {
+	ip := filepath.Join(dir, mbstats.EditorIndexFile)
+	sp := filepath.Join(dir, fmt.Sprintf("editors-%d.json", year))
+	if ifi, err := os.Stat(ip); err != nil {
+		logutil.Debugf("Not using editor index: %v", err)
+		return nil
+	} else if sfi, err := os.Stat(sp); err != nil {
+		return nil
+	} else if sfi.ModTime().After(ifi.ModTime()) {
+		logutil.Debugf("Not using editor index older than %v", sp)
+		return nil
+	}
+
+	var offsets []int64
+	var ids []mbstats.EditorID
+	if err := func() error {
+		f, err := os.Open(ip)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		dec := json.NewDecoder(f)
+		for {
+			var ie mbstats.EditorIndexEntry
+			if err := dec.Decode(&ie); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if off, ok := ie.Offsets[year]; ok && match(&ie) {
+				offsets = append(offsets, off)
+				ids = append(ids, ie.ID)
+			}
+		}
+	}(); err != nil {
+		logutil.Debugf("Failed reading editor index: %v", err)
+		return nil
+	}
+
+	md, err := readMetadata(dir)
+	if err != nil {
+		return nil
+	}
+	editors, err := readEditors(dir)
+	if err != nil {
+		return nil
+	}
+	f, err := os.Open(sp)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
 	var stats []mbstats.EditorStats
+	for i, off := range offsets {
+		var es mbstats.EditorStats
+		if _, err := f.Seek(off, io.SeekStart); err != nil {
+			return nil
+		}
+		if err := json.NewDecoder(f).Decode(&es); err != nil || es.ID != ids[i] {
+			logutil.Debugf("Editor index doesn't match %v", sp)
+			return nil
+		}
+		if err := mbstats.MigrateEditorStats(&es, md.Version); err != nil {
+			logutil.Debugf("Not using editor index: %v", err)
+			return nil
+		}
+		if ed, ok := editors[es.ID]; ok {
+			es.SetEditor(ed)
+		}
+		stats = append(stats, es)
+	}
+	logutil.Debugf("Read %d editor(s) from %v using index", len(stats), sp)
+	return stats
+}
+
+// readVoterStats reads the specified voters-<year>.json file written by read-mbdump.
+func readVoterStats(p string) ([]mbstats.VoterStats, error) {
+	f, _, err := mbstats.OpenStatsFile(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var stats []mbstats.VoterStats
 	dec := json.NewDecoder(f)
 	for {
-		var es mbstats.EditorStats
-		if err := dec.Decode(&es); err == io.EOF {
+		var vs mbstats.VoterStats
+		if err := dec.Decode(&vs); err == io.EOF {
 			break
 		} else if err != nil {
 			return nil, err
 		}
-		stats = append(stats, es)
+		stats = append(stats, vs)
+	}
+	return stats, nil
+}
+
+// readNoteStats reads the specified notes-<year>.json file written by read-mbdump.
+func readNoteStats(p string) ([]mbstats.NoteStats, error) {
+	f, _, err := mbstats.OpenStatsFile(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var stats []mbstats.NoteStats
+	dec := json.NewDecoder(f)
+	for {
+		var ns mbstats.NoteStats
+		if err := dec.Decode(&ns); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		stats = append(stats, ns)
 	}
 	return stats, nil
 }
 
+// readWeekdays reads the weekdays.json file written by read-mbdump.
+// The returned map contains total edits for each day of the week (indexed by
+// time.Weekday), keyed by year.
+func readWeekdays(p string) (map[int][]int32, error) {
+	f, _, err := mbstats.OpenStatsFile(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var days map[int][]int32
+	if err := json.NewDecoder(f).Decode(&days); err != nil {
+		return nil, err
+	}
+	for year, counts := range days {
+		if len(counts) != 7 {
+			return nil, fmt.Errorf("%d has %d day(s)", year, len(counts))
+		}
+	}
+	return days, nil
+}
+
+// readEditorNames reads the editor-names.json file written by read-mbdump.
+func readEditorNames(p string) ([]mbstats.EditorNameHistory, error) {
+	f, _, err := mbstats.OpenStatsFile(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var hists []mbstats.EditorNameHistory
+	dec := json.NewDecoder(f)
+	for {
+		var hist mbstats.EditorNameHistory
+		if err := dec.Decode(&hist); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		hists = append(hists, hist)
+	}
+	return hists, nil
+}
+
+// readMetadata reads the metadata file written by read-mbdump to dir.
+// See mbstats.ReadMetadata.
+func readMetadata(dir string) (*mbstats.Metadata, error) {
+	if _, err := os.Stat(filepath.Join(dir, mbstats.MetadataFile)); os.IsNotExist(err) {
+		logutil.Debugf("No metadata in %v; using defaults", dir)
+	}
+	return mbstats.ReadMetadata(dir)
+}
+
+// editorsCache holds the editors read by readEditors, keyed by directory.
+var editorsCache = struct {
+	sync.Mutex
+	dirs map[string]map[mbstats.EditorID]*mbstats.Editor
+}{dirs: make(map[string]map[mbstats.EditorID]*mbstats.Editor)}
+
+// readEditors returns the editors from dir's editors file written by read-mbdump.
+// See mbstats.ReadEditors. Results are cached, since the file is needed when
+// reading each of the directory's stats files.
+func readEditors(dir string) (map[mbstats.EditorID]*mbstats.Editor, error) {
+	editorsCache.Lock()
+	defer editorsCache.Unlock()
+	if editors, ok := editorsCache.dirs[dir]; ok {
+		return editors, nil
+	}
+	editors, err := mbstats.ReadEditors(dir)
+	if err != nil {
+		return nil, err
+	}
+	logutil.Debugf("Read %d editor(s) from %v", len(editors), filepath.Join(dir, mbstats.EditorsFile))
+	editorsCache.dirs[dir] = editors
+	return editors, nil
+}
+
+// yearEditorStats contains the stats for a single year. It is also used for
+// months read by readAllMonthlyEditorStats, in which case period identifies a
+// month and the other fields describe the month.
 type yearEditorStats struct {
-	year  int
-	stats []mbstats.EditorStats
+	period mbstats.Period
+	label  string    // human-readable label for period, e.g. "2022" or "2022-23"
+	start  time.Time // start of period (inclusive)
+	end    time.Time // end of period (exclusive)
+	stats  []mbstats.EditorStats
+
+	dumpTime time.Time // time at which the dump was created; zero if unknown
+
+	voters []mbstats.VoterStats // nil if voters-<year>.json is missing
+	notes  []mbstats.NoteStats  // nil if notes-<year>.json is missing
+
+	sums []float64 // values computed by sumAllEditorStats
 }
 
 // readAllEditorStats reads and returns all editor-<year>.json files within the
-// specified range from dir. The returned slice is sorted by ascending year.
-func readAllEditorStats(dir string, minYear, maxYear int) ([]yearEditorStats, error) {
-	paths, err := filepath.Glob(filepath.Join(dir, "editors-????.json"))
+// specified range from dir. Duplicate records are handled according to dups.
+func readAllEditorStats(ctx context.Context, dir string, minYear, maxYear int,
+	dups dupPolicy) (*mbstats.Series[yearEditorStats], error) {
+	all, files, err := findYearFiles(dir, minYear, maxYear)
 	if err != nil {
 		return nil, err
 	}
-	all := make([]yearEditorStats, 0, len(paths))
-	for _, p := range paths {
-		ys := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(p), "editors-"), ".json")
-		year, err := strconv.Atoi(ys)
-		if err != nil {
-			continue
+
+	// Decoding large files is slow, so read multiple years at once.
+	years, paths := all.Values(), files.Values()
+	if err := forEachParallel(len(years), func(i int) error {
+		ye := &years[i]
+		var err error
+		if ye.stats, err = readEditorStats(ctx, paths[i], dups); err != nil {
+			return err
+		}
+		ye.voters, err = readVoterStats(filepath.Join(dir, fmt.Sprintf("voters-%v.json", ye.period)))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		ye.notes, err = readNoteStats(filepath.Join(dir, fmt.Sprintf("notes-%v.json", ye.period)))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// streamBatchSize is the number of editors passed at once to functions by sumAllEditorStats.
+const streamBatchSize = 10000
+
+// sumAllEditorStats is like readAllEditorStats, but rather than holding each year's
+// stats in memory, it streams them to fn in batches and stores the sums of the
+// returned values in the sums fields of the returned yearEditorStats, whose stats
+// fields are nil. fn must return values that can be summed across batches
+// (e.g. edit or editor counts), and it must not use voter or note stats.
+func sumAllEditorStats(ctx context.Context, dir string, minYear, maxYear int,
+	dups dupPolicy, fn yearlyFunc) (*mbstats.Series[yearEditorStats], error) {
+	all, files, err := findYearFiles(dir, minYear, maxYear)
+	if err != nil {
+		return nil, err
+	}
+	years, paths := all.Values(), files.Values()
+	if err := forEachParallel(len(years), func(i int) error {
+		ye := &years[i]
+		batch := *ye
+		batch.stats = make([]mbstats.EditorStats, 0, streamBatchSize)
+		flush := func() {
+			for j, v := range fn(&batch) {
+				if j >= len(ye.sums) {
+					ye.sums = append(ye.sums, 0)
+				}
+				ye.sums[j] += v
+			}
+			batch.stats = batch.stats[:0]
 		}
-		if year < minYear || year > maxYear {
-			continue
+		if err := streamEditorStats(ctx, paths[i], dups, func(es *mbstats.EditorStats) error {
+			if batch.stats = append(batch.stats, *es); len(batch.stats) == streamBatchSize {
+				flush()
+			}
+			return nil
+		}); err != nil {
+			return err
 		}
-		stats, err := readEditorStats(p)
+		if len(batch.stats) > 0 || ye.sums == nil {
+			flush()
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// findYearFiles returns the editors-<year>.json files within the specified range in dir.
+// The returned stats only have their period-related fields set. The corresponding
+// files' paths are also returned.
+func findYearFiles(dir string, minYear, maxYear int) (
+	stats *mbstats.Series[yearEditorStats], files *mbstats.Series[string], err error) {
+	md, err := readMetadata(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if files, err = mbstats.EditorStatsFiles(dir); err != nil {
+		return nil, nil, err
+	}
+	files = files.Filter(func(per mbstats.Period, _ string) bool { return !per.IsMonth() })
+	if files.Len() == 0 {
+		logutil.Infof("No editors-<year>.json files in %v", dir)
+	}
+	files = files.Range(mbstats.YearPeriod(minYear), mbstats.YearPeriod(maxYear))
+	return newPeriodStats(md, files), files, nil
+}
+
+// newPeriodStats returns a series containing a yearEditorStats with
+// period-related fields set for each period in files.
+func newPeriodStats(md *mbstats.Metadata, files *mbstats.Series[string]) *mbstats.Series[yearEditorStats] {
+	var stats mbstats.Series[yearEditorStats]
+	for _, per := range files.Periods() {
+		stats.Set(per, yearEditorStats{period: per, label: md.PeriodLabel(per),
+			start: md.PeriodStart(per), end: md.PeriodStart(per.Next()), dumpTime: md.DumpTime})
+	}
+	return &stats
+}
+
+// forEachParallel calls fn for each integer in [0, n) using up to GOMAXPROCS
+// goroutines. After all calls have finished, the error returned for the lowest
+// integer is returned.
+func forEachParallel(n int, fn func(i int) error) error {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+	idxs := make(chan int, n)
+	for i := 0; i < n; i++ {
+		idxs <- i
+	}
+	close(idxs)
+
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idxs {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
 		if err != nil {
-			return nil, err
+			return err
 		}
-		all = append(all, yearEditorStats{year, stats})
 	}
-	sort.Slice(all, func(i, j int) bool { return all[i].year < all[j].year })
+	return nil
+}
+
+// parseMonth parses a "YYYY-MM" string.
+func parseMonth(s string) (mbstats.Period, error) {
+	per, err := mbstats.ParsePeriod(s)
+	if err == nil && !per.IsMonth() {
+		err = fmt.Errorf("%q isn't a month", s)
+	}
+	return per, err
+}
+
+// readAllMonthlyEditorStats is similar to readAllEditorStats but reads all
+// editor-<year>-<month>.json files (as written by "read-mbdump -monthly") from dir.
+func readAllMonthlyEditorStats(ctx context.Context, dir string, minMonth, maxMonth mbstats.Period,
+	dups dupPolicy) (*mbstats.Series[yearEditorStats], error) {
+	md, err := readMetadata(dir)
+	if err != nil {
+		return nil, err
+	}
+	files, err := mbstats.EditorStatsFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	files = files.Filter(func(per mbstats.Period, _ string) bool { return per.IsMonth() })
+	if files.Len() == 0 {
+		return nil, errors.New("no monthly stats (run read-mbdump with -monthly)")
+	}
+	files = files.Range(minMonth, maxMonth)
+	all := newPeriodStats(md, files)
+	months, paths := all.Values(), files.Values()
+	if err := forEachParallel(len(months), func(i int) error {
+		var err error
+		months[i].stats, err = readEditorStats(ctx, paths[i], dups)
+		return err
+	}); err != nil {
+		return nil, err
+	}
 	return all, nil
 }
+
+// event describes an external event, e.g. a software release or schema change.
+type event struct {
+	time  time.Time
+	label string
+}
+
+// readEvents reads events from the CSV file at p. Each row should contain a date
+// (either "YYYY-MM-DD" or "YYYY") and a label. An initial header row is skipped.
+func readEvents(p string) ([]event, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = 2
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var events []event
+	for i, row := range rows {
+		ds := strings.TrimSpace(row[0])
+		t, err := time.Parse("2006-01-02", ds)
+		if err != nil {
+			if t, err = time.Parse("2006", ds); err != nil {
+				if i == 0 {
+					continue // header
+				}
+				return nil, fmt.Errorf("bad date %q on line %d", ds, i+1)
+			}
+		}
+		events = append(events, event{t, strings.TrimSpace(row[1])})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].time.Before(events[j].time) })
+	return events, nil
+}
@@ -0,0 +1,280 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/derat/mbstats"
+	"github.com/derat/mbstats/histogram"
+	"github.com/derat/mbstats/internal/fileutil"
+)
+
+// runner holds the state needed to run a parsed command.
+type runner struct {
+	ctx  context.Context
+	cmd  *command
+	opts *options
+	fs   *flag.FlagSet // parsed flags, used to check which flags were passed
+	arg  string        // command's positional argument, e.g. an edit type
+	args []string      // remaining positional args, i.e. input dirs
+
+	format outputFormat
+	stdout io.Writer // stdout or the file passed via -o
+	dups   dupPolicy
+	hs     *histSpec
+	events []event
+}
+
+// dir returns the first input dir.
+func (r *runner) dir() string { return r.args[0] }
+
+// run prepares output and runs r.cmd, returning the exit code.
+func (r *runner) run() (code int) {
+	o := r.opts
+	if err := o.log.Apply(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	cacheStats = o.cache
+
+	switch r.cmd.dirs {
+	case oneDir:
+		if len(r.args) != 1 {
+			r.fs.Usage()
+			return 2
+		}
+	case multiDirs:
+		if len(r.args) < 1 {
+			r.fs.Usage()
+			return 2
+		}
+	}
+
+	var err error
+	if r.format, err = parseOutputFormat(o.format); err != nil {
+		fmt.Fprintln(os.Stderr, "Bad -format flag:", err)
+		return 2
+	}
+
+	// Write results to a temporary file that's moved to the path passed
+	// via -o on success or deleted on failure.
+	r.stdout = os.Stdout
+	if o.outPath != "" {
+		of, err := fileutil.CreateOutputFile(o.outPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed creating output file:", err)
+			return 1
+		}
+		defer func() {
+			if code != 0 {
+				of.Abort()
+			} else if err := of.Commit(); err != nil {
+				fmt.Fprintln(os.Stderr, "Failed writing output file:", err)
+				code = 1
+			}
+		}()
+		r.stdout = of
+	}
+
+	// Size and color histogram bars to suit the terminal if there is one.
+	var isTerm bool
+	if o.outPath == "" {
+		var width int
+		if width, isTerm = terminalWidth(os.Stdout); width > 0 {
+			textHistStyle.BarWidth = 0
+			textHistStyle.Width = width
+		}
+	}
+	switch o.color {
+	case "auto":
+		textHistStyle.Color = isTerm && os.Getenv("NO_COLOR") == ""
+	case "always":
+		textHistStyle.Color = true
+	case "never":
+	default:
+		fmt.Fprintf(os.Stderr, "Bad -color flag %q\n", o.color)
+		return 2
+	}
+	textHistStyle.Unicode = o.histUnicode
+	textHistStyle.Percent = o.histPercent
+
+	if r.cmd.dirs == noDirs {
+		return r.cmd.run(r)
+	}
+
+	if r.dups, err = parseDupPolicy(o.dups); err != nil {
+		fmt.Fprintln(os.Stderr, "Bad -duplicates flag:", err)
+		return 2
+	}
+	if o.histBuckets < 1 {
+		fmt.Fprintln(os.Stderr, "-histogram-buckets must be positive")
+		return 2
+	}
+	r.hs = &histSpec{min: o.histMin, max: o.histMax, buckets: o.histBuckets, quantiles: o.histQuantiles,
+		weighted: o.histWeighted, autoMin: true, autoMax: true}
+	r.fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "histogram-min":
+			r.hs.autoMin = false
+		case "histogram-max":
+			r.hs.autoMax = false
+		}
+	})
+	if o.eventsFile != "" {
+		if r.events, err = readEvents(o.eventsFile); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed reading events:", err)
+			return 1
+		}
+	}
+
+	return r.cmd.run(r)
+}
+
+// write writes tables to stdout (or -o) in the format specified by -format.
+func (r *runner) write(tables ...*table) int {
+	if err := writeTables(r.stdout, r.format, tables...); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed writing output:", err)
+		return 1
+	}
+	return 0
+}
+
+// writeCharted writes tables to stdout like write, and also writes a chart
+// of t to the files specified by -chart, -chart-out, and -gnuplot, if any. If -format=vega
+// was passed, a Vega-Lite spec charting t is written to stdout instead of
+// tables. ct is used if -chart-type wasn't supplied.
+func (r *runner) writeCharted(t *table, ct chartType, tables ...*table) int {
+	o := r.opts
+	if o.chartType != "" {
+		var err error
+		if ct, err = parseChartType(o.chartType); err != nil {
+			fmt.Fprintln(os.Stderr, "Bad -chart-type flag:", err)
+			return 2
+		}
+	}
+	if o.chartFile != "" {
+		if err := writeSVGChartFile(o.chartFile, t, ct); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed writing chart:", err)
+			return 1
+		}
+	}
+	if o.chartOut != "" {
+		if err := writePNGChartFile(o.chartOut, t, ct); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed writing chart:", err)
+			return 1
+		}
+	}
+	if o.gnuplotFile != "" {
+		if err := writeGnuplotFile(o.gnuplotFile, t, ct); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed writing gnuplot script:", err)
+			return 1
+		}
+	}
+	if r.format == formatVega {
+		if err := writeVegaLite(r.stdout, t, ct); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed writing output:", err)
+			return 1
+		}
+		return 0
+	}
+	return r.write(tables...)
+}
+
+// writeHist writes h to stdout and charts it if requested.
+func (r *runner) writeHist(h *histogram.Histogram) int {
+	return r.writeCharted(histChartTable(h, "count"), barChart, histTable(h))
+}
+
+// printYearlyResults prints the results of a yearly action, followed by
+// an analysis of the first value if events were supplied. names contains
+// the names of the values returned by fn, and valFormat is used to format
+// them in text output.
+func (r *runner) printYearlyResults(dirStats []*mbstats.Series[yearEditorStats], valFormat string,
+	names []string, fn yearlyFunc) int {
+	tables := []*table{yearlyTable(r.args, dirStats, valFormat, names, fn)}
+	if len(r.events) > 0 {
+		tables = append(tables, eventTable(r.args, dirStats, r.events, r.opts.eventWindow, fn))
+	}
+	return r.writeCharted(tables[0], lineChart, tables...)
+}
+
+// printMonthlyResults is similar to printYearlyResults but for monthly stats.
+func (r *runner) printMonthlyResults(dirStats []*mbstats.Series[yearEditorStats], valFormat string,
+	names []string, fn yearlyFunc) int {
+	t := yearlyTable(r.args, dirStats, valFormat, names, fn)
+	t.cols[0].name = "month"
+	return r.writeCharted(t, lineChart, t)
+}
+
+// typeColumns returns fn(ts), where ts contains the edit types matched by patterns.
+// If -split-types was passed, the values for each comma-separated pattern are
+// instead printed in separate columns.
+func (r *runner) typeColumns(patterns string, ts typeSet, fn func(ts typeSet) yearlyFunc) yearlyFunc {
+	if !r.opts.splitTypes {
+		return fn(ts)
+	}
+	sets, _ := splitTypeSets(patterns) // already validated by doYearlyEditsCmd
+	fns := make([]yearlyFunc, len(sets))
+	for i, set := range sets {
+		fns[i] = fn(set)
+	}
+	return concatYearly(fns)
+}
+
+// typeNames returns names, the names of the values computed by a function
+// passed to typeColumns. If -split-types was passed, a copy of names is
+// returned for each comma-separated pattern in patterns.
+func (r *runner) typeNames(patterns string, names ...string) []string {
+	if !r.opts.splitTypes {
+		return names
+	}
+	var all []string
+	for _, pattern := range strings.Split(patterns, ",") {
+		for _, name := range names {
+			all = append(all, name+"["+pattern+"]")
+		}
+	}
+	return all
+}
+
+// doMonthlyEditsCmd is a wrapper around doPeriodEditsCmd that reads
+// monthly stats within the range specified by -min-month and -max-month.
+func (r *runner) doMonthlyEditsCmd(editName string) ([]*mbstats.Series[yearEditorStats], typeSet, int) {
+	minPeriod := mbstats.MonthPeriod(math.MinInt32, time.January)
+	maxPeriod := mbstats.MonthPeriod(math.MaxInt32, time.December)
+	for _, m := range []struct {
+		name, val string
+		dst       *mbstats.Period
+	}{{"min-month", r.opts.minMonth, &minPeriod}, {"max-month", r.opts.maxMonth, &maxPeriod}} {
+		if m.val == "" {
+			continue
+		}
+		var err error
+		if *m.dst, err = parseMonth(m.val); err != nil {
+			fmt.Fprintf(os.Stderr, "Bad -%v value %q (want e.g. 2021-03)\n", m.name, m.val)
+			return nil, nil, 2
+		}
+	}
+	return doPeriodEditsCmd(r.args, func(dir string) (*mbstats.Series[yearEditorStats], error) {
+		return readAllMonthlyEditorStats(r.ctx, dir, minPeriod, maxPeriod, r.dups)
+	}, editName)
+}
+
+// doSingleYearEditsCmd calls doSingleYearEditsCmd for the first input dir.
+func (r *runner) doSingleYearEditsCmd(year int, editName string) ([]mbstats.EditorStats, typeSet, int) {
+	return doSingleYearEditsCmd(r.ctx, r.dir(), year, r.dups, editName)
+}
+
+// doYearlyEditsCmd calls doYearlyEditsCmd for the input dirs using -min-year and -max-year.
+func (r *runner) doYearlyEditsCmd(editName string) ([]*mbstats.Series[yearEditorStats], typeSet, int) {
+	return doYearlyEditsCmd(r.ctx, r.args, r.opts.minYear, r.opts.maxYear, r.dups, editName)
+}
@@ -0,0 +1,62 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/derat/mbstats"
+	"github.com/derat/mbstats/mbdump"
+)
+
+// schemaColumns contains the expected number of columns in each table
+// described by the mbdump package.
+var schemaColumns = map[string]int{
+	"editor":    16,
+	"edit":      10,
+	"edit_note": 5,
+	"vote":      6,
+}
+
+// selfTest checks the internal consistency of the edit type metadata in the
+// mbstats package (see mbstats.CheckEditTypes) and of the table schemas in the
+// mbdump package. An error is returned for each problem that is found.
+func selfTest() []error {
+	return append(mbstats.CheckEditTypes(), checkSchemas(mbdump.Tables, schemaColumns)...)
+}
+
+// checkSchemas checks that tables have unique names and non-empty, unique
+// column names, and that each table's column count matches the one in want.
+func checkSchemas(tables []*mbdump.Table, want map[string]int) []error {
+	var errs []error
+	seen := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		if seen[t.Name] {
+			errs = append(errs, fmt.Errorf("table %q described multiple times", t.Name))
+			continue
+		}
+		seen[t.Name] = true
+
+		if n, ok := want[t.Name]; !ok {
+			errs = append(errs, fmt.Errorf("table %q has no expected column count", t.Name))
+		} else if len(t.Columns) != n {
+			errs = append(errs, fmt.Errorf("table %q has %d columns; want %d", t.Name, len(t.Columns), n))
+		}
+		cols := make(map[string]bool, len(t.Columns))
+		for i, c := range t.Columns {
+			if c == "" {
+				errs = append(errs, fmt.Errorf("table %q column %d has empty name", t.Name, i))
+			} else if cols[c] {
+				errs = append(errs, fmt.Errorf("table %q has multiple %q columns", t.Name, c))
+			}
+			cols[c] = true
+		}
+	}
+	for name := range want {
+		if !seen[name] {
+			errs = append(errs, fmt.Errorf("table %q not described", name))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,38 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/derat/mbstats/mbdump"
+)
+
+func TestSelfTest(t *testing.T) {
+	if errs := selfTest(); len(errs) != 0 {
+		t.Errorf("selfTest() = %v", errs)
+	}
+}
+
+func TestCheckSchemas(t *testing.T) {
+	a := &mbdump.Table{Name: "a", Columns: []string{"id", "name"}}
+	for _, tc := range []struct {
+		desc   string
+		tables []*mbdump.Table
+		want   map[string]int
+		nerrs  int
+	}{
+		{"ok", []*mbdump.Table{a}, map[string]int{"a": 2}, 0},
+		{"wrong count", []*mbdump.Table{a}, map[string]int{"a": 3}, 1},
+		{"unexpected table", []*mbdump.Table{a}, map[string]int{}, 1},
+		{"missing table", []*mbdump.Table{a}, map[string]int{"a": 2, "b": 1}, 1},
+		{"duplicate table", []*mbdump.Table{a, a}, map[string]int{"a": 2}, 1},
+		{"empty column", []*mbdump.Table{{Name: "a", Columns: []string{"id", ""}}}, map[string]int{"a": 2}, 1},
+		{"duplicate column", []*mbdump.Table{{Name: "a", Columns: []string{"id", "id"}}}, map[string]int{"a": 2}, 1},
+	} {
+		if errs := checkSchemas(tc.tables, tc.want); len(errs) != tc.nerrs {
+			t.Errorf("%v: checkSchemas() returned %v; want %d error(s)", tc.desc, errs, tc.nerrs)
+		}
+	}
+}
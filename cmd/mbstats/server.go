@@ -0,0 +1,354 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"container/list"
+	"context"
+	_ "embed"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/derat/mbstats"
+)
+
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// server serves an interactive dashboard and a JSON API (see handleAPI)
+// for a directory written by read-mbdump.
+// Stats files are loaded lazily, and up to cacheYears years of them are cached.
+type server struct {
+	ctx        context.Context // used for loading stats files
+	dir        string
+	dups       dupPolicy
+	md         *mbstats.Metadata
+	years      []int // years with editors-<year>.json files, ascending
+	cacheYears int   // maximum number of years in stats
+
+	// load reads the editor stats for a year. It is called without holding mu.
+	load func(ctx context.Context, year int) ([]mbstats.EditorStats, error)
+
+	mu    sync.Mutex
+	stats map[int]*yearEntry // keyed by year
+	lru   *list.List         // *yearEntry values, most-recently-used first
+}
+
+// yearEntry holds a single year's editor stats in server.
+type yearEntry struct {
+	year  int
+	done  chan struct{} // closed once stats and err are set
+	stats []mbstats.EditorStats
+	err   error
+	elem  *list.Element // in server.lru
+}
+
+// newServer returns a new server for dir that caches up to cacheYears years of stats.
+// Stats files are loaded using ctx.
+func newServer(ctx context.Context, dir string, dups dupPolicy, cacheYears int) (*server, error) {
+	md, err := readMetadata(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := mbstats.GlobStats(dir, "editors-????.json")
+	if err != nil {
+		return nil, err
+	}
+	srv := &server{
+		ctx:        ctx,
+		dir:        dir,
+		dups:       dups,
+		md:         md,
+		cacheYears: cacheYears,
+		stats:      make(map[int]*yearEntry),
+		lru:        list.New(),
+	}
+	srv.load = func(ctx context.Context, year int) ([]mbstats.EditorStats, error) {
+		return readEditorStats(ctx, filepath.Join(srv.dir, fmt.Sprintf("editors-%d.json", year)), srv.dups)
+	}
+	for _, p := range paths {
+		ys := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(p), "editors-"), ".json")
+		if year, err := strconv.Atoi(ys); err == nil {
+			srv.years = append(srv.years, year)
+		}
+	}
+	if len(srv.years) == 0 {
+		return nil, fmt.Errorf("no editor stats in %v", dir)
+	}
+	sort.Ints(srv.years)
+	return srv, nil
+}
+
+// handler returns an http.Handler for srv.
+func (srv *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(dashboardHTML)
+	})
+	mux.Handle("/data/years", errorHandler(srv.handleYears))
+	mux.Handle("/data/histogram", errorHandler(srv.handleHistogram))
+	mux.Handle("/data/leaderboard", errorHandler(srv.handleLeaderboard))
+	mux.Handle("/data/trends", errorHandler(srv.handleTrends))
+	mux.Handle("/api/", http.StripPrefix("/api", errorHandler(srv.handleAPI)))
+	return mux
+}
+
+// errorHandler is an http.Handler that reports errors returned by the function.
+type errorHandler func(w http.ResponseWriter, req *http.Request) error
+
+func (fn errorHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if err := fn(w, req); err != nil {
+		reportError(w, err)
+	}
+}
+
+// yearStats returns the editor stats for year, loading them if needed.
+// Concurrent requests for the same year share a single load, which is performed
+// without holding srv.mu so that requests for other years aren't blocked.
+// Waiting is abandoned if ctx (typically the request's context) is done,
+// but the load continues so that its result can be cached.
+func (srv *server) yearStats(ctx context.Context, year int) ([]mbstats.EditorStats, error) {
+	srv.mu.Lock()
+	ent, ok := srv.stats[year]
+	if ok {
+		srv.lru.MoveToFront(ent.elem)
+	} else {
+		ent = &yearEntry{year: year, done: make(chan struct{})}
+		ent.elem = srv.lru.PushFront(ent)
+		srv.stats[year] = ent
+		srv.evict()
+		go srv.loadYear(ent)
+	}
+	srv.mu.Unlock()
+
+	select {
+	case <-ent.done:
+		return ent.stats, ent.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// loadYear loads ent's stats and marks it as done.
+// Failed loads are removed from the cache so they can be retried.
+func (srv *server) loadYear(ent *yearEntry) {
+	ent.stats, ent.err = srv.load(srv.ctx, ent.year)
+	close(ent.done)
+	if ent.err != nil {
+		srv.mu.Lock()
+		srv.remove(ent)
+		srv.mu.Unlock()
+	}
+}
+
+// evict removes the least-recently-used entries from srv.stats until it contains
+// at most srv.cacheYears entries. Requests already waiting for an evicted entry
+// still receive its stats. srv.mu must be held.
+func (srv *server) evict() {
+	for srv.lru.Len() > srv.cacheYears {
+		srv.remove(srv.lru.Back().Value.(*yearEntry))
+	}
+}
+
+// remove removes ent from srv.stats if it's still present. srv.mu must be held.
+func (srv *server) remove(ent *yearEntry) {
+	if srv.stats[ent.year] == ent {
+		delete(srv.stats, ent.year)
+		srv.lru.Remove(ent.elem)
+	}
+}
+
+// httpError describes an error that should be reported with a specific status code.
+type httpError struct {
+	code int
+	msg  string
+}
+
+func (e *httpError) Error() string { return e.msg }
+
+// badRequest returns an *httpError with http.StatusBadRequest.
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+// reportError writes err to w.
+func reportError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	if he, ok := err.(*httpError); ok {
+		code = he.code
+	}
+	http.Error(w, err.Error(), code)
+}
+
+// intParam returns the named integer query parameter from req, or def if it's unset.
+func intParam(req *http.Request, name string, def int) (int, error) {
+	s := req.FormValue(name)
+	if s == "" {
+		return def, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, badRequest("bad %q parameter %q", name, s)
+	}
+	return v, nil
+}
+
+// boolParam returns the named boolean query parameter from req, or def if it's unset.
+func boolParam(req *http.Request, name string, def bool) (bool, error) {
+	s := req.FormValue(name)
+	if s == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, badRequest("bad %q parameter %q", name, s)
+	}
+	return v, nil
+}
+
+// typeParam parses the "type" query parameter from req as described by parseTypeSet.
+// All types are matched if it's unset.
+func typeParam(req *http.Request) (typeSet, error) {
+	s := req.FormValue("type")
+	if s == "" {
+		return nil, nil
+	}
+	ts, err := parseTypeSet(s)
+	if err != nil {
+		return nil, badRequest("bad type %q: %v", s, err)
+	}
+	return ts, nil
+}
+
+// yearParam returns the "year" query parameter from req, defaulting to the last year.
+func (srv *server) yearParam(req *http.Request) (int, error) {
+	year, err := intParam(req, "year", srv.years[len(srv.years)-1])
+	if err != nil {
+		return 0, err
+	}
+	if i := sort.SearchInts(srv.years, year); i == len(srv.years) || srv.years[i] != year {
+		return 0, &httpError{http.StatusNotFound, fmt.Sprintf("no stats for %d", year)}
+	}
+	return year, nil
+}
+
+// writeTable writes t to w as JSON, or as an SVG or PNG chart of type ct if req's
+// "format" parameter is "svg" or "png". chart is charted instead of t if non-nil.
+func writeTable(w http.ResponseWriter, req *http.Request, t, chart *table, ct chartType) error {
+	if chart == nil {
+		chart = t
+	}
+	switch req.FormValue("format") {
+	case "svg":
+		w.Header().Set("Content-Type", "image/svg+xml")
+		return writeSVGChart(w, chart, ct)
+	case "png":
+		w.Header().Set("Content-Type", "image/png")
+		return writePNGChart(w, chart, ct)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return writeTables(w, formatJSON, t)
+}
+
+func (srv *server) handleYears(w http.ResponseWriter, req *http.Request) error {
+	t := newTable(column{name: "year"}, column{name: "label"})
+	for _, year := range srv.years {
+		t.add(year, srv.md.YearLabel(year))
+	}
+	return writeTable(w, req, t, nil, lineChart)
+}
+
+func (srv *server) handleHistogram(w http.ResponseWriter, req *http.Request) error {
+	year, err := srv.yearParam(req)
+	if err != nil {
+		return err
+	}
+	ts, err := typeParam(req)
+	if err != nil {
+		return err
+	}
+	var min, max, buckets int
+	if min, err = intParam(req, "min", 1); err != nil {
+		return err
+	}
+	if max, err = intParam(req, "max", 100); err != nil {
+		return err
+	}
+	if buckets, err = intParam(req, "buckets", 10); err != nil {
+		return err
+	}
+	if max < min || buckets < 1 || buckets > max-min+1 {
+		return badRequest("bad histogram range")
+	}
+	stats, err := srv.yearStats(req.Context(), year)
+	if err != nil {
+		return err
+	}
+	h := editorHistogram(stats, ts, &histSpec{min: min, max: max, buckets: buckets})
+	return writeTable(w, req, histTable(h), histChartTable(h, "editors"), barChart)
+}
+
+func (srv *server) handleLeaderboard(w http.ResponseWriter, req *http.Request) error {
+	year, err := srv.yearParam(req)
+	if err != nil {
+		return err
+	}
+	ts, err := typeParam(req)
+	if err != nil {
+		return err
+	}
+	limit, err := intParam(req, "limit", 100)
+	if err != nil {
+		return err
+	}
+	minEdits, err := intParam(req, "min", 1)
+	if err != nil {
+		return err
+	}
+	excludeBots, err := boolParam(req, "exclude-bots", false)
+	if err != nil {
+		return err
+	}
+	stats, err := srv.yearStats(req.Context(), year)
+	if err != nil {
+		return err
+	}
+	ecs := getEditorCounts(stats, ts, editorFilters(ts, minEdits, excludeBots))
+	sortEditorCounts(ecs, orderCount, false)
+	if limit > 0 && len(ecs) > limit {
+		ecs = ecs[:limit]
+	}
+	t := newTable(column{name: "rank"}, column{name: "edits"},
+		column{name: "id"}, column{name: "name", left: true})
+	for i, ec := range ecs {
+		t.add(i+1, ec.count, ec.es.ID, ec.es.Name)
+	}
+	return writeTable(w, req, t, nil, barChart)
+}
+
+func (srv *server) handleTrends(w http.ResponseWriter, req *http.Request) error {
+	ts, err := typeParam(req)
+	if err != nil {
+		return err
+	}
+	t := newTable(column{name: "year", left: true}, column{name: "edits"}, column{name: "editors"})
+	for _, year := range srv.years {
+		stats, err := srv.yearStats(req.Context(), year)
+		if err != nil {
+			return err
+		}
+		t.add(srv.md.YearLabel(year), float64(countEdits(stats, ts)), float64(countEditors(stats, ts, 1)))
+	}
+	return writeTable(w, req, t, nil, lineChart)
+}
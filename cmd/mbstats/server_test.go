@@ -0,0 +1,175 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/derat/mbstats"
+)
+
+// testLoader is used as server.load in tests.
+type testLoader struct {
+	mu    sync.Mutex
+	calls map[int]int           // number of loads per year
+	block map[int]chan struct{} // if non-nil, loads wait for the channel to be closed
+	fail  map[int]int           // number of remaining loads that should fail per year
+}
+
+func newTestLoader() *testLoader {
+	return &testLoader{
+		calls: make(map[int]int),
+		block: make(map[int]chan struct{}),
+		fail:  make(map[int]int),
+	}
+}
+
+func (tl *testLoader) load(ctx context.Context, year int) ([]mbstats.EditorStats, error) {
+	tl.mu.Lock()
+	tl.calls[year]++
+	ch := tl.block[year]
+	fail := tl.fail[year] > 0
+	if fail {
+		tl.fail[year]--
+	}
+	tl.mu.Unlock()
+
+	if ch != nil {
+		<-ch
+	}
+	if fail {
+		return nil, errors.New("intentional failure")
+	}
+	return []mbstats.EditorStats{{ID: mbstats.EditorID(year)}}, nil
+}
+
+func (tl *testLoader) numCalls(year int) int {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return tl.calls[year]
+}
+
+// newTestServer returns a server that loads stats using tl.
+func newTestServer(tl *testLoader, cacheYears int) *server {
+	return &server{
+		ctx:        context.Background(),
+		cacheYears: cacheYears,
+		load:       tl.load,
+		stats:      make(map[int]*yearEntry),
+		lru:        list.New(),
+	}
+}
+
+// checkYearStats calls srv.yearStats and checks that the expected stats are returned.
+func checkYearStats(t *testing.T, srv *server, year int) {
+	t.Helper()
+	stats, err := srv.yearStats(context.Background(), year)
+	if err != nil {
+		t.Errorf("yearStats(%d) failed: %v", year, err)
+	} else if want := []mbstats.EditorStats{{ID: mbstats.EditorID(year)}}; !reflect.DeepEqual(stats, want) {
+		t.Errorf("yearStats(%d) = %+v; want %+v", year, stats, want)
+	}
+}
+
+func TestServerYearStats_Concurrent(t *testing.T) {
+	tl := newTestLoader()
+	ch := make(chan struct{})
+	tl.block[2020] = ch
+	srv := newTestServer(tl, 5)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkYearStats(t, srv, 2020)
+		}()
+	}
+
+	// Other years should be loadable while 2020 is still loading.
+	done := make(chan struct{})
+	go func() {
+		checkYearStats(t, srv, 2021)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Loading 2021 blocked by load of 2020")
+	}
+
+	close(ch)
+	wg.Wait()
+	if n := tl.numCalls(2020); n != 1 {
+		t.Errorf("2020 loaded %d times; want 1", n)
+	}
+	checkYearStats(t, srv, 2020)
+	if n := tl.numCalls(2020); n != 1 {
+		t.Errorf("2020 loaded %d times after cached request; want 1", n)
+	}
+}
+
+func TestServerYearStats_Evict(t *testing.T) {
+	tl := newTestLoader()
+	srv := newTestServer(tl, 2)
+
+	for _, year := range []int{2018, 2019, 2020, 2019, 2021, 2019, 2018} {
+		checkYearStats(t, srv, year)
+	}
+	// 2018 is evicted by 2020, and 2020 by 2021 (since 2019 was used more recently).
+	// 2018 is then reloaded, evicting 2021.
+	for year, want := range map[int]int{2018: 2, 2019: 1, 2020: 1, 2021: 1} {
+		if n := tl.numCalls(year); n != want {
+			t.Errorf("%d loaded %d times; want %d", year, n, want)
+		}
+	}
+	if n := len(srv.stats); n != 2 {
+		t.Errorf("Server caches %d years; want 2", n)
+	}
+	for _, year := range []int{2018, 2019} {
+		if _, ok := srv.stats[year]; !ok {
+			t.Errorf("%d not cached", year)
+		}
+	}
+}
+
+func TestServerYearStats_Error(t *testing.T) {
+	tl := newTestLoader()
+	tl.fail[2020] = 1
+	srv := newTestServer(tl, 5)
+
+	if _, err := srv.yearStats(context.Background(), 2020); err == nil {
+		t.Error("yearStats(2020) unexpectedly succeeded")
+	}
+	// Failed loads shouldn't be cached.
+	checkYearStats(t, srv, 2020)
+	if n := tl.numCalls(2020); n != 2 {
+		t.Errorf("2020 loaded %d times; want 2", n)
+	}
+}
+
+func TestServerYearStats_Canceled(t *testing.T) {
+	tl := newTestLoader()
+	ch := make(chan struct{})
+	tl.block[2020] = ch
+	srv := newTestServer(tl, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := srv.yearStats(ctx, 2020); err != context.Canceled {
+		t.Errorf("yearStats(2020) with canceled context returned %v; want %v", err, context.Canceled)
+	}
+	// The load should continue and be used by later requests.
+	close(ch)
+	checkYearStats(t, srv, 2020)
+	if n := tl.numCalls(2020); n != 1 {
+		t.Errorf("2020 loaded %d times; want 1", n)
+	}
+}
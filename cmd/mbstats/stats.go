@@ -6,43 +6,195 @@ package main
 import (
 	"fmt"
 	"io"
+	"math"
+	"regexp"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/derat/mbstats"
+	"github.com/derat/mbstats/histogram"
 	gostats "github.com/montanaflynn/stats"
 )
 
-// countEditors returns the total number of editors with at least one edit of type et.
-func countEditors(stats []mbstats.EditorStats, et mbstats.EditType) int {
+// typeSet is a set of edit types whose counts are aggregated.
+// A nil typeSet contains all edit types.
+type typeSet map[mbstats.EditType]struct{}
+
+// allTypes can be passed to parseTypeSet in place of an edit type name
+// to request edits of all types.
+const allTypes = "ALL"
+
+// parseTypeSet returns a set containing the edit types matched by patterns,
+// a comma-separated list of patterns that are passed to mbstats.MatchEditTypes.
+// If any of the patterns is allTypes, nil is returned.
+func parseTypeSet(patterns string) (typeSet, error) {
+	ts := make(typeSet)
+	for _, pattern := range strings.Split(patterns, ",") {
+		if pattern == allTypes {
+			return nil, nil
+		}
+		types, err := mbstats.MatchEditTypes(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, et := range types {
+			ts[et] = struct{}{}
+		}
+	}
+	return ts, nil
+}
+
+// editTypesTable returns a table listing the IDs, names, entities, and actions of
+// known edit types.
+// If pattern is non-empty, only types with names matched by it as a case-insensitive
+// regular expression are listed, or types with names containing it (ignoring case)
+// if it isn't a valid regular expression.
+func editTypesTable(pattern string) *table {
+	var match func(string) bool
+	if re, err := regexp.Compile("(?i)" + pattern); err == nil {
+		match = re.MatchString
+	} else {
+		lower := strings.ToLower(pattern)
+		match = func(name string) bool { return strings.Contains(strings.ToLower(name), lower) }
+	}
+	t := newTable(column{name: "id", format: "%3d"}, column{name: "name", left: true},
+		column{name: "entity", left: true}, column{name: "action", left: true})
+	for _, et := range mbstats.EditTypes() {
+		if name := mbstats.EditTypeName(et); match(name) {
+			entity, action := mbstats.EditTypeInfo(et)
+			t.add(int(et), name, entity, action)
+		}
+	}
+	return t
+}
+
+// splitTypeSets is similar to parseTypeSet but returns a separate set
+// for each comma-separated pattern in patterns.
+func splitTypeSets(patterns string) ([]typeSet, error) {
+	var sets []typeSet
+	for _, pattern := range strings.Split(patterns, ",") {
+		ts, err := parseTypeSet(pattern)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, ts)
+	}
+	return sets, nil
+}
+
+// types returns the types in ts in ascending order, or nil if ts matches all types.
+func (ts typeSet) types() []mbstats.EditType {
+	if ts == nil {
+		return nil
+	}
+	types := make([]mbstats.EditType, 0, len(ts))
+	for et := range ts {
+		types = append(types, et)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// has returns true if ts contains et. All types are contained by a nil ts.
+func (ts typeSet) has(et mbstats.EditType) bool {
+	if ts == nil {
+		return true
+	}
+	_, ok := ts[et]
+	return ok
+}
+
+// count returns the total number of edits in es with types in ts.
+func (ts typeSet) count(es *mbstats.EditorStats) int {
+	return ts.sum(es.Edits)
+}
+
+// countAuto returns the total number of autoedits in es with types in ts.
+func (ts typeSet) countAuto(es *mbstats.EditorStats) int {
+	return ts.sum(es.AutoEdits)
+}
+
+// sum returns the sum of the counts in m with types in ts.
+func (ts typeSet) sum(m map[mbstats.EditType]int32) int {
 	var cnt int
-	for _, es := range stats {
-		if es.Edits[et] > 0 {
-			cnt++
+	if ts == nil {
+		for _, c := range m {
+			cnt += int(c)
 		}
+		return cnt
+	}
+	for et := range ts {
+		cnt += int(m[et])
 	}
 	return cnt
 }
 
+// countEditors returns the total number of editors with at least minEdits
+// (and at least one) edits with types in ts.
+func countEditors(stats []mbstats.EditorStats, ts typeSet, minEdits int) int {
+	return len(mbstats.FilterEditors(stats, editorFilters(ts, minEdits, false)...))
+}
+
+// editorFilters returns predicates matching editors with at least minEdits
+// (minimum 1) edits with types in ts, excluding bots if excludeBots is true.
+func editorFilters(ts typeSet, minEdits int, excludeBots bool) []mbstats.EditorPredicate {
+	if minEdits < 1 {
+		minEdits = 1
+	}
+	var preds []mbstats.EditorPredicate
+	if ts == nil {
+		preds = append(preds, mbstats.MinEdits(minEdits))
+	} else {
+		preds = append(preds, mbstats.MinTypeEdits(minEdits, ts.types()...))
+	}
+	if excludeBots {
+		preds = append(preds, mbstats.Not(mbstats.IsBot))
+	}
+	return preds
+}
+
+// countEdits returns the total number of edits with types in ts.
+func countEdits(stats []mbstats.EditorStats, ts typeSet) int {
+	var cnt int
+	for i := range stats {
+		cnt += ts.count(&stats[i])
+	}
+	return cnt
+}
+
+// getAutoEditRatio returns the fraction of edits with types in ts that were autoedits.
+// NaN is returned if there are no such edits.
+func getAutoEditRatio(stats []mbstats.EditorStats, ts typeSet) float64 {
+	var edits, auto int
+	for i := range stats {
+		edits += ts.count(&stats[i])
+		auto += ts.countAuto(&stats[i])
+	}
+	if edits == 0 {
+		return math.NaN()
+	}
+	return float64(auto) / float64(edits)
+}
+
 // countEditTypes returns a map from edit type to total number of edits.
 func countEditTypes(stats []mbstats.EditorStats) map[mbstats.EditType]int {
-	counts := make(map[mbstats.EditType]int)
-	for _, es := range stats {
-		for et, cnt := range es.Edits {
-			counts[et] += int(cnt)
-		}
+	ec := newEditColumns(stats, nil)
+	counts := make(map[mbstats.EditType]int, len(ec.types))
+	for i, total := range ec.typeTotals() {
+		counts[ec.types[i]] = total
 	}
 	return counts
 }
 
 // getEditorAgeStats computes the median and mean account age (relative to ref)
-// in years of editors with at least one edit of type et.
-func getEditorAgeStats(stats []mbstats.EditorStats, et mbstats.EditType, ref time.Time) (
+// in years of editors with at least one edit with a type in ts.
+func getEditorAgeStats(stats []mbstats.EditorStats, ts typeSet, ref time.Time) (
 	medianYears, meanYears float64) {
 	var ages gostats.Float64Data
-	for _, es := range stats {
-		if es.Edits[et] > 0 && !es.Created.IsZero() {
-			ages = append(ages, ref.Sub(es.Created).Seconds()/(86400*365))
+	for i, es := range stats {
+		if ts.count(&stats[i]) > 0 && !es.Created.IsZero() {
+			ages = append(ages, accountAgeYears(es.Created, ref))
 		}
 	}
 	var err error
@@ -55,64 +207,1289 @@ func getEditorAgeStats(stats []mbstats.EditorStats, et mbstats.EditType, ref tim
 	return medianYears, meanYears
 }
 
-// printEditTypeCounts prints edit types by descending number of editors.
-func printEditTypeCounts(w io.Writer, stats []mbstats.EditorStats) {
-	counts := countEditTypes(stats)
+// accountAgeYears returns the age in years of an account created at
+// created as of ref.
+func accountAgeYears(created, ref time.Time) float64 {
+	return ref.Sub(created).Seconds() / (86400 * 365)
+}
+
+// ageEditsTables groups editors with at least one edit with a type in ts by their
+// account ages in whole years as of ref and returns a table containing the mean and
+// median number of edits for each group, followed by a table containing the
+// correlation coefficient between account age and edit count.
+func ageEditsTables(stats []mbstats.EditorStats, ts typeSet, ref time.Time) ([]*table, error) {
+	var ages, counts gostats.Float64Data
+	groups := make(map[int]gostats.Float64Data)
+	var maxAge int
+	for i, es := range stats {
+		cnt := ts.count(&stats[i])
+		if cnt == 0 || es.Created.IsZero() {
+			continue
+		}
+		age := accountAgeYears(es.Created, ref)
+		ages = append(ages, age)
+		counts = append(counts, float64(cnt))
+		ag := int(age)
+		groups[ag] = append(groups[ag], float64(cnt))
+		if ag > maxAge {
+			maxAge = ag
+		}
+	}
+	if len(ages) == 0 {
+		return nil, nil
+	}
+
+	t := newTable(column{name: "age", format: "%3d"}, column{name: "editors", format: "%5d"},
+		column{name: "mean", format: "%8.1f"}, column{name: "median", format: "%8.1f"})
+	for ag := 0; ag <= maxAge; ag++ {
+		g := groups[ag]
+		if len(g) == 0 {
+			continue
+		}
+		mean, _ := gostats.Mean(g)
+		median, _ := gostats.Median(g)
+		t.add(ag, len(g), mean, median)
+	}
+	coeff, err := gostats.Pearson(ages, counts)
+	if err != nil {
+		return nil, err
+	}
+	ct := newTable(column{name: "correlation", format: "Correlation: %0.3f", left: true})
+	ct.add(coeff)
+	return []*table{t, ct}, nil
+}
+
+// getOverlap returns the number of editors with edits with types in ts in
+// both stats1 and stats2, in only stats1, and in only stats2.
+func getOverlap(stats1, stats2 []mbstats.EditorStats, ts typeSet) (both, only1, only2 int) {
+	active1 := make(map[mbstats.EditorID]bool)
+	for i := range stats1 {
+		if ts.count(&stats1[i]) > 0 {
+			active1[stats1[i].ID] = true
+		}
+	}
+	for i := range stats2 {
+		if ts.count(&stats2[i]) > 0 {
+			if active1[stats2[i].ID] {
+				both++
+			} else {
+				only2++
+			}
+		}
+	}
+	return both, len(active1) - both, only2
+}
+
+// editTypeCountsTable returns a table listing edit types by descending number of
+// editors. Types with fewer than minEditors editors are omitted.
+func editTypeCountsTable(stats []mbstats.EditorStats, minEditors int) *table {
+	ec := newEditColumns(stats, nil)
 	type typeCount struct {
 		et      mbstats.EditType
 		total   int
 		editors int
 	}
-	types := make([]typeCount, 0, len(counts))
-	for et, cnt := range counts {
-		types = append(types, typeCount{et, cnt, countEditors(stats, et)})
+	types := make([]typeCount, len(ec.types))
+	totals, editors := ec.typeTotals(), ec.typeEditors()
+	for i, et := range ec.types {
+		types[i] = typeCount{et, totals[i], editors[i]}
 	}
-	sort.Slice(types, func(i, j int) bool { return types[i].editors > types[j].editors })
+	// Types with the same number of editors remain in ascending order.
+	sort.SliceStable(types, func(i, j int) bool { return types[i].editors > types[j].editors })
 
-	for _, t := range types {
-		fmt.Fprintf(w, "%5d editors  %v (%d edits)\n", t.editors, mbstats.EditTypeName(t.et), t.total)
+	t := newTable(column{name: "editors", format: "%5d editors"},
+		column{name: "type", left: true}, column{name: "edits", format: "(%d edits)", left: true})
+	for _, tc := range types {
+		if tc.editors < minEditors {
+			break
+		}
+		t.add(tc.editors, mbstats.EditTypeName(tc.et), tc.total)
 	}
+	return t
 }
 
-// printEditorHistogram prints a histogram of per-editor edit counts.
-func printEditorHistogram(w io.Writer, stats []mbstats.EditorStats, et mbstats.EditType,
-	min, max, buckets int) {
-	hist := newHistogram(int64(min), int64(max), buckets)
-	for _, es := range stats {
-		if v := int64(es.Edits[et]); v > 0 {
-			hist.add(v)
-		}
+// editorEditsTable returns a table containing es's edit counts by ascending edit type.
+func editorEditsTable(es *mbstats.EditorStats) *table {
+	types := make([]mbstats.EditType, 0, len(es.Edits))
+	for et := range es.Edits {
+		types = append(types, et)
 	}
-	hist.write(w, 0, 40)
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	t := newTable(column{name: "type", left: true}, column{name: "edits", format: "%5d"})
+	for _, et := range types {
+		t.add(mbstats.EditTypeName(et), es.Edits[et])
+	}
+	return t
 }
 
-func printEditTypeCorrelations(w io.Writer, stats []mbstats.EditorStats) error {
-	typeCounts := countEditTypes(stats)
-	types := make([]mbstats.EditType, 0, len(typeCounts))
-	for et := range typeCounts {
+// editorComparisonTable returns a table with a column containing each editor's
+// per-type edit counts from counts, which is parallel to names. Rows in which
+// the counts differ are marked with asterisks, and a column containing the
+// difference between the counts is added when comparing two editors.
+func editorComparisonTable(names []string, counts []map[mbstats.EditType]int) *table {
+	typeMap := make(map[mbstats.EditType]struct{})
+	for _, m := range counts {
+		for et := range m {
+			typeMap[et] = struct{}{}
+		}
+	}
+	types := make([]mbstats.EditType, 0, len(typeMap))
+	for et := range typeMap {
 		types = append(types, et)
 	}
 	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
 
-	edits := make(map[mbstats.EditType]gostats.Float64Data, len(types))
+	cols := []column{
+		{name: "differs", text: func(v interface{}) string {
+			if v.(bool) {
+				return "*"
+			}
+			return ""
+		}},
+		{name: "type", left: true},
+	}
+	for _, name := range names {
+		cols = append(cols, column{name: name, format: "%5d"})
+	}
+	if len(names) == 2 {
+		cols = append(cols, column{name: "diff", format: "%+6d"})
+	}
+	t := newTable(cols...)
+	t.header = true
+
 	for _, et := range types {
-		vals := make(gostats.Float64Data, len(stats))
-		for i, es := range stats {
-			vals[i] = float64(es.Edits[et])
+		var differs bool
+		for _, m := range counts[1:] {
+			if m[et] != counts[0][et] {
+				differs = true
+			}
+		}
+		row := []interface{}{differs, mbstats.EditTypeName(et)}
+		for _, m := range counts {
+			row = append(row, m[et])
 		}
-		edits[et] = vals
+		if len(names) == 2 {
+			row = append(row, counts[1][et]-counts[0][et])
+		}
+		t.add(row...)
 	}
+	return t
+}
+
+// histSpec describes the buckets used by a histogram.
+type histSpec struct {
+	min, max         int  // range covered by linear buckets
+	autoMin, autoMax bool // compute min or max from the values instead
+	buckets          int  // number of buckets
+	quantiles        bool // choose bucket boundaries from the values' quantiles instead of min and max
+	weighted         bool // count values' weights (e.g. editors' edits) instead of values
+}
 
+// weight returns the amount that a value with the supplied weight should add to its bucket.
+func (hs *histSpec) weight(weight int64) int {
+	if !hs.weighted {
+		return 1
+	}
+	return int(weight)
+}
+
+// resolve returns a copy of hs with min and max computed from vals if requested.
+// The minimum value is used as min and the 99th percentile is used as max so that
+// a few outliers don't squash the rest of the data into the first bucket.
+// The number of buckets is reduced if needed so that each covers at least one value.
+func (hs *histSpec) resolve(vals []int64) *histSpec {
+	res := *hs
+	res.autoMin, res.autoMax = false, false
+	if !hs.autoMin && !hs.autoMax {
+		return &res
+	}
+	if len(vals) > 0 {
+		sorted := append([]int64(nil), vals...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		if hs.autoMin {
+			res.min = int(sorted[0])
+		}
+		if hs.autoMax {
+			res.max = int(sorted[(len(sorted)*99+99)/100-1]) // nearest rank
+		}
+	}
+	if res.max < res.min {
+		res.max = res.min
+	}
+	if n := res.max - res.min + 1; res.buckets > n {
+		res.buckets = n
+	}
+	return &res
+}
+
+// histogram returns a histogram containing vals. If hs.weighted is true, each
+// value is counted using the corresponding element of weights, or using the
+// value itself if weights is nil.
+func (hs *histSpec) histogram(vals, weights []int64) *histogram.Histogram {
+	var hist *histogram.Histogram
+	if hs.quantiles {
+		hist = histogram.NewQuantile(vals, hs.buckets)
+	} else {
+		res := hs.resolve(vals)
+		hist = histogram.New(int64(res.min), int64(res.max), res.buckets)
+	}
+	for i, v := range vals {
+		w := v
+		if weights != nil {
+			w = weights[i]
+		}
+		hist.AddWeighted(v, hs.weight(w))
+	}
+	return hist
+}
+
+// editCountValues returns the non-zero per-editor counts of edits with types in ts.
+func editCountValues(stats []mbstats.EditorStats, ts typeSet) []int64 {
+	counts := getEditCounts(stats, ts)
+	vals := make([]int64, len(counts))
+	for i, v := range counts {
+		vals[i] = int64(v)
+	}
+	return vals
+}
+
+// editorHistogram returns a histogram of per-editor counts of edits with types in ts.
+func editorHistogram(stats []mbstats.EditorStats, ts typeSet, hs *histSpec) *histogram.Histogram {
+	return hs.histogram(editCountValues(stats, ts), nil)
+}
+
+// yearlyHistogramTable returns a table with a row for each bucket of a histogram
+// of per-editor counts of edits with types in ts, and a column with each year's
+// number of editors. The same buckets are used for all years, and underflow and
+// overflow rows are always included.
+func yearlyHistogramTable(yearStats []yearEditorStats, ts typeSet, hs *histSpec) *table {
+	yearVals := make([][]int64, len(yearStats))
+	var all []int64
+	for i, ys := range yearStats {
+		yearVals[i] = editCountValues(ys.stats, ts)
+		all = append(all, yearVals[i]...)
+	}
+	buckets := hs.histogram(all, nil)
+
+	cols := []column{{name: "range", left: true}}
+	hists := make([]*histogram.Histogram, len(yearStats))
+	for i, ys := range yearStats {
+		cols = append(cols, column{name: ys.label, format: "%.0f"})
+		hists[i] = buckets.Empty()
+		for _, v := range yearVals[i] {
+			hists[i].AddWeighted(v, hs.weight(v))
+		}
+	}
+	t := newTable(cols...)
+	t.header = true
+	for i, label := range buckets.Labels() {
+		row := []interface{}{label}
+		for _, h := range hists {
+			cnt := h.Underflow
+			if i > len(h.Buckets) {
+				cnt = h.Overflow
+			} else if i > 0 {
+				cnt = h.Buckets[i-1].Count
+			}
+			row = append(row, float64(cnt))
+		}
+		t.add(row...)
+	}
+	return t
+}
+
+// editorHeatmap returns a heatmap of per-editor counts of edits with types in
+// tx (on the x axis) and ty (on the y axis). Editors with no edits of either type
+// are excluded.
+func editorHeatmap(stats []mbstats.EditorStats, tx, ty typeSet, hs *histSpec) *heatmap {
+	var xs, ys []int64
+	for i := range stats {
+		x, y := int64(tx.count(&stats[i])), int64(ty.count(&stats[i]))
+		if x > 0 || y > 0 {
+			xs = append(xs, x)
+			ys = append(ys, y)
+		}
+	}
+	return newHeatmap(xs, ys, hs)
+}
+
+// typeDiversityHistogram returns a histogram of the number of distinct
+// edit types used by each editor in stats. Editors are weighted by their
+// total edits if hs.weighted is true.
+func typeDiversityHistogram(stats []mbstats.EditorStats, hs *histSpec) *histogram.Histogram {
+	ec := newEditColumns(stats, nil)
+	totals := ec.editorTotals()
+	var vals, edits []int64
+	for i, n := range ec.typesUsed() {
+		if n > 0 {
+			vals = append(vals, int64(n))
+			edits = append(edits, int64(totals[i]))
+		}
+	}
+	return hs.histogram(vals, edits)
+}
+
+// ageHistogram returns a histogram of the account ages in whole years as of ref
+// of editors with at least one edit with a type in ts. Each bucket holds a single year.
+func ageHistogram(stats []mbstats.EditorStats, ts typeSet, ref time.Time) *histogram.Histogram {
+	var ages []int64
+	var maxAge int64
+	for i, es := range stats {
+		if ts.count(&stats[i]) > 0 && !es.Created.IsZero() {
+			age := int64(accountAgeYears(es.Created, ref))
+			ages = append(ages, age)
+			if age > maxAge {
+				maxAge = age
+			}
+		}
+	}
+	hist := histogram.New(0, maxAge, int(maxAge+1))
+	for _, age := range ages {
+		hist.Add(age)
+	}
+	return hist
+}
+
+// getEditTypeCorrelations computes correlation coefficients using method between
+// per-editor counts of each pair of edit types present in stats. The returned types
+// are sorted in ascending order, and coeffs[i][j] contains the coefficient for
+// types[i] and types[j].
+func getEditTypeCorrelations(stats []mbstats.EditorStats, method corrMethod) (
+	types []mbstats.EditType, coeffs [][]float64, err error) {
+	ec := newEditColumns(stats, nil)
+	types = ec.types
+	corr, err := correlator(ec.counts, method)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coeffs = make([][]float64, len(types))
+	for i := range coeffs {
+		coeffs[i] = make([]float64, len(types))
+		coeffs[i][i] = 1
+	}
+	// Each row fills distinct cells, so rows can be computed concurrently.
+	err = forEachParallel(len(types), func(i int) error {
+		for j := 0; j < i; j++ {
+			coeff := corr(i, j)
+			coeffs[i][j], coeffs[j][i] = coeff, coeff
+		}
+		return nil
+	})
+	return types, coeffs, err
+}
+
+// editTypeCorrelationsTable returns a table listing pairs of edit types whose
+// per-editor counts have correlation coefficients with absolute values exceeding
+// threshold.
+func editTypeCorrelationsTable(stats []mbstats.EditorStats, method corrMethod,
+	threshold float64) (*table, error) {
+	types, coeffs, err := getEditTypeCorrelations(stats, method)
+	if err != nil {
+		return nil, err
+	}
+	t := newTable(column{name: "type1", left: true}, column{name: "type2", left: true},
+		column{name: "coeff", format: "%0.3f"})
 	for i := 0; i < len(types); i++ {
 		for j := 0; j < i; j++ {
-			et1, et2 := types[i], types[j]
-			name1, name2 := mbstats.EditTypeName(et1), mbstats.EditTypeName(et2)
-			if coeff, err := gostats.Pearson(edits[et1], edits[et2]); err != nil {
-				return err
-			} else if coeff > 0.5 || coeff < -0.5 {
-				fmt.Fprintf(w, "(%v, %v) = %0.3f\n", name1, name2, coeff)
+			name1, name2 := mbstats.EditTypeName(types[i]), mbstats.EditTypeName(types[j])
+			if coeff := coeffs[i][j]; coeff > threshold || coeff < -threshold {
+				t.add(name1, name2, coeff)
+			}
+		}
+	}
+	// Write pairs like "(TYPE1, TYPE2) = 0.123" in the text format only.
+	t.text = func(w io.Writer) error {
+		pt := &table{rows: t.rows, cols: []column{
+			{format: "(%v,", left: true}, {format: "%v)", left: true}, {format: "= %0.3f"},
+		}}
+		return pt.writeText(w)
+	}
+	return t, nil
+}
+
+// editTypeCorrelationMatrix returns a matrix of correlation coefficients
+// between per-editor counts of all pairs of edit types.
+func editTypeCorrelationMatrix(stats []mbstats.EditorStats, method corrMethod) (*table, error) {
+	types, coeffs, err := getEditTypeCorrelations(stats, method)
+	if err != nil {
+		return nil, err
+	}
+	cols := []column{{name: "type", left: true}}
+	for _, et := range types {
+		cols = append(cols, column{name: mbstats.EditTypeName(et), format: "%0.3f"})
+	}
+	t := newTable(cols...)
+	t.header = true
+	for i, et := range types {
+		row := []interface{}{mbstats.EditTypeName(et)}
+		for _, coeff := range coeffs[i] {
+			row = append(row, coeff)
+		}
+		t.add(row...)
+	}
+	return t, nil
+}
+
+// welchTTest performs Welch's unequal-variances t-test on samples a and b and
+// returns the two-sided p-value for the null hypothesis that their means are equal.
+// NaN is returned if either sample contains fewer than two values.
+func welchTTest(a, b gostats.Float64Data) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return math.NaN()
+	}
+	ma, _ := gostats.Mean(a)
+	mb, _ := gostats.Mean(b)
+	va, _ := gostats.SampleVariance(a)
+	vb, _ := gostats.SampleVariance(b)
+	na, nb := float64(len(a)), float64(len(b))
+
+	sa, sb := va/na, vb/nb
+	if sa+sb == 0 {
+		if ma == mb {
+			return 1
+		}
+		return 0
+	}
+	t := (mb - ma) / math.Sqrt(sa+sb)
+	df := (sa + sb) * (sa + sb) / (sa*sa/(na-1) + sb*sb/(nb-1))
+	return incompleteBeta(df/2, 0.5, df/(df+t*t))
+}
+
+// incompleteBeta returns the regularized incomplete beta function I_x(a, b).
+// See section 6.4 of Numerical Recipes in C.
+func incompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	} else if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	bt := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	// The continued fraction converges quickly for x < (a+1)/(a+b+2).
+	if x < (a+1)/(a+b+2) {
+		return bt * betaContFrac(a, b, x) / a
+	}
+	return 1 - bt*betaContFrac(b, a, 1-x)/b
+}
+
+// betaContFrac evaluates the continued fraction used by incompleteBeta
+// using the modified Lentz method.
+func betaContFrac(a, b, x float64) float64 {
+	const (
+		maxIter = 200
+		eps     = 3e-14
+		tiny    = 1e-300
+	)
+	qab, qap, qam := a+b, a+1, a-1
+	c, d := 1.0, 1-qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIter; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		if d = 1 + aa*d; math.Abs(d) < tiny {
+			d = tiny
+		}
+		if c = 1 + aa/c; math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		if d = 1 + aa*d; math.Abs(d) < tiny {
+			d = tiny
+		}
+		if c = 1 + aa/c; math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return h
+}
+
+// hasEdits returns true if es contains at least one edit of any type.
+func hasEdits(es *mbstats.EditorStats) bool {
+	for _, cnt := range es.Edits {
+		if cnt > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getActiveYears returns the years within yearStats in which each editor was active,
+// as determined by active. Each editor's years are sorted in ascending order.
+func getActiveYears(yearStats []yearEditorStats,
+	active func(es *mbstats.EditorStats) bool) map[mbstats.EditorID][]int {
+	years := make(map[mbstats.EditorID][]int)
+	for _, ys := range yearStats {
+		for i := range ys.stats {
+			if es := &ys.stats[i]; active(es) {
+				years[es.ID] = append(years[es.ID], ys.period.Year)
+			}
+		}
+	}
+	return years
+}
+
+// cohortRetention describes the editors who were first active in a given year.
+type cohortRetention struct {
+	year    int
+	size    int       // number of editors in cohort
+	percent []float64 // percent[i] is percent of cohort active i+1 years later
+}
+
+// getCohortRetention groups editors by the first year in yearStats in which they
+// were active and computes the percentage of each cohort that was still active in
+// each subsequent year through the last year. yearStats must be sorted by ascending
+// year. If some years are missing from yearStats, their percentages are NaN.
+func getCohortRetention(yearStats []yearEditorStats) []cohortRetention {
+	if len(yearStats) == 0 {
+		return nil
+	}
+	maxYear := yearStats[len(yearStats)-1].period.Year
+	loaded := make(map[int]bool, len(yearStats))
+	for _, ys := range yearStats {
+		loaded[ys.period.Year] = true
+	}
+	cohorts := make(map[int]*cohortRetention)
+	counts := make(map[int][]int) // keyed by cohort year
+	for _, years := range getActiveYears(yearStats, hasEdits) {
+		first := years[0]
+		c := cohorts[first]
+		if c == nil {
+			c = &cohortRetention{year: first}
+			cohorts[first] = c
+			counts[first] = make([]int, maxYear-first)
+		}
+		c.size++
+		for _, y := range years[1:] {
+			counts[first][y-first-1]++
+		}
+	}
+
+	res := make([]cohortRetention, 0, len(cohorts))
+	for year, c := range cohorts {
+		c.percent = make([]float64, len(counts[year]))
+		for i, n := range counts[year] {
+			if loaded[year+i+1] {
+				c.percent[i] = 100 * float64(n) / float64(c.size)
+			} else {
+				c.percent[i] = math.NaN()
 			}
 		}
+		res = append(res, *c)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].year < res[j].year })
+	return res
+}
+
+// retentionTable returns a table describing the cohorts returned by
+// getCohortRetention for yearStats, with a column for each number of years
+// after the first year.
+func retentionTable(yearStats []yearEditorStats) *table {
+	cols := []column{{name: "year", left: true}, {name: "size", format: "%5d"}}
+	if len(yearStats) > 0 {
+		for i := 1; i <= yearStats[len(yearStats)-1].period.Year-yearStats[0].period.Year; i++ {
+			cols = append(cols, column{name: fmt.Sprintf("+%d", i), format: "%5.1f"})
+		}
+	}
+	labels := make(map[int]string, len(yearStats))
+	for _, ys := range yearStats {
+		labels[ys.period.Year] = ys.label
+	}
+	t := newTable(cols...)
+	for _, c := range getCohortRetention(yearStats) {
+		row := make([]interface{}, len(cols))
+		row[0], row[1] = labels[c.year], c.size
+		for i, pct := range c.percent {
+			row[2+i] = pct
+		}
+		t.add(row...)
+	}
+	return t
+}
+
+// getYearlyChurn returns the number of editors active in each year in yearStats
+// (which must be sorted by ascending year), the number of the previous year's
+// editors who were never active again, and the number of editors who were active
+// for the first time. The latter two values are NaN for the first year, since
+// earlier activity is unknown.
+func getYearlyChurn(yearStats []yearEditorStats) [][]float64 {
+	res := make([][]float64, len(yearStats))
+	index := make(map[int]int, len(yearStats)) // year to index in yearStats
+	for i, ys := range yearStats {
+		if i == 0 {
+			res[i] = []float64{0, math.NaN(), math.NaN()}
+		} else {
+			res[i] = []float64{0, 0, 0}
+		}
+		index[ys.period.Year] = i
+	}
+	for _, years := range getActiveYears(yearStats, hasEdits) {
+		for _, y := range years {
+			res[index[y]][0]++
+		}
+		if i := index[years[0]]; i > 0 {
+			res[i][2]++
+		}
+		if i, ok := index[years[len(years)-1]+1]; ok {
+			res[i][1]++
+		}
+	}
+	return res
+}
+
+// survivalPoint describes a single point on a survival curve.
+type survivalPoint struct {
+	years    int     // years since editors' first active year
+	editors  int     // editors whose first active year was at least years before the last year
+	fraction float64 // fraction of editors who were active years or more later
+}
+
+// getSurvival groups editors by the first year in yearStats (which must be sorted
+// by ascending year) in which they were active and returns the fraction of editors
+// across all cohorts who were still active 1 to maxYears years later. Cohorts are
+// only included for years that fall within yearStats.
+func getSurvival(yearStats []yearEditorStats, maxYears int) []survivalPoint {
+	if len(yearStats) == 0 {
+		return nil
 	}
-	return nil
+	lastYear := yearStats[len(yearStats)-1].period.Year
+	editors := make([]int, maxYears)
+	surviving := make([]int, maxYears)
+	for _, years := range getActiveYears(yearStats, hasEdits) {
+		first, last := years[0], years[len(years)-1]
+		for i := 0; i < maxYears && first+i+1 <= lastYear; i++ {
+			editors[i]++
+			if last >= first+i+1 {
+				surviving[i]++
+			}
+		}
+	}
+	var points []survivalPoint
+	for i := range editors {
+		if editors[i] == 0 {
+			break
+		}
+		points = append(points, survivalPoint{i + 1, editors[i],
+			float64(surviving[i]) / float64(editors[i])})
+	}
+	return points
+}
+
+// survivalTable returns a table containing points.
+func survivalTable(points []survivalPoint) *table {
+	t := newTable(column{name: "years", format: "%2d"}, column{name: "editors", format: "%6d"},
+		column{name: "fraction", format: "%5.3f"})
+	for _, p := range points {
+		t.add(p.years, p.editors, p.fraction)
+	}
+	return t
+}
+
+// getYearlyComebacks returns the number of editors in each year in yearStats
+// (which must be sorted by ascending year) who returned after not being active
+// for at least gap consecutive years.
+func getYearlyComebacks(yearStats []yearEditorStats, gap int) [][]float64 {
+	res := make([][]float64, len(yearStats))
+	index := make(map[int]int, len(yearStats)) // year to index in yearStats
+	for i, ys := range yearStats {
+		res[i] = []float64{0}
+		index[ys.period.Year] = i
+	}
+	for _, years := range getActiveYears(yearStats, hasEdits) {
+		for i := 1; i < len(years); i++ {
+			if years[i]-years[i-1]-1 >= gap {
+				res[index[years[i]]][0]++
+			}
+		}
+	}
+	return res
+}
+
+// getYearlyDormant returns the number of editors with edits in each year in yearStats
+// whose last login was at least 1, 2, and 5 years before the time at which the dump
+// was created. If the dump time wasn't recorded, the most recent last login across
+// all of yearStats is used to approximate it.
+func getYearlyDormant(yearStats []yearEditorStats) [][]float64 {
+	var ref time.Time
+	if len(yearStats) > 0 {
+		ref = yearStats[0].dumpTime
+	}
+	if ref.IsZero() {
+		for _, ys := range yearStats {
+			for _, es := range ys.stats {
+				if es.Active.After(ref) {
+					ref = es.Active
+				}
+			}
+		}
+	}
+	cutoffs := []time.Time{ref.AddDate(-1, 0, 0), ref.AddDate(-2, 0, 0), ref.AddDate(-5, 0, 0)}
+	res := make([][]float64, len(yearStats))
+	for i, ys := range yearStats {
+		res[i] = make([]float64, len(cutoffs))
+		for j := range ys.stats {
+			es := &ys.stats[j]
+			if !hasEdits(es) || es.Active.IsZero() {
+				continue
+			}
+			for k, c := range cutoffs {
+				if !es.Active.After(c) {
+					res[i][k]++
+				}
+			}
+		}
+	}
+	return res
+}
+
+// getNewcomerSplit returns the percentages of edits with types in ts in ys that
+// were made by editors whose accounts were created during the year and by editors
+// with older accounts. Editors with unknown creation times are excluded.
+func getNewcomerSplit(ys *yearEditorStats, ts typeSet) []float64 {
+	var newEdits, oldEdits int
+	for i := range ys.stats {
+		es := &ys.stats[i]
+		if es.Created.IsZero() {
+			continue
+		}
+		if es.Created.Before(ys.start) {
+			oldEdits += ts.count(es)
+		} else {
+			newEdits += ts.count(es)
+		}
+	}
+	total := newEdits + oldEdits
+	if total == 0 {
+		return []float64{math.NaN(), math.NaN()}
+	}
+	return []float64{100 * float64(newEdits) / float64(total), 100 * float64(oldEdits) / float64(total)}
+}
+
+// monthSeason describes the typical edit volume for a calendar month.
+type monthSeason struct {
+	month time.Month
+	mean  float64 // mean edits in the month across years
+	rel   float64 // percent difference between mean and mean of all months
+	above int     // number of complete years in which month was above the year's monthly mean
+	years int     // number of complete years (i.e. with stats for all 12 months)
+}
+
+// getSeasonality computes the typical volume of edits with types in ts for each
+// calendar month using monthStats as returned by readAllMonthlyEditorStats.
+func getSeasonality(monthStats []yearEditorStats, ts typeSet) []monthSeason {
+	sums := make([]float64, 12)
+	counts := make([]int, 12)
+	years := make(map[int][]float64) // edits for each month, keyed by calendar year
+	for _, ms := range monthStats {
+		edits := float64(countEdits(ms.stats, ts))
+		mi := int(ms.start.Month()) - 1
+		sums[mi] += edits
+		counts[mi]++
+		years[ms.start.Year()] = append(years[ms.start.Year()], edits)
+	}
+
+	res := make([]monthSeason, 12)
+	var total float64
+	var nmonths int
+	for i := range res {
+		res[i].month = time.Month(i + 1)
+		res[i].mean = math.NaN()
+		if counts[i] > 0 {
+			res[i].mean = sums[i] / float64(counts[i])
+			total += res[i].mean
+			nmonths++
+		}
+	}
+	overall := total / float64(nmonths)
+	for i := range res {
+		res[i].rel = 100 * (res[i].mean - overall) / overall
+	}
+
+	for _, ms := range monthStats {
+		vals := years[ms.start.Year()]
+		if len(vals) != 12 {
+			continue
+		}
+		mean, _ := gostats.Mean(vals)
+		mi := int(ms.start.Month()) - 1
+		res[mi].years++
+		if float64(countEdits(ms.stats, ts)) > mean {
+			res[mi].above++
+		}
+	}
+	return res
+}
+
+// weekdaysTable returns a table containing the percentage of each year's edits that
+// were made on each day of the week (starting with Sunday) and on weekends. days is
+// as returned by readWeekdays, and years outside of [minYear, maxYear] are skipped.
+func weekdaysTable(days map[int][]int32, md *mbstats.Metadata, minYear, maxYear int) *table {
+	years := make([]int, 0, len(days))
+	for year := range days {
+		if year >= minYear && year <= maxYear {
+			years = append(years, year)
+		}
+	}
+	sort.Ints(years)
+
+	cols := []column{{name: "year", left: true}}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		cols = append(cols, column{name: strings.ToLower(d.String()[:3]), format: "%5.1f"})
+	}
+	cols = append(cols, column{name: "weekend", format: "%5.1f"})
+	t := newTable(cols...)
+	t.header = true
+
+	for _, year := range years {
+		var total float64
+		for _, n := range days[year] {
+			total += float64(n)
+		}
+		if total == 0 {
+			continue
+		}
+		pct := func(n int32) float64 { return 100 * float64(n) / total }
+		row := []interface{}{md.YearLabel(year)}
+		for _, n := range days[year] {
+			row = append(row, pct(n))
+		}
+		row = append(row, pct(days[year][time.Saturday]+days[year][time.Sunday]))
+		t.add(row...)
+	}
+	return t
+}
+
+// getBotSplit returns the total edits made by human and bot editors in stats,
+// followed by the number of human and bot editors.
+func getBotSplit(stats []mbstats.EditorStats) []float64 {
+	vals := make([]float64, 4)
+	for i := range stats {
+		es := &stats[i]
+		idx := 0
+		if es.Bot {
+			idx = 1
+		}
+		vals[idx] += float64(totalEdits(es))
+		vals[idx+2]++
+	}
+	return vals
+}
+
+// getVoterSummary returns the number of editors in ys who cast votes, the total
+// number of votes, and the number of votes per 100 edits. NaN is returned for all values
+// if ys doesn't contain voter stats.
+func getVoterSummary(ys *yearEditorStats) []float64 {
+	if ys.voters == nil {
+		return []float64{math.NaN(), math.NaN(), math.NaN()}
+	}
+	var voters, votes int
+	for i := range ys.voters {
+		if n := ys.voters[i].Total(); n > 0 {
+			voters++
+			votes += n
+		}
+	}
+	ratio := math.NaN()
+	if edits := countEdits(ys.stats, nil); edits > 0 {
+		ratio = 100 * float64(votes) / float64(edits)
+	}
+	return []float64{float64(voters), float64(votes), ratio}
+}
+
+// getNoteSummary returns the number of editors in ys who wrote edit notes, the
+// total number of notes, and the number of notes per 100 edits. NaN is returned
+// for all values if ys doesn't contain note stats.
+func getNoteSummary(ys *yearEditorStats) []float64 {
+	if ys.notes == nil {
+		return []float64{math.NaN(), math.NaN(), math.NaN()}
+	}
+	var writers, notes int
+	for _, ns := range ys.notes {
+		if ns.Notes > 0 {
+			writers++
+			notes += int(ns.Notes)
+		}
+	}
+	ratio := math.NaN()
+	if edits := countEdits(ys.stats, nil); edits > 0 {
+		ratio = 100 * float64(notes) / float64(edits)
+	}
+	return []float64{float64(writers), float64(notes), ratio}
+}
+
+// noteHistogram returns a histogram of per-editor edit note counts.
+func noteHistogram(notes []mbstats.NoteStats, hs *histSpec) *histogram.Histogram {
+	var vals []int64
+	for _, ns := range notes {
+		if ns.Notes > 0 {
+			vals = append(vals, int64(ns.Notes))
+		}
+	}
+	return hs.histogram(vals, nil)
+}
+
+// voterListTable returns a table containing the votes and edits of each editor in
+// voters, sorted by descending votes. Edits are looked up in stats.
+func voterListTable(voters []mbstats.VoterStats, stats []mbstats.EditorStats) *table {
+	edits := make(map[mbstats.EditorID]int, len(stats))
+	for i := range stats {
+		edits[stats[i].ID] = totalEdits(&stats[i])
+	}
+	voters = append([]mbstats.VoterStats(nil), voters...)
+	sort.SliceStable(voters, func(i, j int) bool { return voters[i].Total() > voters[j].Total() })
+
+	t := newTable(column{name: "votes", format: "%5d"}, column{name: "edits", format: "%5d"},
+		column{name: "ratio", format: "%6.2f"}, column{name: "id", format: "%d"},
+		column{name: "name", left: true})
+	for i := range voters {
+		vs := &voters[i]
+		votes, ne := vs.Total(), edits[vs.ID]
+		ratio := math.NaN()
+		if ne > 0 {
+			ratio = float64(votes) / float64(ne)
+		}
+		t.add(votes, ne, ratio, vs.ID, vs.Name)
+	}
+	return t
+}
+
+// editorCount holds an editor's count of edits of some types.
+type editorCount struct {
+	es    *mbstats.EditorStats
+	count int
+}
+
+// getEditorCounts returns the counts of edits with types in ts for editors
+// matched by preds (see editorFilters).
+func getEditorCounts(stats []mbstats.EditorStats, ts typeSet, preds []mbstats.EditorPredicate) []editorCount {
+	matched := mbstats.FilterEditors(stats, preds...)
+	ecs := make([]editorCount, len(matched))
+	for i := range matched {
+		ecs[i] = editorCount{&matched[i], ts.count(&matched[i])}
+	}
+	return ecs
+}
+
+// editorOrder describes how editorCounts are sorted.
+type editorOrder string
+
+const (
+	orderCount   editorOrder = "count"   // descending count
+	orderName    editorOrder = "name"    // ascending name, ignoring case
+	orderCreated editorOrder = "created" // ascending account creation time
+)
+
+// parseEditorOrder parses an editorOrder from s.
+func parseEditorOrder(s string) (editorOrder, error) {
+	switch o := editorOrder(s); o {
+	case orderCount, orderName, orderCreated:
+		return o, nil
+	default:
+		return "", fmt.Errorf("unknown order %q", s)
+	}
+}
+
+// sortEditorCounts sorts ecs by order, reversing the order if reverse is true.
+// Ties are broken by ascending editor ID.
+func sortEditorCounts(ecs []editorCount, order editorOrder, reverse bool) {
+	less := func(a, b *editorCount) bool {
+		switch order {
+		case orderCount:
+			if a.count != b.count {
+				return a.count > b.count
+			}
+		case orderName:
+			if an, bn := strings.ToLower(a.es.Name), strings.ToLower(b.es.Name); an != bn {
+				return an < bn
+			}
+		case orderCreated:
+			if !a.es.Created.Equal(b.es.Created) {
+				return a.es.Created.Before(b.es.Created)
+			}
+		}
+		return a.es.ID < b.es.ID
+	}
+	sort.Slice(ecs, func(i, j int) bool {
+		if reverse {
+			return less(&ecs[j], &ecs[i])
+		}
+		return less(&ecs[i], &ecs[j])
+	})
+}
+
+// totalEdits returns the total number of edits of all types in es.
+func totalEdits(es *mbstats.EditorStats) int {
+	var total int
+	for _, cnt := range es.Edits {
+		total += int(cnt)
+	}
+	return total
+}
+
+// getYearlyNewEditors returns the number of new editors with at least minEdits
+// (minimum 1) edits of any type in each year in yearStats (which must be sorted by
+// ascending year). If byCreated is true, editors are new in the year in which their
+// accounts were created. Otherwise, editors are new in the first year in which they
+// made an edit, and NaN is returned for the first year since earlier activity is unknown.
+func getYearlyNewEditors(yearStats []yearEditorStats, byCreated bool, minEdits int) [][]float64 {
+	res := make([][]float64, len(yearStats))
+	seen := make(map[mbstats.EditorID]bool)
+	for i, ys := range yearStats {
+		preds := editorFilters(nil, minEdits, false)
+		if byCreated {
+			preds = append(preds, mbstats.Not(mbstats.CreatedBefore(ys.start)),
+				mbstats.CreatedBefore(ys.end))
+		}
+		var cnt int
+		for _, es := range mbstats.FilterEditors(ys.stats, preds...) {
+			if byCreated || !seen[es.ID] {
+				cnt++
+			}
+			seen[es.ID] = true
+		}
+		for _, es := range ys.stats {
+			seen[es.ID] = true
+		}
+		res[i] = []float64{float64(cnt)}
+		if !byCreated && i == 0 {
+			res[i][0] = math.NaN()
+		}
+	}
+	return res
+}
+
+// getLifespans returns the number of years between each editor's first and last
+// active years in yearStats.
+func getLifespans(yearStats []yearEditorStats) []int {
+	active := getActiveYears(yearStats, hasEdits)
+	spans := make([]int, 0, len(active))
+	for _, years := range active {
+		spans = append(spans, years[len(years)-1]-years[0])
+	}
+	return spans
+}
+
+// lifespanTables returns a summary and histogram of editor lifespans in yearStats.
+// No tables are returned if there are no editors.
+func lifespanTables(yearStats []yearEditorStats) []*table {
+	spans := getLifespans(yearStats)
+	if len(spans) == 0 {
+		return nil
+	}
+	var data gostats.Float64Data
+	var max int
+	for _, s := range spans {
+		data = append(data, float64(s))
+		if s > max {
+			max = s
+		}
+	}
+	median, _ := gostats.Median(data)
+	mean, _ := gostats.Mean(data)
+	t := newTable(column{name: "editors", format: "%d editors,"},
+		column{name: "median", format: "median %0.1f years,"}, column{name: "mean", format: "mean %0.1f years"})
+	t.add(len(spans), median, mean)
+
+	hist := histogram.New(0, int64(max), max+1)
+	for _, s := range spans {
+		hist.Add(int64(s))
+	}
+	return []*table{t, histTable(hist)}
+}
+
+// getEditCounts returns the per-editor counts of edits with types in ts,
+// omitting editors without any such edits.
+func getEditCounts(stats []mbstats.EditorStats, ts typeSet) gostats.Float64Data {
+	return newEditColumns(stats, ts).totals()
+}
+
+// getSummary returns the mean, median, population standard deviation, and maximum
+// of counts. NaN is returned for each value if counts is empty.
+func getSummary(counts gostats.Float64Data) []float64 {
+	if len(counts) == 0 {
+		return []float64{math.NaN(), math.NaN(), math.NaN(), math.NaN()}
+	}
+	mean, _ := gostats.Mean(counts)
+	median, _ := gostats.Median(counts)
+	stddev, _ := gostats.StandardDeviation(counts)
+	max, _ := gostats.Max(counts)
+	return []float64{mean, median, stddev, max}
+}
+
+// getPercentiles returns the nearest-rank percentiles pcts (in (0, 100]) of counts.
+// NaN is returned for each percentile if counts is empty.
+func getPercentiles(counts gostats.Float64Data, pcts []float64) []float64 {
+	vals := make([]float64, len(pcts))
+	for i, pct := range pcts {
+		var err error
+		if vals[i], err = gostats.PercentileNearestRank(counts, pct); err != nil {
+			vals[i] = math.NaN()
+		}
+	}
+	return vals
+}
+
+// getGini returns the Gini coefficient of counts, ranging from 0 when all counts
+// are equal to nearly 1 when a single count dominates. NaN is returned if counts
+// is empty or sums to zero.
+func getGini(counts gostats.Float64Data) float64 {
+	sorted := make([]float64, len(counts))
+	copy(sorted, counts)
+	sort.Float64s(sorted)
+	var sum, weighted float64
+	for i, v := range sorted {
+		sum += v
+		weighted += float64(i+1) * v
+	}
+	if sum == 0 {
+		return math.NaN()
+	}
+	n := float64(len(sorted))
+	return 2*weighted/(n*sum) - (n+1)/n
+}
+
+// getLorenz returns npoints+1 points on the Lorenz curve of counts. Each point
+// contains the cumulative percentage of editors (sorted by ascending count) and
+// the cumulative percentage of edits made by them.
+func getLorenz(counts gostats.Float64Data, npoints int) [][2]float64 {
+	sorted := make([]float64, len(counts))
+	copy(sorted, counts)
+	sort.Float64s(sorted)
+	cum := make([]float64, len(sorted)+1) // cum[i] is sum of first i counts
+	for i, v := range sorted {
+		cum[i+1] = cum[i] + v
+	}
+	total := cum[len(cum)-1]
+	if total == 0 || npoints < 1 {
+		return nil
+	}
+	points := make([][2]float64, npoints+1)
+	for k := range points {
+		i := int(math.Round(float64(k) / float64(npoints) * float64(len(sorted))))
+		points[k] = [2]float64{100 * float64(i) / float64(len(sorted)), 100 * cum[i] / total}
+	}
+	return points
+}
+
+// getTopShares returns the percentage of the total of counts contributed by the
+// top pcts percent of editors. The number of top editors is rounded up, so at
+// least one editor is always included. NaN is returned for each percentage if
+// counts is empty or sums to zero.
+func getTopShares(counts gostats.Float64Data, pcts []float64) []float64 {
+	sorted := make([]float64, len(counts))
+	copy(sorted, counts)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+	total, _ := gostats.Sum(sorted)
+
+	shares := make([]float64, len(pcts))
+	for i, pct := range pcts {
+		if total == 0 {
+			shares[i] = math.NaN()
+			continue
+		}
+		n := int(math.Ceil(pct / 100 * float64(len(sorted))))
+		top, _ := gostats.Sum(sorted[:n])
+		shares[i] = 100 * top / total
+	}
+	return shares
+}
+
+// getYearlyGrowth returns the percent change in edits with types in ts
+// and in editors making those edits between each year in yearStats
+// (which must be sorted by ascending year) and the previous year. NaN is returned
+// if the previous year is missing or had no edits.
+func getYearlyGrowth(yearStats []yearEditorStats, ts typeSet) [][]float64 {
+	change := func(prev, cur float64) float64 {
+		if prev == 0 {
+			return math.NaN()
+		}
+		return 100 * (cur - prev) / prev
+	}
+	res := make([][]float64, len(yearStats))
+	var prevYear int
+	var prevEdits, prevEditors float64
+	for i, ys := range yearStats {
+		counts := getEditCounts(ys.stats, ts)
+		edits, _ := gostats.Sum(counts)
+		editors := float64(len(counts))
+		if i > 0 && ys.period.Year == prevYear+1 {
+			res[i] = []float64{change(prevEdits, edits), change(prevEditors, editors)}
+		} else {
+			res[i] = []float64{math.NaN(), math.NaN()}
+		}
+		prevYear, prevEdits, prevEditors = ys.period.Year, edits, editors
+	}
+	return res
+}
+
+// getYearlyCumulative returns the running total of edits with types in ts
+// through each year in yearStats.
+func getYearlyCumulative(yearStats []yearEditorStats, ts typeSet) [][]float64 {
+	res := make([][]float64, len(yearStats))
+	var total int
+	for i, ys := range yearStats {
+		total += countEdits(ys.stats, ts)
+		res[i] = []float64{float64(total)}
+	}
+	return res
+}
+
+// typeTrend describes the trend in yearly counts of an edit type.
+type typeTrend struct {
+	et    mbstats.EditType
+	slope float64 // change in edits per year from linear regression
+	mean  float64 // mean edits per year
+}
+
+// getTypeTrends fits a line to the yearly counts of each edit type in yearStats
+// and returns the resulting trends sorted by descending slope.
+func getTypeTrends(yearStats []yearEditorStats) []typeTrend {
+	yearCounts := make([]map[mbstats.EditType]int, len(yearStats))
+	types := make(map[mbstats.EditType]struct{})
+	for i, ys := range yearStats {
+		yearCounts[i] = countEditTypes(ys.stats)
+		for et := range yearCounts[i] {
+			types[et] = struct{}{}
+		}
+	}
+
+	trends := make([]typeTrend, 0, len(types))
+	for et := range types {
+		var sx, sy, sxx, sxy float64
+		for i, ys := range yearStats {
+			x, y := float64(ys.period.Year), float64(yearCounts[i][et])
+			sx += x
+			sy += y
+			sxx += x * x
+			sxy += x * y
+		}
+		n := float64(len(yearStats))
+		t := typeTrend{et: et, mean: sy / n}
+		if d := n*sxx - sx*sx; d != 0 {
+			t.slope = (n*sxy - sx*sy) / d
+		}
+		trends = append(trends, t)
+	}
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].slope != trends[j].slope {
+			return trends[i].slope > trends[j].slope
+		}
+		return trends[i].et < trends[j].et
+	})
+	return trends
 }
@@ -0,0 +1,149 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/derat/mbstats"
+	gostats "github.com/montanaflynn/stats"
+)
+
+func TestWelchTTest(t *testing.T) {
+	// Reference p-values were computed independently by numerically integrating
+	// the Student's t density (with the Welch-Satterthwaite degrees of freedom)
+	// to a tolerance of 1e-15, which reproduces closed-form values for 1 and 2
+	// degrees of freedom. The first case is the example from Wikipedia's
+	// "Welch's t-test" article (t = -2.46, df = 25.0, p = 0.021).
+	const eps = 1e-9
+	for _, tc := range []struct {
+		desc string
+		a, b gostats.Float64Data
+		want float64
+	}{
+		{"wikipedia", gostats.Float64Data{27.5, 21.0, 19.0, 23.6, 17.0, 17.9, 16.9, 20.1, 21.9, 22.6, 23.1, 19.6, 19.0, 21.7, 21.4},
+			gostats.Float64Data{27.1, 22.0, 20.8, 23.4, 23.4, 23.5, 25.8, 22.0, 24.8, 20.2, 21.9, 22.1, 22.9, 20.5, 24.4},
+			0.021378001462870833},
+		{"ties", gostats.Float64Data{0, 0, 0, 1, 1, 2, 5}, gostats.Float64Data{1, 1, 2, 2, 2, 3, 3, 3, 8}, 0.14963025403489538},
+		{"small", gostats.Float64Data{1, 2}, gostats.Float64Data{3, 5}, 0.1987273889345259},
+		{"one zero variance", gostats.Float64Data{4, 4, 4, 4}, gostats.Float64Data{1, 3, 5, 6, 9}, 0.5870496397870562},
+		{"unequal variances", gostats.Float64Data{10, 12, 9, 11, 10, 13, 50}, gostats.Float64Data{1, 2, 1, 3}, 0.03995708066626946},
+		{"close means", gostats.Float64Data{5, 6, 7, 8}, gostats.Float64Data{5.1, 6.2, 6.9, 8.05}, 0.946557404104756},
+		{"identical", gostats.Float64Data{1, 2, 3}, gostats.Float64Data{3, 2, 1}, 1},
+		{"both zero variance, equal", gostats.Float64Data{2, 2}, gostats.Float64Data{2, 2, 2}, 1},
+		{"both zero variance, different", gostats.Float64Data{2, 2}, gostats.Float64Data{3, 3, 3}, 0},
+	} {
+		got := welchTTest(tc.a, tc.b)
+		if math.Abs(got-tc.want) > eps {
+			t.Errorf("%v: welchTTest(%v, %v) = %v; want %v", tc.desc, tc.a, tc.b, got, tc.want)
+		}
+		// The test is symmetric.
+		if rev := welchTTest(tc.b, tc.a); math.Abs(rev-got) > eps {
+			t.Errorf("%v: welchTTest(%v, %v) = %v; want %v", tc.desc, tc.b, tc.a, rev, got)
+		}
+	}
+
+	for _, tc := range [][2]gostats.Float64Data{
+		{{1}, {1, 2, 3}},
+		{{1, 2, 3}, {}},
+		{nil, nil},
+	} {
+		if got := welchTTest(tc[0], tc[1]); !math.IsNaN(got) {
+			t.Errorf("welchTTest(%v, %v) = %v; want NaN", tc[0], tc[1], got)
+		}
+	}
+}
+
+func TestIncompleteBeta(t *testing.T) {
+	const eps = 1e-12
+	for _, tc := range []struct {
+		a, b, x float64
+		want    float64
+	}{
+		// Closed forms: I_x(1, 1) = x, I_x(a, 1) = x^a, I_x(1, b) = 1 - (1-x)^b,
+		// and I_x(1/2, 1/2) = 2/pi * asin(sqrt(x)).
+		{1, 1, 0.3, 0.3},
+		{3, 1, 0.4, math.Pow(0.4, 3)},
+		{2.5, 1, 0.9, math.Pow(0.9, 2.5)},
+		{1, 4, 0.2, 1 - math.Pow(0.8, 4)},
+		{1, 7.5, 0.95, 1 - math.Pow(0.05, 7.5)},
+		{0.5, 0.5, 0.25, 2 / math.Pi * math.Asin(0.5)},
+		{0.5, 0.5, 0.9, 2 / math.Pi * math.Asin(math.Sqrt(0.9))},
+		// Student's t with 1 and 2 degrees of freedom: I_{df/(df+t^2)}(df/2, 1/2) is
+		// the two-sided p-value 1 - 2/pi*atan(t) or 1 - t/sqrt(2+t^2), respectively.
+		{0.5, 0.5, 1 / (1 + 1.5*1.5), 1 - 2/math.Pi*math.Atan(1.5)},
+		{1, 0.5, 2 / (2 + 3.0*3.0), 1 - 3/math.Sqrt(2+3.0*3.0)},
+		// Out-of-range x values are clamped.
+		{2, 3, 0, 0},
+		{2, 3, -1, 0},
+		{2, 3, 1, 1},
+		{2, 3, 1.5, 1},
+	} {
+		if got := incompleteBeta(tc.a, tc.b, tc.x); math.Abs(got-tc.want) > eps {
+			t.Errorf("incompleteBeta(%v, %v, %v) = %v; want %v", tc.a, tc.b, tc.x, got, tc.want)
+		}
+	}
+
+	// I_x(a, b) = 1 - I_{1-x}(b, a), which exercises both of the continued
+	// fraction's branches.
+	for _, tc := range [][3]float64{{2, 3, 0.1}, {2, 3, 0.7}, {12.5, 0.5, 0.8}, {30, 40, 0.45}} {
+		a, b, x := tc[0], tc[1], tc[2]
+		if got, want := incompleteBeta(a, b, x), 1-incompleteBeta(b, a, 1-x); math.Abs(got-want) > eps {
+			t.Errorf("incompleteBeta(%v, %v, %v) = %v; want %v", a, b, x, got, want)
+		}
+	}
+}
+
+func TestBetaContFrac(t *testing.T) {
+	// With b = 1, I_x(a, 1) = x^a, so the continued fraction must equal
+	// a * x^a / bt, where bt = x^a * (1-x) * Gamma(a+1) / Gamma(a) = a * x^a * (1-x).
+	const eps = 1e-12
+	for _, tc := range [][2]float64{{1, 0.2}, {2, 0.5}, {3.5, 0.6}} {
+		a, x := tc[0], tc[1]
+		if got, want := betaContFrac(a, 1, x), 1/(1-x); math.Abs(got-want) > eps {
+			t.Errorf("betaContFrac(%v, 1, %v) = %v; want %v", a, x, got, want)
+		}
+	}
+}
+
+func TestRetentionTable_GapYear(t *testing.T) {
+	// 2017 is missing.
+	edits := map[mbstats.EditType]int32{mbstats.EDIT_ARTIST_CREATE: 1}
+	var yearStats []yearEditorStats
+	for _, ys := range []struct {
+		year int
+		ids  []mbstats.EditorID
+	}{
+		{2015, []mbstats.EditorID{1, 2}},
+		{2016, []mbstats.EditorID{1, 3}},
+		{2018, []mbstats.EditorID{1, 3}},
+	} {
+		var stats []mbstats.EditorStats
+		for _, id := range ys.ids {
+			stats = append(stats, mbstats.EditorStats{ID: id, Edits: edits})
+		}
+		yearStats = append(yearStats, yearEditorStats{period: mbstats.YearPeriod(ys.year),
+			label: strconv.Itoa(ys.year), stats: stats})
+	}
+
+	tbl := retentionTable(yearStats)
+	var cols []string
+	for _, c := range tbl.cols {
+		cols = append(cols, c.name)
+	}
+	if got, want := strings.Join(cols, " "), "year size +1 +2 +3"; got != want {
+		t.Errorf("retentionTable returned columns %q; want %q", got, want)
+	}
+	var rows []string
+	for _, row := range tbl.rows {
+		rows = append(rows, fmt.Sprint(row))
+	}
+	if got, want := strings.Join(rows, "\n"), "[2015 2 50 NaN 50]\n[2016 1 NaN 100 <nil>]"; got != want {
+		t.Errorf("retentionTable returned rows:\n%v\nwant:\n%v", got, want)
+	}
+}
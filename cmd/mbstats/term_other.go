@@ -0,0 +1,12 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+//go:build !linux && !darwin
+
+package main
+
+import "os"
+
+// terminalWidth reports that f isn't a terminal, since terminal detection
+// isn't implemented on this platform.
+func terminalWidth(f *os.File) (width int, ok bool) { return 0, false }
@@ -0,0 +1,23 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+//go:build linux || darwin
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// terminalWidth returns the width in columns of the terminal attached to f.
+// ok is false if f isn't a terminal. width may be 0 if the terminal's size is unknown.
+func terminalWidth(f *os.File) (width int, ok bool) {
+	var ws struct{ row, col, xpixel, ypixel uint16 }
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(),
+		uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws))); errno != 0 {
+		return 0, false
+	}
+	return int(ws.col), true
+}
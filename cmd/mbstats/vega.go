@@ -0,0 +1,70 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const vegaLiteSchema = "https://vega.github.io/schema/vega-lite/v5.json"
+
+// writeVegaLite writes a Vega-Lite spec to w that charts t with type ct.
+// t is interpreted as described by writeSVGChart, and its data is inlined in the spec.
+func writeVegaLite(w io.Writer, t *table, ct chartType) error {
+	if len(t.cols) < 2 || len(t.rows) == 0 {
+		return fmt.Errorf("no data to chart")
+	}
+
+	// Use a "long" layout with a row for each value so that series can be
+	// distinguished by color.
+	xField := t.cols[0].name
+	const seriesField, valueField = "series", "value"
+	values := make([]map[string]interface{}, 0, len(t.rows)*(len(t.cols)-1))
+	for _, row := range t.rows {
+		for i, v := range row[1:] {
+			if f, ok := chartValue(v); ok {
+				values = append(values, map[string]interface{}{
+					xField:      fmt.Sprint(row[0]),
+					seriesField: t.cols[i+1].name,
+					valueField:  f,
+				})
+			}
+		}
+	}
+
+	enc := map[string]interface{}{
+		// Preserve the order of rows instead of sorting labels.
+		"x": map[string]interface{}{"field": xField, "type": "ordinal", "sort": nil},
+		"y": map[string]interface{}{"field": valueField, "type": "quantitative"},
+	}
+	if len(t.cols) > 2 {
+		enc["color"] = map[string]interface{}{"field": seriesField, "type": "nominal", "sort": nil}
+	} else {
+		enc["y"].(map[string]interface{})["title"] = t.cols[1].name
+	}
+	var mark interface{}
+	switch ct {
+	case barChart:
+		mark = "bar"
+		if len(t.cols) > 2 {
+			enc["xOffset"] = map[string]interface{}{"field": seriesField, "sort": nil}
+		}
+	default:
+		mark = map[string]interface{}{"type": "line", "point": true}
+	}
+
+	je := json.NewEncoder(w)
+	je.SetEscapeHTML(false) // don't mangle labels like ">100"
+	je.SetIndent("", "  ")
+	return je.Encode(map[string]interface{}{
+		"$schema":  vegaLiteSchema,
+		"width":    chartWidth,
+		"height":   chartHeight,
+		"data":     map[string]interface{}{"values": values},
+		"mark":     mark,
+		"encoding": enc,
+	})
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/derat/mbstats"
+)
+
+// yearlyFunc computes one or more values from a single year's stats.
+type yearlyFunc func(ys *yearEditorStats) []float64
+
+// yearlyTable returns a table with a row for each year present in dirStats, which
+// contains stats read from each of dirs by readAllEditorStats. fn is called to
+// compute the value(s) for a year's stats from a single directory, and names
+// contains the name of each value. Values are formatted using format (e.g. "%5.0f")
+// in text output, and the results for each directory are placed in adjacent columns.
+// Years that are missing from a directory are treated as missing values.
+func yearlyTable(dirs []string, dirStats []*mbstats.Series[yearEditorStats], format string,
+	names []string, fn yearlyFunc) *table {
+	cols := []column{{name: "year", left: true}}
+	for _, dir := range dirs {
+		for _, name := range names {
+			if len(dirs) > 1 {
+				name = dir + ":" + name
+			}
+			cols = append(cols, column{name: name, format: format})
+		}
+	}
+	t := newTable(cols...)
+
+	var rows mbstats.Series[[]interface{}]
+	for i, all := range dirStats {
+		years := all.Values()
+		for j := range years {
+			ys := &years[j]
+			row, ok := rows.Get(ys.period)
+			if !ok {
+				row = make([]interface{}, len(cols))
+				row[0] = ys.label
+				rows.Set(ys.period, row)
+			}
+			for k, v := range fn(ys) {
+				row[1+i*len(names)+k] = v
+			}
+		}
+	}
+	for _, row := range rows.Values() {
+		t.add(row...)
+	}
+	return t
+}
+
+// concatYearly returns a yearlyFunc that returns the concatenated values of fns.
+func concatYearly(fns []yearlyFunc) yearlyFunc {
+	return func(ys *yearEditorStats) []float64 {
+		var vals []float64
+		for _, fn := range fns {
+			vals = append(vals, fn(ys)...)
+		}
+		return vals
+	}
+}
+
+// crossYearly returns a yearlyFunc for actions that need to look at multiple years'
+// stats at once. fn is called with each directory's stats from dirStats and must
+// return the values for each year, in the same order.
+func crossYearly(dirStats []*mbstats.Series[yearEditorStats],
+	fn func(all []yearEditorStats) [][]float64) yearlyFunc {
+	vals := make(map[*yearEditorStats][]float64)
+	for _, s := range dirStats {
+		all := s.Values()
+		res := fn(all)
+		for i := range all {
+			vals[&all[i]] = res[i]
+		}
+	}
+	return func(ys *yearEditorStats) []float64 { return vals[ys] }
+}
+
+// checkYearLabels returns an error if dirStats, containing stats read from
+// multiple directories, use different labels (and thus boundaries) for the same year.
+func checkYearLabels(dirStats []*mbstats.Series[yearEditorStats]) error {
+	labels := make(map[mbstats.Period]string)
+	for _, all := range dirStats {
+		for _, ys := range all.Values() {
+			if l, ok := labels[ys.period]; ok && l != ys.label {
+				return fmt.Errorf("year %v labeled as both %q and %q", ys.period, l, ys.label)
+			}
+			labels[ys.period] = ys.label
+		}
+	}
+	return nil
+}
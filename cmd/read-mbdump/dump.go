@@ -4,124 +4,54 @@
 package main
 
 import (
-	"archive/tar"
-	"bufio"
-	"compress/bzip2"
-	"fmt"
+	"context"
 	"io"
-	"log"
-	"os"
-	"strconv"
-	"strings"
 	"time"
-)
 
-const (
-	logFreq    = 5 * time.Second
-	mb         = 1024 * 1024
-	timeLayout = "2006-01-02 15:04:05.999-07" // time format in PostgreSQL dumps
-	emptyCol   = `\N`                         // empty column value in PostgreSQL dumps
+	"github.com/derat/mbstats/internal/logutil"
+	"github.com/derat/mbstats/mbdump"
 )
 
-// readArchive opens a .tar.bz2 file at path p and reads the named file within it.
-// fn is invoked with each line from the file.
-func readArchive(p, name string, fn func(*lineParser)) error {
-	f, err := os.Open(p)
+// openArchive opens the .tar.bz2 file at path p and configures it to log messages.
+func openArchive(p string) (*mbdump.Archive, error) {
+	ar, err := mbdump.Open(p)
 	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	tr := tar.NewReader(bzip2.NewReader(f))
-	var size int64
-	for {
-		head, err := tr.Next()
-		if err != nil {
-			f.Close()
-			if err == io.EOF {
-				return fmt.Errorf("file %q not found in archive", name)
-			}
-			return err
-		}
-		if head.Name == name {
-			size = head.Size
-			break
-		}
+		return nil, err
 	}
-
-	log.Printf("Processing %v (%0.1f MB)", name, float64(size)/mb)
-	logTime := time.Now()
-
-	r := &countReader{r: tr}
-	sc := bufio.NewScanner(r)
-	var nrows int
-	for sc.Scan() {
-		p := newLineParser(sc.Text())
-		fn(p)
-		if p.err != nil {
-			return fmt.Errorf("bad row %q: %v", sc.Text(), p.err)
-		}
-
-		nrows++
-		if now := time.Now(); now.Sub(logTime) > logFreq {
-			log.Printf("Read %4.1f%% (%d rows, %0.1f MB)",
-				float64(r.nbytes)/float64(size)*100,
-				nrows, float64(r.nbytes)/mb)
-			logTime = now
-		}
-	}
-	return nil
-}
-
-// countReader wraps an io.Reader and counts the number of bytes that have been read.
-type countReader struct {
-	r      io.Reader
-	nbytes int
-}
-
-func (cr *countReader) Read(p []byte) (int, error) {
-	n, err := cr.r.Read(p)
-	cr.nbytes += n
-	return n, err
-}
-
-// lineParser extracts tab-separated values from a single line.
-type lineParser struct {
-	cols []string
-	err  error
+	ar.Infof, ar.Debugf = logutil.Infof, logutil.Debugf
+	return ar, nil
 }
 
-func newLineParser(ln string) *lineParser {
-	return &lineParser{cols: strings.Split(ln, "\t")}
-}
-
-func (p *lineParser) getString(i int) string {
-	if p.err != nil {
-		return ""
-	}
-	if i >= len(p.cols) {
-		p.err = fmt.Errorf("column %d requested but only have %d", i, len(p.cols))
-		return ""
+// readArchive opens the .tar.bz2 file at path p and reads the first table within it
+// whose name appears in names. See mbdump.Archive.ForEachRow.
+func readArchive(ctx context.Context, p string, names []string,
+	fn func(string, *mbdump.Row) error) error {
+	ar, err := openArchive(p)
+	if err != nil {
+		return err
 	}
-	return p.cols[i]
+	defer ar.Close()
+	return ar.ForEachRow(ctx, names, fn)
 }
 
-func (p *lineParser) getInt(i int) int32 {
-	s := p.getString(i)
-	if p.err != nil {
-		return 0
+// extractTable copies the named table from the .tar.bz2 file at path p to w.
+// See mbdump.Archive.CopyTable.
+func extractTable(ctx context.Context, w io.Writer, p, table string) error {
+	ar, err := openArchive(p)
+	if err != nil {
+		return err
 	}
-	var v int64
-	v, p.err = strconv.ParseInt(s, 10, 32)
-	return int32(v)
+	defer ar.Close()
+	return ar.CopyTable(ctx, w, table)
 }
 
-func (p *lineParser) getTime(i int) time.Time {
-	s := p.getString(i)
-	if p.err != nil {
-		return time.Time{}
+// readDumpTime reads the TIMESTAMP file from the .tar.bz2 file at path p.
+// The timestamp identifies the point at which the dump was created.
+func readDumpTime(ctx context.Context, p string) (time.Time, error) {
+	ar, err := openArchive(p)
+	if err != nil {
+		return time.Time{}, err
 	}
-	var t time.Time
-	t, p.err = time.Parse(timeLayout, s)
-	return t
+	defer ar.Close()
+	return ar.DumpTime(ctx)
 }
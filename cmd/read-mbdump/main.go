@@ -5,27 +5,86 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/derat/mbstats"
+	"github.com/derat/mbstats/internal/fileutil"
+	"github.com/derat/mbstats/internal/logutil"
+	"github.com/derat/mbstats/mbdump"
 )
 
 func main() {
 	flag.Usage = func() {
 		fmt.Fprintln(flag.CommandLine.Output(), "Usage: read-mbdump [flag]... <DUMP_DIR> <OUT_DIR>")
+		fmt.Fprintln(flag.CommandLine.Output(), "       read-mbdump extract <ARCHIVE> <TABLE>")
 		fmt.Fprintln(flag.CommandLine.Output(), "Process MusicBrainz database dumps and write JSON data for gen-mb-stats.")
+		fmt.Fprintln(flag.CommandLine.Output(), "With \"extract\", write the named table from a .tar.bz2 archive to stdout as TSV.")
 		fmt.Fprintln(flag.CommandLine.Output())
 		flag.PrintDefaults()
 	}
+	historyDumps := flag.String("history-dumps", "", "Comma-separated older dump dirs to read editor name history from")
+	yearStartMonth := flag.Int("year-start-month", 1, "First month (1-12) of the years that edits are grouped into")
+	monthly := flag.Bool("monthly", false, "Also write per-month stats (e.g. editors-2020-03.json)")
+	weekdays := flag.Bool("weekdays", false, "Also write yearly edit counts by day of week to weekdays.json")
+	outPath := flag.String("o", "", "With \"extract\", file to write the table to instead of stdout (parent dirs are created)")
+	tableList := flag.String("tables", "edit,editor", "Comma-separated tables to process ("+strings.Join(knownTables, ", ")+")")
+	logFlags := logutil.AddFlags(flag.CommandLine)
 	flag.Parse()
 
+	// Cancel reads on the first interrupt so that partially-written output can be
+	// cleaned up. Subsequent interrupts kill the process as usual.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	os.Exit(func() int {
+		if err := logFlags.Apply(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		if flag.Arg(0) == "extract" {
+			if flag.NArg() != 3 {
+				flag.Usage()
+				return 2
+			}
+			if *outPath == "" {
+				if err := extractTable(ctx, os.Stdout, flag.Arg(1), flag.Arg(2)); err != nil {
+					log.Print("Failed extracting table: ", err)
+					return 1
+				}
+				return 0
+			}
+			of, err := fileutil.CreateOutputFile(*outPath)
+			if err != nil {
+				log.Print("Failed creating output file: ", err)
+				return 1
+			}
+			if err := extractTable(ctx, of, flag.Arg(1), flag.Arg(2)); err != nil {
+				of.Abort()
+				log.Print("Failed extracting table: ", err)
+				return 1
+			}
+			if err := of.Commit(); err != nil {
+				log.Print("Failed writing output file: ", err)
+				return 1
+			}
+			return 0
+		}
+
 		if flag.NArg() != 2 {
 			flag.Usage()
 			return 2
@@ -33,95 +92,248 @@ func main() {
 		dumpDir := flag.Arg(0)
 		outDir := flag.Arg(1)
 
-		editors, err := readEditorArchive(filepath.Join(dumpDir, "mbdump-editor.tar.bz2"))
+		tables, err := parseTables(*tableList)
 		if err != nil {
-			log.Print("Failed reading editors: ", err)
-			return 1
+			fmt.Fprintln(os.Stderr, "Bad -tables flag:", err)
+			return 2
 		}
-		stats, err := readEditArchive(filepath.Join(dumpDir, "mbdump-edit.tar.bz2"))
-		if err != nil {
-			log.Print("Failed reading edits: ", err)
-			return 1
+		if *yearStartMonth < 1 || *yearStartMonth > 12 {
+			fmt.Fprintln(os.Stderr, "-year-start-month must be in the range [1, 12]")
+			return 2
+		}
+		md := mbstats.Metadata{
+			YearStartMonth: time.Month(*yearStartMonth),
+			Version:        mbstats.StatsVersion,
+		}
+		if *historyDumps != "" && !tables["editor"] {
+			fmt.Fprintln(os.Stderr, "-history-dumps requires the editor table")
+			return 2
 		}
-		if err := writeEditorStats(outDir, stats, editors); err != nil {
-			log.Print("Failed writing stats: ", err)
-			return 1
+
+		editorPath := filepath.Join(dumpDir, "mbdump-editor.tar.bz2")
+		var editors map[mbstats.EditorID]mbstats.Editor
+		if tables["editor"] {
+			if editors, err = readEditorArchive(ctx, editorPath); err != nil {
+				log.Print("Failed reading editors: ", err)
+				return 1
+			}
+		}
+
+		// IDs of editors in the stats that are written.
+		editorIDs := make(map[mbstats.EditorID]bool)
+		if tables["edit"] {
+			var days weekdayCounts
+			if *weekdays {
+				days = make(weekdayCounts)
+			}
+			editPath := filepath.Join(dumpDir, "mbdump-edit.tar.bz2")
+			// The dump time is only used to improve -yearly-dormant's accuracy,
+			// so leave it unset if it's missing.
+			if md.DumpTime, err = readDumpTime(ctx, editPath); errors.Is(err, mbdump.ErrNoTimestamp) {
+				logutil.Infof("Not recording dump time: %v: %v", editPath, err)
+			} else if err != nil {
+				log.Print("Failed reading dump time: ", err)
+				return 1
+			}
+			stats, err := readEditArchive(ctx, editPath, &md, *monthly, days)
+			if err != nil {
+				log.Print("Failed reading edits: ", err)
+				return 1
+			}
+			if days != nil {
+				if err := writeWeekdays(outDir, days); err != nil {
+					log.Print("Failed writing weekday counts: ", err)
+					return 1
+				}
+			}
+			addEditorIDs(editorIDs, stats)
+			if err := writeEditorStats(outDir, stats, editors, &md); err != nil {
+				log.Print("Failed writing stats: ", err)
+				return 1
+			}
+		}
+
+		if tables["vote"] {
+			// The vote and edit_note tables are stored in the edit archive,
+			// which is read again for each of them.
+			stats, err := readVoteArchive(ctx, filepath.Join(dumpDir, "mbdump-edit.tar.bz2"), &md)
+			if err != nil {
+				log.Print("Failed reading votes: ", err)
+				return 1
+			}
+			addEditorIDs(editorIDs, stats)
+			if err := writeVoterStats(outDir, stats, editors); err != nil {
+				log.Print("Failed writing voter stats: ", err)
+				return 1
+			}
+		}
+
+		if tables["edit_note"] {
+			stats, err := readNoteArchive(ctx, filepath.Join(dumpDir, "mbdump-edit.tar.bz2"), &md)
+			if err != nil {
+				log.Print("Failed reading edit notes: ", err)
+				return 1
+			}
+			addEditorIDs(editorIDs, stats)
+			if err := writeNoteStats(outDir, stats, editors); err != nil {
+				log.Print("Failed writing note stats: ", err)
+				return 1
+			}
+		}
+
+		if editors != nil && len(editorIDs) > 0 {
+			if err := writeEditors(outDir, editors, editorIDs); err != nil {
+				log.Print("Failed writing editors: ", err)
+				return 1
+			}
+		}
+
+		if *historyDumps != "" {
+			t, err := readDumpTime(ctx, editorPath)
+			if err != nil {
+				log.Print("Failed reading dump time: ", err)
+				return 1
+			}
+			snaps := []editorSnapshot{{t, editors}}
+			for _, dir := range strings.Split(*historyDumps, ",") {
+				snap, err := readEditorSnapshot(ctx, dir)
+				if err != nil {
+					log.Printf("Failed reading editors from %v: %v", dir, err)
+					return 1
+				}
+				snaps = append(snaps, snap)
+			}
+			if err := writeNameHistory(outDir, buildNameHistory(snaps)); err != nil {
+				log.Print("Failed writing name history: ", err)
+				return 1
+			}
 		}
 		return 0
 	}())
 }
 
-// The MusicBrainz database schema lives here:
-// https://github.com/metabrainz/musicbrainz-server/blob/master/admin/sql/CreateTables.sql
-//
-//  CREATE TABLE editor
-//  (
-//  	id                  SERIAL,
-//  	name                VARCHAR(64) NOT NULL,
-//  	privs               INTEGER DEFAULT 0,
-//  	email               VARCHAR(64) DEFAULT NULL,
-//  	website             VARCHAR(255) DEFAULT NULL,
-//  	bio                 TEXT DEFAULT NULL,
-//  	member_since        TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-//  	email_confirm_date  TIMESTAMP WITH TIME ZONE,
-//  	last_login_date     TIMESTAMP WITH TIME ZONE DEFAULT now(),
-//  	last_updated        TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-//  	birth_date          DATE,
-//  	gender              INTEGER, -- references gender.id
-//  	area                INTEGER, -- references area.id
-//  	password            VARCHAR(128) NOT NULL,
-//  	ha1                 CHAR(32) NOT NULL,
-//  	deleted             BOOLEAN NOT NULL DEFAULT FALSE
-//  );
-//
-//  CREATE TABLE edit
-//  (
-//      id                  SERIAL,
-//      editor              INTEGER NOT NULL, -- references editor.id
-//      type                SMALLINT NOT NULL,
-//      status              SMALLINT NOT NULL,
-//      autoedit            SMALLINT NOT NULL DEFAULT 0,
-//      open_time            TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-//      close_time           TIMESTAMP WITH TIME ZONE,
-//      expire_time          TIMESTAMP WITH TIME ZONE NOT NULL,
-//      language            INTEGER, -- references language.id
-//      quality             SMALLINT NOT NULL DEFAULT 1
-//  );
+// knownTables lists the tables that can be passed via the -tables flag.
+var knownTables = []string{"edit", "edit_note", "editor", "vote"}
+
+// parseTables parses a comma-separated list of table names.
+func parseTables(list string) (map[string]bool, error) {
+	tables := make(map[string]bool)
+	for _, t := range strings.Split(list, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		var ok bool
+		for _, k := range knownTables {
+			if t == k {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown table %q", t)
+		}
+		tables[t] = true
+	}
+	return tables, nil
+}
 
 type editStats map[mbstats.EditType]int32
-type editorStatsMap map[mbstats.EditorID]editStats
+type editorStatsMap map[mbstats.EditorID]*editorCounts
 
-// editorInfo contains a subset of information from the editor table.
-type editorInfo struct {
-	name    string
-	created time.Time // member_since
-	active  time.Time // last_login_date
+// editorCounts contains a single editor's edit counts within a period.
+type editorCounts struct {
+	edits     editStats // all applied edits
+	autoEdits editStats // applied edits that were autoedits
 }
 
+// editorTables contains the names of editor tables that may appear in
+// mbdump-editor.tar.bz2 files. Public dumps contain editor_sanitised while full
+// dumps contain the editor table itself. The sanitised table has the same columns
+// as the editor table (with private values like email addresses cleared), so the
+// editorCol* indexes are used for both.
+var editorTables = []string{mbdump.EditorTable.Path(), mbdump.TableDir + "editor_sanitised"}
+
+// Indexes of columns within editor tables.
+var (
+	editorColID          = mbdump.EditorTable.Col("id")
+	editorColName        = mbdump.EditorTable.Col("name")
+	editorColPrivs       = mbdump.EditorTable.Col("privs")
+	editorColMemberSince = mbdump.EditorTable.Col("member_since")
+	editorColLastLogin   = mbdump.EditorTable.Col("last_login_date")
+	editorColGender      = mbdump.EditorTable.Col("gender")
+	editorColArea        = mbdump.EditorTable.Col("area")
+	editorColDeleted     = mbdump.EditorTable.Col("deleted")
+)
+
+// Indexes of columns within the edit table.
+var (
+	editColEditor   = mbdump.EditTable.Col("editor")
+	editColType     = mbdump.EditTable.Col("type")
+	editColStatus   = mbdump.EditTable.Col("status")
+	editColAutoedit = mbdump.EditTable.Col("autoedit")
+	editColOpenTime = mbdump.EditTable.Col("open_time")
+)
+
 // readEditorArchive reads an mbdump-editor.tar.bz2 file at the specified path.
-func readEditorArchive(p string) (map[mbstats.EditorID]editorInfo, error) {
-	editors := make(map[mbstats.EditorID]editorInfo)
-	err := readArchive(p, "mbdump/editor_sanitised", func(p *lineParser) {
-		id := mbstats.EditorID(p.getInt(0))
-		ed := editorInfo{
-			name:   p.getString(1),
-			active: p.getTime(8),
+// Both sanitised and full editor tables are supported.
+func readEditorArchive(ctx context.Context, p string) (map[mbstats.EditorID]mbstats.Editor, error) {
+	editors := make(map[mbstats.EditorID]mbstats.Editor)
+	err := readArchive(ctx, p, editorTables, func(_ string, row *mbdump.Row) error {
+		ed := mbstats.Editor{
+			ID:     mbstats.EditorID(row.Int32(editorColID)),
+			Name:   row.String(editorColName),
+			Privs:  mbstats.EditorPrivs(row.Int32(editorColPrivs)),
+			Active: row.Time(editorColLastLogin),
 		}
 		// Some accounts are missing a 'member_since' value.
 		// No idea why -- maybe it wasn't recorded initially?
-		if p.getString(6) != emptyCol {
-			ed.created = p.getTime(6)
+		if !row.Null(editorColMemberSince) {
+			ed.Created = row.Time(editorColMemberSince)
+		}
+		if !row.Null(editorColGender) {
+			ed.Gender = row.Int32(editorColGender)
 		}
-		editors[id] = ed
+		if !row.Null(editorColArea) {
+			ed.Area = row.Int32(editorColArea)
+		}
+		// Old dumps (e.g. ones passed via -history-dumps) predate the 'deleted' column.
+		if row.Len() > editorColDeleted {
+			ed.Deleted = row.Bool(editorColDeleted)
+		}
+		editors[ed.ID] = ed
+		return nil
 	})
 	return editors, err
 }
 
 // readEditArchive reads an mbdump-edit.tar.bz2 file at the specified path.
-// The returned map contains per-editor edit type counts keyed by year.
-func readEditArchive(p string) (map[int]editorStatsMap, error) {
-	stats := make(map[int]editorStatsMap)
-	err := readArchive(p, "mbdump/edit", func(p *lineParser) {
+// The returned series contains per-editor edit type counts for years as
+// determined by md. If monthly is true, counts are additionally included for
+// calendar months. If days is non-nil, it is filled with total edits by day of week.
+func readEditArchive(ctx context.Context, p string, md *mbstats.Metadata, monthly bool,
+	days weekdayCounts) (*mbstats.Series[editorStatsMap], error) {
+	var stats mbstats.Series[editorStatsMap]
+	add := func(per mbstats.Period, ed mbstats.EditorID, et mbstats.EditType, auto bool) {
+		editors, ok := stats.Get(per)
+		if !ok {
+			editors = make(editorStatsMap)
+			stats.Set(per, editors)
+		}
+		counts := editors[ed]
+		if counts == nil {
+			counts = &editorCounts{edits: make(editStats)}
+			editors[ed] = counts
+		}
+		counts.edits[et]++
+		if auto {
+			if counts.autoEdits == nil {
+				counts.autoEdits = make(editStats)
+			}
+			counts.autoEdits[et]++
+		}
+	}
+	err := readArchive(ctx, p, []string{mbdump.EditTable.Path()}, func(_ string, row *mbdump.Row) error {
 		// Skip non-applied edits.
 		// https://github.com/metabrainz/musicbrainz-server/blob/master/root/types/edit.js:
 		//
@@ -134,63 +346,108 @@ func readEditArchive(p string) (map[int]editorStatsMap, error) {
 		//    | 6 // FAILEDPREREQ
 		//    | 7 // NOVOTES
 		//    | 9; // DELETED
-		if p.getInt(3) != 2 {
-			return
+		if row.Int32(editColStatus) != 2 {
+			return nil
 		}
 
-		year := p.getTime(5).Year()
-		editors := stats[year]
-		if editors == nil {
-			editors = make(editorStatsMap)
-			stats[year] = editors
+		t := row.Time(editColOpenTime)
+		ed := mbstats.EditorID(row.Int32(editColEditor))
+		et := mbstats.EditType(row.Int32(editColType))
+		auto := row.Int32(editColAutoedit) != 0
+		year := md.Year(t)
+		add(mbstats.YearPeriod(year), ed, et, auto)
+		if days != nil {
+			if days[year] == nil {
+				days[year] = make([]int32, 7)
+			}
+			days[year][t.Weekday()]++
 		}
-		ed := mbstats.EditorID(p.getInt(1))
-		counts := editors[ed]
-		if counts == nil {
-			counts = make(editStats)
-			editors[ed] = counts
+		if monthly {
+			add(mbstats.MonthPeriod(t.Year(), t.Month()), ed, et, auto)
 		}
-		counts[mbstats.EditType(p.getInt(2))]++
+		return nil
 	})
-	return stats, err
+	return &stats, err
 }
 
-// writeEditorStats writes per-year files (e.g. "editors-2020.json") into dir
-// containing JSON-marshaled mbstats.EditorStats objects.
-func writeEditorStats(dir string, stats map[int]editorStatsMap,
-	editors map[mbstats.EditorID]editorInfo) error {
-	if err := os.MkdirAll(dir, 0755); err != nil {
+// writeEditorStats writes per-period files (e.g. "editors-2020.json" or
+// "editors-2020-03.json") into dir containing mbstats.EditorStats objects.
+// stats is as described in readEditArchive.
+// An index of the records in the yearly files and md are also written.
+func writeEditorStats(dir string, stats *mbstats.Series[editorStatsMap],
+	editors map[mbstats.EditorID]mbstats.Editor, md *mbstats.Metadata) error {
+	dw, err := mbstats.NewEditorStatsDirWriter(dir, md)
+	if err != nil {
 		return err
 	}
-
-	for year, em := range stats {
-		p := filepath.Join(dir, fmt.Sprintf("editors-%d.json", year))
-		log.Print("Writing ", p)
-		f, err := os.Create(p)
-		if err != nil {
-			return err
-		}
-		enc := json.NewEncoder(f)
-
-		for id, edits := range em {
+	if err := stats.Each(func(per mbstats.Period, em editorStatsMap) error {
+		logutil.Infof("Writing %v", filepath.Join(dir, mbstats.EditorStatsFile(per)))
+		all := make([]mbstats.EditorStats, 0, len(em))
+		for id, counts := range em {
 			es := mbstats.EditorStats{
-				ID:    id,
-				Edits: edits,
+				ID:        id,
+				Edits:     counts.edits,
+				AutoEdits: counts.autoEdits,
 			}
+			// The name is only used for the index; the rest of the editor's
+			// information is written by writeEditors.
 			if ed, ok := editors[id]; ok {
-				es.Name = ed.name
-				es.Created = ed.created
-				es.Active = ed.active
-			}
-			if err := enc.Encode(es); err != nil {
-				f.Close()
-				return err
+				es.Name = ed.Name
 			}
+			all = append(all, es)
+		}
+		return dw.WritePeriod(per, all)
+	}); err != nil {
+		return err
+	}
+	logutil.Infof("Writing %v and %v", filepath.Join(dir, mbstats.EditorIndexFile), mbstats.MetadataFile)
+	return dw.Close()
+}
+
+// addEditorIDs adds the IDs of the editors in stats to ids.
+func addEditorIDs[M ~map[mbstats.EditorID]V, V any](ids map[mbstats.EditorID]bool, stats *mbstats.Series[M]) {
+	for _, m := range stats.Values() {
+		for id := range m {
+			ids[id] = true
 		}
+	}
+}
 
-		if err := f.Close(); err != nil {
-			return err
+// writeEditors writes the editors from editors with IDs in ids
+// to dir's mbstats.EditorsFile.
+func writeEditors(dir string, editors map[mbstats.EditorID]mbstats.Editor,
+	ids map[mbstats.EditorID]bool) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	logutil.Infof("Writing %v", filepath.Join(dir, mbstats.EditorsFile))
+	all := make([]mbstats.Editor, 0, len(ids))
+	for id := range ids {
+		if ed, ok := editors[id]; ok {
+			all = append(all, ed)
 		}
 	}
-	return nil
+	return mbstats.WriteEditors(dir, all)
+}
+
+// weekdayCounts contains total edits for each day of the week (indexed by
+// time.Weekday), keyed by year.
+type weekdayCounts map[int][]int32
+
+// writeWeekdays writes days to a "weekdays.json" file in dir.
+func writeWeekdays(dir string, days weekdayCounts) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	p := filepath.Join(dir, "weekdays.json")
+	logutil.Infof("Writing %v", p)
+	of, err := fileutil.CreateOutputFile(p)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(of).Encode(days); err != nil {
+		of.Abort()
+		return err
+	}
+	return of.Commit()
 }
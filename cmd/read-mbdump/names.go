@@ -0,0 +1,90 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/derat/mbstats"
+	"github.com/derat/mbstats/internal/fileutil"
+	"github.com/derat/mbstats/internal/logutil"
+)
+
+// editorSnapshot contains the editors from a single database dump.
+type editorSnapshot struct {
+	time    time.Time // dump timestamp
+	editors map[mbstats.EditorID]mbstats.Editor
+}
+
+// readEditorSnapshot reads the mbdump-editor.tar.bz2 file in dumpDir.
+func readEditorSnapshot(ctx context.Context, dumpDir string) (editorSnapshot, error) {
+	p := filepath.Join(dumpDir, "mbdump-editor.tar.bz2")
+	t, err := readDumpTime(ctx, p)
+	if err != nil {
+		return editorSnapshot{}, err
+	}
+	editors, err := readEditorArchive(ctx, p)
+	return editorSnapshot{t, editors}, err
+}
+
+// buildNameHistory returns per-editor name histories built from snaps.
+// The returned slice is sorted by ascending editor ID.
+func buildNameHistory(snaps []editorSnapshot) []mbstats.EditorNameHistory {
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].time.Before(snaps[j].time) })
+
+	hists := make(map[mbstats.EditorID]*mbstats.EditorNameHistory)
+	for _, snap := range snaps {
+		for id, ed := range snap.editors {
+			hist := hists[id]
+			if hist == nil {
+				hist = &mbstats.EditorNameHistory{ID: id}
+				hists[id] = hist
+			}
+			// Only start a new entry when the name has changed.
+			if n := len(hist.Names); n > 0 && hist.Names[n-1].Name == ed.Name {
+				hist.Names[n-1].Last = snap.time
+			} else {
+				hist.Names = append(hist.Names, mbstats.EditorName{
+					Name:  ed.Name,
+					First: snap.time,
+					Last:  snap.time,
+				})
+			}
+		}
+	}
+
+	all := make([]mbstats.EditorNameHistory, 0, len(hists))
+	for _, hist := range hists {
+		all = append(all, *hist)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return all
+}
+
+// writeNameHistory writes an "editor-names.json" file into dir containing
+// JSON-marshaled mbstats.EditorNameHistory objects.
+func writeNameHistory(dir string, hists []mbstats.EditorNameHistory) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	p := filepath.Join(dir, "editor-names.json")
+	logutil.Infof("Writing %v", p)
+	of, err := fileutil.CreateOutputFile(p)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(of)
+	for _, hist := range hists {
+		if err := enc.Encode(hist); err != nil {
+			of.Abort()
+			return err
+		}
+	}
+	return of.Commit()
+}
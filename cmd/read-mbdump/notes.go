@@ -0,0 +1,83 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/derat/mbstats"
+	"github.com/derat/mbstats/internal/fileutil"
+	"github.com/derat/mbstats/internal/logutil"
+	"github.com/derat/mbstats/mbdump"
+)
+
+// Indexes of columns within the edit_note table.
+var (
+	noteColEditor   = mbdump.EditNoteTable.Col("editor")
+	noteColPostTime = mbdump.EditNoteTable.Col("post_time")
+)
+
+type noteStatsMap map[mbstats.EditorID]*mbstats.NoteStats
+
+// readNoteArchive reads the edit_note table from the mbdump-edit.tar.bz2 file at
+// the specified path. The returned series contains per-editor note counts for years
+// determined by md.
+func readNoteArchive(ctx context.Context, p string,
+	md *mbstats.Metadata) (*mbstats.Series[noteStatsMap], error) {
+	var stats mbstats.Series[noteStatsMap]
+	err := readArchive(ctx, p, []string{mbdump.EditNoteTable.Path()}, func(_ string, row *mbdump.Row) error {
+		// post_time is nullable, so skip notes without it.
+		if row.Null(noteColPostTime) {
+			return nil
+		}
+		per := mbstats.YearPeriod(md.Year(row.Time(noteColPostTime)))
+		notes, ok := stats.Get(per)
+		if !ok {
+			notes = make(noteStatsMap)
+			stats.Set(per, notes)
+		}
+		id := mbstats.EditorID(row.Int32(noteColEditor))
+		ns := notes[id]
+		if ns == nil {
+			ns = &mbstats.NoteStats{ID: id}
+			notes[id] = ns
+		}
+		ns.Notes++
+		return nil
+	})
+	return &stats, err
+}
+
+// writeNoteStats writes per-year files (e.g. "notes-2020.json") into dir
+// containing JSON-marshaled mbstats.NoteStats objects.
+func writeNoteStats(dir string, stats *mbstats.Series[noteStatsMap],
+	editors map[mbstats.EditorID]mbstats.Editor) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	return stats.Each(func(per mbstats.Period, nm noteStatsMap) error {
+		p := filepath.Join(dir, fmt.Sprintf("notes-%v.json", per))
+		logutil.Infof("Writing %v", p)
+		of, err := fileutil.CreateOutputFile(p)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(of)
+		for id, ns := range nm {
+			if ed, ok := editors[id]; ok {
+				ns.Name = ed.Name
+			}
+			if err := enc.Encode(ns); err != nil {
+				of.Abort()
+				return err
+			}
+		}
+		return of.Commit()
+	})
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/derat/mbstats"
+	"github.com/derat/mbstats/internal/fileutil"
+	"github.com/derat/mbstats/internal/logutil"
+	"github.com/derat/mbstats/mbdump"
+)
+
+// Indexes of columns within the vote table.
+var (
+	voteColEditor     = mbdump.VoteTable.Col("editor")
+	voteColVote       = mbdump.VoteTable.Col("vote")
+	voteColVoteTime   = mbdump.VoteTable.Col("vote_time")
+	voteColSuperseded = mbdump.VoteTable.Col("superseded")
+)
+
+type voterStatsMap map[mbstats.EditorID]*mbstats.VoterStats
+
+// readVoteArchive reads the vote table from the mbdump-edit.tar.bz2 file at the
+// specified path. The returned series contains per-editor vote counts for years
+// determined by md. Superseded votes are skipped.
+func readVoteArchive(ctx context.Context, p string,
+	md *mbstats.Metadata) (*mbstats.Series[voterStatsMap], error) {
+	var stats mbstats.Series[voterStatsMap]
+	err := readArchive(ctx, p, []string{mbdump.VoteTable.Path()}, func(_ string, row *mbdump.Row) error {
+		if row.Bool(voteColSuperseded) {
+			return nil
+		}
+		per := mbstats.YearPeriod(md.Year(row.Time(voteColVoteTime)))
+		voters, ok := stats.Get(per)
+		if !ok {
+			voters = make(voterStatsMap)
+			stats.Set(per, voters)
+		}
+		id := mbstats.EditorID(row.Int32(voteColEditor))
+		vs := voters[id]
+		if vs == nil {
+			vs = &mbstats.VoterStats{ID: id}
+			voters[id] = vs
+		}
+		// See $VOTE_* in lib/MusicBrainz/Server/Constants.pm.
+		switch v := row.Int32(voteColVote); v {
+		case -1:
+			vs.Abstain++
+		case 0:
+			vs.No++
+		case 1:
+			vs.Yes++
+		case 2:
+			vs.Approve++
+		default:
+			return fmt.Errorf("unknown vote %d", v)
+		}
+		return nil
+	})
+	return &stats, err
+}
+
+// writeVoterStats writes per-year files (e.g. "voters-2020.json") into dir
+// containing JSON-marshaled mbstats.VoterStats objects.
+func writeVoterStats(dir string, stats *mbstats.Series[voterStatsMap],
+	editors map[mbstats.EditorID]mbstats.Editor) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	return stats.Each(func(per mbstats.Period, vm voterStatsMap) error {
+		p := filepath.Join(dir, fmt.Sprintf("voters-%v.json", per))
+		logutil.Infof("Writing %v", p)
+		of, err := fileutil.CreateOutputFile(p)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(of)
+		for id, vs := range vm {
+			if ed, ok := editors[id]; ok {
+				vs.Name = ed.Name
+			}
+			if err := enc.Encode(vs); err != nil {
+				of.Abort()
+				return err
+			}
+		}
+		return of.Commit()
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbstats
+
+// The functions in this file operate on per-edit-type counts like EditorStats.Edits.
+// Missing keys are treated as zero counts, and keys whose counts become zero are
+// deleted so that results don't depend on whether zero counts were stored.
+// Negative counts (e.g. a decrease in an editor's edits) are preserved.
+
+// AddCounts adds src's counts to dst, allocating dst if needed, and returns dst.
+func AddCounts(dst, src map[EditType]int32) map[EditType]int32 {
+	if dst == nil && len(src) > 0 {
+		dst = make(map[EditType]int32, len(src))
+	}
+	for et, cnt := range src {
+		if v := dst[et] + cnt; v != 0 {
+			dst[et] = v
+		} else {
+			delete(dst, et)
+		}
+	}
+	return dst
+}
+
+// SubCounts subtracts src's counts from dst, allocating dst if needed, and returns dst.
+// Counts that aren't present in dst become negative.
+func SubCounts(dst, src map[EditType]int32) map[EditType]int32 {
+	if dst == nil && len(src) > 0 {
+		dst = make(map[EditType]int32, len(src))
+	}
+	for et, cnt := range src {
+		if v := dst[et] - cnt; v != 0 {
+			dst[et] = v
+		} else {
+			delete(dst, et)
+		}
+	}
+	return dst
+}
+
+// DiffCounts returns a new map containing the change in counts from a to b,
+// e.g. an editor's previous and current years' edits. Neither map is modified.
+// Types with equal counts in a and b are omitted, and nil is returned if there
+// are no differences.
+func DiffCounts(a, b map[EditType]int32) map[EditType]int32 {
+	var diff map[EditType]int32
+	for et, cnt := range b {
+		if v := cnt - a[et]; v != 0 {
+			if diff == nil {
+				diff = make(map[EditType]int32)
+			}
+			diff[et] = v
+		}
+	}
+	for et, cnt := range a {
+		if _, ok := b[et]; !ok && cnt != 0 {
+			if diff == nil {
+				diff = make(map[EditType]int32)
+			}
+			diff[et] = -cnt
+		}
+	}
+	return diff
+}
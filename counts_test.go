@@ -0,0 +1,108 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbstats
+
+import (
+	"reflect"
+	"testing"
+)
+
+// counts is a shorthand for map[EditType]int32.
+type counts = map[EditType]int32
+
+// cloneCounts returns a copy of m, preserving nil.
+func cloneCounts(m counts) counts {
+	if m == nil {
+		return nil
+	}
+	c := make(counts, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func TestAddCounts(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		dst, src counts
+		want     counts
+	}{
+		{"both nil", nil, nil, nil},
+		{"nil dst", nil, counts{1: 2}, counts{1: 2}},
+		{"nil src", counts{1: 2}, nil, counts{1: 2}},
+		{"missing keys", counts{1: 2}, counts{2: 3}, counts{1: 2, 2: 3}},
+		{"sum", counts{1: 2, 2: 1}, counts{1: 3}, counts{1: 5, 2: 1}},
+		{"cancel to zero", counts{1: 2, 2: 1}, counts{1: -2}, counts{2: 1}},
+		{"negative result", counts{1: 2}, counts{1: -5}, counts{1: -3}},
+		{"zero src count", nil, counts{1: 0}, counts{}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			src := cloneCounts(tc.src)
+			if got := AddCounts(cloneCounts(tc.dst), tc.src); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("AddCounts(%v, %v) = %v; want %v", tc.dst, tc.src, got, tc.want)
+			}
+			if !reflect.DeepEqual(tc.src, src) {
+				t.Errorf("AddCounts modified src: %v; was %v", tc.src, src)
+			}
+		})
+	}
+}
+
+func TestSubCounts(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		dst, src counts
+		want     counts
+	}{
+		{"both nil", nil, nil, nil},
+		{"nil dst", nil, counts{1: 2}, counts{1: -2}},
+		{"nil src", counts{1: 2}, nil, counts{1: 2}},
+		{"missing keys", counts{1: 2}, counts{2: 3}, counts{1: 2, 2: -3}},
+		{"difference", counts{1: 5, 2: 1}, counts{1: 3}, counts{1: 2, 2: 1}},
+		{"cancel to zero", counts{1: 2, 2: 1}, counts{1: 2}, counts{2: 1}},
+		{"all cancel", counts{1: 2, 2: 1}, counts{1: 2, 2: 1}, counts{}},
+		{"negative result", counts{1: 2}, counts{1: 5}, counts{1: -3}},
+		{"negative src", counts{1: -2}, counts{1: -5}, counts{1: 3}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			src := cloneCounts(tc.src)
+			if got := SubCounts(cloneCounts(tc.dst), tc.src); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("SubCounts(%v, %v) = %v; want %v", tc.dst, tc.src, got, tc.want)
+			}
+			if !reflect.DeepEqual(tc.src, src) {
+				t.Errorf("SubCounts modified src: %v; was %v", tc.src, src)
+			}
+		})
+	}
+}
+
+func TestDiffCounts(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		a, b counts
+		want counts
+	}{
+		{"both nil", nil, nil, nil},
+		{"both empty", counts{}, counts{}, nil},
+		{"nil a", nil, counts{1: 2}, counts{1: 2}},
+		{"nil b", counts{1: 2}, nil, counts{1: -2}},
+		{"equal", counts{1: 2, 2: 3}, counts{1: 2, 2: 3}, nil},
+		{"missing keys", counts{1: 2}, counts{2: 3}, counts{1: -2, 2: 3}},
+		{"increase", counts{1: 2}, counts{1: 5}, counts{1: 3}},
+		{"decrease", counts{1: 5, 2: 1}, counts{1: 2, 2: 1}, counts{1: -3}},
+		{"zero counts", counts{1: 0}, counts{2: 0}, nil},
+		{"negative counts", counts{1: -2}, counts{1: 3}, counts{1: 5}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			a, b := cloneCounts(tc.a), cloneCounts(tc.b)
+			if got := DiffCounts(tc.a, tc.b); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("DiffCounts(%v, %v) = %v; want %v", tc.a, tc.b, got, tc.want)
+			}
+			if !reflect.DeepEqual(tc.a, a) || !reflect.DeepEqual(tc.b, b) {
+				t.Errorf("DiffCounts modified args: %v, %v; were %v, %v", tc.a, tc.b, a, b)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbstats
+
+import "time"
+
+// EditorPredicate returns true if es should be included in results.
+// Predicates must not modify es.
+type EditorPredicate func(es *EditorStats) bool
+
+// FilterEditors returns a new slice containing the records in stats that are
+// matched by all of preds, in their original order. All records are returned if
+// preds is empty. The returned records share their edit count maps with stats.
+func FilterEditors(stats []EditorStats, preds ...EditorPredicate) []EditorStats {
+	var res []EditorStats
+	for i := range stats {
+		if MatchEditor(&stats[i], preds...) {
+			res = append(res, stats[i])
+		}
+	}
+	return res
+}
+
+// MatchEditor returns true if es is matched by all of preds.
+func MatchEditor(es *EditorStats, preds ...EditorPredicate) bool {
+	for _, pred := range preds {
+		if !pred(es) {
+			return false
+		}
+	}
+	return true
+}
+
+// MinEdits returns a predicate matching editors with at least n edits of any type.
+func MinEdits(n int) EditorPredicate {
+	return func(es *EditorStats) bool {
+		var cnt int
+		for _, c := range es.Edits {
+			cnt += int(c)
+		}
+		return cnt >= n
+	}
+}
+
+// MinTypeEdits returns a predicate matching editors with at least n edits with
+// any of the supplied types. Only the supplied types are counted, so if types is
+// empty, editors are only matched if n is 0 or less. Use MinEdits to count edits
+// of all types.
+func MinTypeEdits(n int, types ...EditType) EditorPredicate {
+	// Ignore duplicate types so their edits aren't counted multiple times.
+	seen := make(map[EditType]bool, len(types))
+	uniq := make([]EditType, 0, len(types))
+	for _, et := range types {
+		if !seen[et] {
+			uniq = append(uniq, et)
+			seen[et] = true
+		}
+	}
+	return func(es *EditorStats) bool {
+		var cnt int
+		for _, et := range uniq {
+			cnt += int(es.Edits[et])
+		}
+		return cnt >= n
+	}
+}
+
+// HasType returns a predicate matching editors with at least one edit with any
+// of the supplied types. No editors are matched if types is empty.
+func HasType(types ...EditType) EditorPredicate {
+	return MinTypeEdits(1, types...)
+}
+
+// CreatedBefore returns a predicate matching editors whose accounts were created
+// before t. Editors with unknown creation times are not matched.
+func CreatedBefore(t time.Time) EditorPredicate {
+	return func(es *EditorStats) bool {
+		return !es.Created.IsZero() && es.Created.Before(t)
+	}
+}
+
+// IsBot is a predicate matching bot accounts.
+func IsBot(es *EditorStats) bool { return es.Bot }
+
+// Not returns a predicate matching editors that aren't matched by pred,
+// e.g. Not(IsBot) to exclude bot accounts.
+func Not(pred EditorPredicate) EditorPredicate {
+	return func(es *EditorStats) bool { return !pred(es) }
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbstats
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPredicates(t *testing.T) {
+	t2010 := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2015 := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2020 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	es := &EditorStats{
+		ID:      1,
+		Created: t2015,
+		Edits:   map[EditType]int32{EDIT_ARTIST_CREATE: 3, EDIT_ARTIST_EDIT: 2, EDIT_RELEASE_CREATE: 1},
+	}
+	bot := &EditorStats{ID: 2, Bot: true, Edits: map[EditType]int32{EDIT_ARTIST_EDIT: 100}}
+	empty := &EditorStats{ID: 3}
+
+	for _, tc := range []struct {
+		desc string
+		pred EditorPredicate
+		es   *EditorStats
+		want bool
+	}{
+		{"MinEdits all types", MinEdits(6), es, true},
+		{"MinEdits too few", MinEdits(7), es, false},
+		{"MinEdits zero", MinEdits(0), empty, true},
+		{"MinEdits no edits", MinEdits(1), empty, false},
+		{"MinTypeEdits single type", MinTypeEdits(3, EDIT_ARTIST_CREATE), es, true},
+		{"MinTypeEdits too few", MinTypeEdits(4, EDIT_ARTIST_CREATE), es, false},
+		{"MinTypeEdits multiple types", MinTypeEdits(5, EDIT_ARTIST_CREATE, EDIT_ARTIST_EDIT), es, true},
+		{"MinTypeEdits duplicate types", MinTypeEdits(6, EDIT_ARTIST_CREATE, EDIT_ARTIST_CREATE), es, false},
+		{"MinTypeEdits missing type", MinTypeEdits(1, EDIT_LABEL_CREATE), es, false},
+		{"MinTypeEdits no types", MinTypeEdits(1), es, false},
+		{"MinTypeEdits no types zero", MinTypeEdits(0), es, true},
+		{"HasType", HasType(EDIT_LABEL_CREATE, EDIT_RELEASE_CREATE), es, true},
+		{"HasType missing", HasType(EDIT_LABEL_CREATE), es, false},
+		{"HasType no types", HasType(), es, false},
+		{"CreatedBefore later", CreatedBefore(t2020), es, true},
+		{"CreatedBefore earlier", CreatedBefore(t2010), es, false},
+		{"CreatedBefore equal", CreatedBefore(t2015), es, false},
+		{"CreatedBefore unknown", CreatedBefore(t2020), bot, false},
+		{"IsBot", IsBot, bot, true},
+		{"IsBot human", IsBot, es, false},
+		{"Not", Not(IsBot), es, true},
+		{"Not bot", Not(IsBot), bot, false},
+		{"Not CreatedBefore unknown", Not(CreatedBefore(t2020)), bot, true},
+	} {
+		if got := tc.pred(tc.es); got != tc.want {
+			t.Errorf("%v: predicate returned %v for editor %d; want %v", tc.desc, got, tc.es.ID, tc.want)
+		}
+	}
+}
+
+func TestMatchEditor(t *testing.T) {
+	es := &EditorStats{ID: 1, Edits: map[EditType]int32{EDIT_ARTIST_CREATE: 3}}
+	for _, tc := range []struct {
+		desc  string
+		preds []EditorPredicate
+		want  bool
+	}{
+		{"no predicates", nil, true},
+		{"all match", []EditorPredicate{MinEdits(3), Not(IsBot)}, true},
+		{"one fails", []EditorPredicate{MinEdits(3), IsBot}, false},
+		{"first fails", []EditorPredicate{MinEdits(4), Not(IsBot)}, false},
+	} {
+		if got := MatchEditor(es, tc.preds...); got != tc.want {
+			t.Errorf("%v: MatchEditor returned %v; want %v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestFilterEditors(t *testing.T) {
+	stats := []EditorStats{
+		{ID: 1, Edits: map[EditType]int32{EDIT_ARTIST_CREATE: 3}},
+		{ID: 2, Bot: true, Edits: map[EditType]int32{EDIT_ARTIST_CREATE: 5}},
+		{ID: 3, Edits: map[EditType]int32{EDIT_RELEASE_CREATE: 1}},
+		{ID: 4, Edits: map[EditType]int32{EDIT_ARTIST_CREATE: 2, EDIT_RELEASE_CREATE: 4}},
+	}
+	ids := func(stats []EditorStats) []EditorID {
+		var res []EditorID
+		for _, es := range stats {
+			res = append(res, es.ID)
+		}
+		return res
+	}
+	for _, tc := range []struct {
+		desc  string
+		preds []EditorPredicate
+		want  []EditorID
+	}{
+		{"no predicates", nil, []EditorID{1, 2, 3, 4}},
+		{"one predicate", []EditorPredicate{HasType(EDIT_ARTIST_CREATE)}, []EditorID{1, 2, 4}},
+		{"multiple predicates", []EditorPredicate{MinTypeEdits(3, EDIT_ARTIST_CREATE), Not(IsBot)}, []EditorID{1}},
+		{"no matches", []EditorPredicate{MinEdits(100)}, nil},
+	} {
+		if got := ids(FilterEditors(stats, tc.preds...)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v: FilterEditors returned %v; want %v", tc.desc, got, tc.want)
+		}
+	}
+
+	// The original slice shouldn't be modified.
+	if got, want := ids(stats), []EditorID{1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterEditors modified input: got %v; want %v", got, want)
+	}
+}
@@ -3,6 +3,58 @@
 URL=https://raw.githubusercontent.com/metabrainz/musicbrainz-server/master/root/types/edit_type_ids.js
 OUT=types_gen.go
 
+# Entities that begin edit type names, e.g. "ARTIST" in "ARTIST_CREATE". Entities
+# that are prefixes of other entities are listed after them so that the longest
+# match is used.
+ENTITIES="AREA ARTIST EVENT GENRE INSTRUMENT LABEL MEDIUM PLACE RECORDING
+RELATIONSHIP RELEASEGROUP RELEASE SERIES URL WIKIDOC WORK"
+
+# Entities and actions for edit types whose names don't begin with an entity.
+#
+# Historic types (edits imported from the pre-2011 database schema) are mapped
+# to the entities that they affect in the current schema. Tracks in the old
+# schema became recordings, and disc IDs are now attached to mediums.
+INFO_OVERRIDES="
+RELATIONSHIPS_REORDER RELATIONSHIP REORDER
+SET_TRACK_LENGTHS MEDIUM SET_TRACK_LENGTHS
+HISTORIC_ADD_DISCID MEDIUM ADD_DISCID
+HISTORIC_ADD_LINK RELATIONSHIP ADD
+HISTORIC_ADD_RELEASE RELEASE ADD
+HISTORIC_ADD_RELEASE_ANNOTATION RELEASE ADD_ANNOTATION
+HISTORIC_ADD_RELEASE_EVENTS RELEASE ADD_EVENTS
+HISTORIC_ADD_TRACK RECORDING ADD
+HISTORIC_ADD_TRACK_KV RECORDING ADD_KV
+HISTORIC_CHANGE_ARTIST_QUALITY ARTIST CHANGE_QUALITY
+HISTORIC_CHANGE_RELEASE_GROUP RELEASE CHANGE_RELEASEGROUP
+HISTORIC_CHANGE_RELEASE_QUALITY RELEASE CHANGE_QUALITY
+HISTORIC_CHANGE_TRACK_ARTIST RECORDING CHANGE_ARTIST
+HISTORIC_EDIT_LINK RELATIONSHIP EDIT
+HISTORIC_EDIT_LINK_TYPE RELATIONSHIP EDIT_LINK_TYPE
+HISTORIC_EDIT_RELEASE_ATTRS RELEASE EDIT_ATTRS
+HISTORIC_EDIT_RELEASE_EVENTS RELEASE EDIT_EVENTS
+HISTORIC_EDIT_RELEASE_EVENTS_OLD RELEASE EDIT_EVENTS_OLD
+HISTORIC_EDIT_RELEASE_LANGUAGE RELEASE EDIT_LANGUAGE
+HISTORIC_EDIT_RELEASE_NAME RELEASE EDIT_NAME
+HISTORIC_EDIT_TRACK_LENGTH RECORDING EDIT_LENGTH
+HISTORIC_EDIT_TRACKNAME RECORDING EDIT_NAME
+HISTORIC_EDIT_TRACKNUM MEDIUM EDIT_TRACKNUM
+HISTORIC_MAC_TO_SAC RELEASE MAC_TO_SAC
+HISTORIC_MERGE_RELEASE RELEASE MERGE
+HISTORIC_MERGE_RELEASE_MAC RELEASE MERGE_MAC
+HISTORIC_MOVE_DISCID MEDIUM MOVE_DISCID
+HISTORIC_MOVE_RELEASE RELEASE MOVE
+HISTORIC_REMOVE_DISCID MEDIUM REMOVE_DISCID
+HISTORIC_REMOVE_LABEL_ALIAS LABEL REMOVE_ALIAS
+HISTORIC_REMOVE_LINK RELATIONSHIP REMOVE
+HISTORIC_REMOVE_LINK_TYPE RELATIONSHIP REMOVE_LINK_TYPE
+HISTORIC_REMOVE_RELEASE RELEASE REMOVE
+HISTORIC_REMOVE_RELEASE_EVENTS RELEASE REMOVE_EVENTS
+HISTORIC_REMOVE_RELEASES RELEASE REMOVE_MULTIPLE
+HISTORIC_REMOVE_TRACK RECORDING REMOVE
+HISTORIC_SAC_TO_MAC RELEASE SAC_TO_MAC
+HISTORIC_SET_TRACK_LENGTHS_FROM_CDTOC MEDIUM SET_TRACK_LENGTHS_FROM_CDTOC
+"
+
 tmpfile=$(mktemp --tmpdir mbstats_gen_types.XXXXXX)
 trap "rm '$tmpfile'" EXIT
 curl --silent --show-error "$URL" >"$tmpfile"
@@ -31,6 +83,50 @@ EOF
   sed -nre 's/^declare type EDIT_([_A-Z]+)_T .*$/EDIT_\1: "\1",/p' | \
   sort >>"$OUT"
 
+cat >>"$OUT" <<EOF
+}
+
+var editTypeIDs = map[string]EditType{
+EOF
+
+<"$tmpfile" \
+  sed -nre 's/^declare type EDIT_([_A-Z]+)_T .*$/"\1": EDIT_\1,/p' | \
+  sort >>"$OUT"
+
+cat >>"$OUT" <<EOF
+}
+
+// editTypeInfos contains the entity and action for each edit type.
+var editTypeInfos = map[EditType][2]string{
+EOF
+
+<"$tmpfile" \
+  sed -nre 's/^declare type EDIT_([_A-Z]+)_T .*$/\1/p' | \
+  sort | \
+  awk -v entities="$ENTITIES" -v overrides="$INFO_OVERRIDES" '
+    BEGIN {
+      nents = split(entities, ents)
+      n = split(overrides, lines, "\n")
+      for (i = 1; i <= n; i++) {
+        if (split(lines[i], f, " ") == 3) info[f[1]] = f[2] " " f[3]
+      }
+    }
+    {
+      if ($1 in info) {
+        split(info[$1], f, " ")
+        printf "EDIT_%s: {\"%s\", \"%s\"},\n", $1, f[1], f[2]
+        next
+      }
+      for (i = 1; i <= nents; i++) {
+        if (index($1, ents[i] "_") == 1) {
+          printf "EDIT_%s: {\"%s\", \"%s\"},\n", $1, ents[i], substr($1, length(ents[i]) + 2)
+          next
+        }
+      }
+      print "No entity for " $1 >"/dev/stderr"
+      exit 1
+    }' >>"$OUT"
+
 cat >>"$OUT" <<EOF
 }
 EOF
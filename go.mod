@@ -2,4 +2,4 @@ module github.com/derat/mbstats
 
 go 1.19
 
-require github.com/montanaflynn/stats v0.6.6 // indirect
+require github.com/montanaflynn/stats v0.6.6
@@ -0,0 +1,268 @@
+// Copyright 2020 Daniel Erat. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package histogram implements simple histograms of integer values
+// that can be written as text bar charts.
+package histogram
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Histogram implements a simple histogram with either linear buckets or
+// buckets with arbitrary boundaries.
+type Histogram struct {
+	Buckets   []Bucket
+	Underflow int // weight of values below the first bucket
+	Overflow  int // weight of values above the last bucket
+
+	step float64 // zero if buckets aren't linear
+}
+
+// Bucket is an individual bucket within Histogram.
+type Bucket struct {
+	Min, Max int64 // inclusive
+	Count    int
+}
+
+// New returns a new histogram suitable for counting values between min
+// and max, inclusive, with nb buckets. A single bucket is used if nb is less than 1,
+// and nb is reduced to the number of values in the range if it's larger so that
+// each bucket contains at least one value.
+func New(min, max int64, nb int) *Histogram {
+	if nb < 1 {
+		nb = 1
+	}
+	if n := max - min + 1; n >= 1 && int64(nb) > n {
+		nb = int(n)
+	}
+	h := &Histogram{
+		Buckets: make([]Bucket, nb),
+		step:    float64(max-min+1) / float64(nb),
+	}
+	for i := range h.Buckets {
+		b := &h.Buckets[i]
+		b.Min = min + int64(float64(i)*h.step)
+		b.Max = min + int64(float64(i+1)*h.step) - 1
+	}
+	return h
+}
+
+// NewQuantile returns a new histogram with up to nb buckets whose
+// boundaries are chosen from the quantiles of vals. vals are not added to it.
+// Fewer buckets are used if there are too few distinct values, and a single
+// bucket is used if nb is less than 1.
+func NewQuantile(vals []int64, nb int) *Histogram {
+	if nb < 1 {
+		nb = 1
+	}
+	if len(vals) == 0 {
+		return New(0, 0, 1)
+	}
+	sorted := append([]int64(nil), vals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	h := &Histogram{}
+	for i := 0; i < nb; i++ {
+		min := sorted[i*len(sorted)/nb]
+		if n := len(h.Buckets); n > 0 {
+			if min <= h.Buckets[n-1].Min {
+				continue // skip repeated values
+			}
+			h.Buckets[n-1].Max = min - 1
+		}
+		h.Buckets = append(h.Buckets, Bucket{Min: min})
+	}
+	h.Buckets[len(h.Buckets)-1].Max = sorted[len(sorted)-1]
+	return h
+}
+
+// Empty returns a new histogram with the same buckets as h but no counts.
+func (h *Histogram) Empty() *Histogram {
+	e := &Histogram{Buckets: append([]Bucket(nil), h.Buckets...), step: h.step}
+	for i := range e.Buckets {
+		e.Buckets[i].Count = 0
+	}
+	return e
+}
+
+// Add records n in the appropriate bucket.
+func (h *Histogram) Add(n int64) {
+	h.AddWeighted(n, 1)
+}
+
+// AddWeighted adds weight to the count of the bucket containing n.
+func (h *Histogram) AddWeighted(n int64, weight int) {
+	switch i := h.Index(n); {
+	case i < 0:
+		h.Underflow += weight
+	case i == len(h.Buckets):
+		h.Overflow += weight
+	default:
+		h.Buckets[i].Count += weight
+	}
+}
+
+// Index returns the index of the bucket that n belongs in. -1 is returned if
+// n is below the first bucket and len(h.Buckets) if it's above the last one.
+func (h *Histogram) Index(n int64) int {
+	if n < h.Buckets[0].Min {
+		return -1
+	} else if n > h.Buckets[len(h.Buckets)-1].Max {
+		return len(h.Buckets)
+	} else if h.step == 0 {
+		return sort.Search(len(h.Buckets), func(i int) bool { return h.Buckets[i].Max >= n })
+	}
+
+	// We'd ideally be able to compute the bucket directly here.
+	// However, this doesn't work due to truncation.
+	//
+	// Consider a case with 10 buckets and a range of [4, 50].
+	// step will be 4.7, and buckets[2].min will be 4 + (2 * 4.7) = 13.4,
+	// which will be truncated to 13. If we try to compute the bucket for
+	// 13, we'll get (13 - 4) / 4.7 = 1.915 instead of 2. This is because
+	// the "real" lower bound for the bucket given the step is 13.4.
+	//
+	// To work around this, use the next smaller or larger bucket if needed.
+	// Maybe there's an easier way to do this, but I'm not seeing it...
+	i := int(float64(n-h.Buckets[0].Min) / h.step)
+	if n > h.Buckets[i].Max {
+		i++
+	}
+	return i
+}
+
+// Labels returns labels for h's underflow values, buckets, and overflow values.
+func (h *Histogram) Labels() []string {
+	labels := []string{h.UnderflowLabel()}
+	for _, b := range h.Buckets {
+		labels = append(labels, b.Label())
+	}
+	return append(labels, h.OverflowLabel())
+}
+
+// UnderflowLabel returns a label describing values below h's first bucket, e.g. "<5".
+func (h *Histogram) UnderflowLabel() string {
+	return fmt.Sprintf("<%v", h.Buckets[0].Min)
+}
+
+// OverflowLabel returns a label describing values above h's last bucket, e.g. ">20".
+func (h *Histogram) OverflowLabel() string {
+	return fmt.Sprintf(">%v", h.Buckets[len(h.Buckets)-1].Max)
+}
+
+// Label returns a label describing b's range, e.g. "5" or "5-9".
+func (b *Bucket) Label() string {
+	if b.Min == b.Max {
+		return fmt.Sprintf("%v", b.Min)
+	}
+	return fmt.Sprintf("%v-%v", b.Min, b.Max)
+}
+
+// Style controls how histograms are written as text.
+type Style struct {
+	Unicode  bool // draw bars using Unicode block characters instead of '#'
+	Color    bool // color bars using ANSI escape sequences
+	Percent  bool // print each count's percentage of the total count
+	BarWidth int  // width of the bar used for the largest count, or 0 to fill Width
+	Width    int  // total line width to fill if BarWidth is 0
+}
+
+const (
+	minBarWidth = 10 // minimum bar width when filling the line width
+	barColor    = "\x1b[36m"
+	resetColor  = "\x1b[0m"
+)
+
+// partialBlocks contains Unicode characters for bars of 0 through 7 eighths of a cell.
+var partialBlocks = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// Write writes a string representation of h to w.
+// labelWidth specifies a lower bound for the width to use for labels.
+func (h *Histogram) Write(w io.Writer, labelWidth int, style *Style) error {
+	// Find the overflow label width. (Underflow could technically be wider if
+	// it's negative, but *shrug*.)
+	ow := len(fmt.Sprintf("%v", h.Buckets[len(h.Buckets)-1].Max+1)) + 1
+	if ow > labelWidth {
+		labelWidth = ow
+	}
+
+	// Find the maximum bar and label widths.
+	maxCount := 0
+	total := h.Underflow + h.Overflow
+	for _, b := range h.Buckets {
+		if b.Count > maxCount {
+			maxCount = b.Count
+		}
+		total += b.Count
+		lw := len(b.Label())
+		if lw > labelWidth {
+			labelWidth = lw
+		}
+	}
+	if h.Underflow > maxCount {
+		maxCount = h.Underflow
+	}
+	if h.Overflow > maxCount {
+		maxCount = h.Overflow
+	}
+
+	const pctFmt = " (%5.1f%%)"
+	barWidth := style.BarWidth
+	if barWidth <= 0 {
+		// Leave room for the label, separator, count, and percentage.
+		barWidth = style.Width - labelWidth - len(" |") - len(" "+strconv.Itoa(maxCount))
+		if style.Percent {
+			barWidth -= len(fmt.Sprintf(pctFmt, 100.0))
+		}
+		if barWidth < minBarWidth {
+			barWidth = minBarWidth
+		}
+	}
+
+	fmtStr := fmt.Sprintf("%%%ds |%%s\n", labelWidth)
+
+	var perr error
+	printLine := func(label string, count int) {
+		if perr != nil {
+			return
+		}
+		var bar string
+		var frac float64
+		if maxCount > 0 {
+			frac = float64(count) / float64(maxCount)
+		}
+		if style.Unicode {
+			eighths := int(math.Round(frac * float64(barWidth*8)))
+			bar = strings.Repeat("█", eighths/8) + partialBlocks[eighths%8]
+		} else {
+			bar = strings.Repeat("#", int(math.Round(frac*float64(barWidth))))
+		}
+		if style.Color && bar != "" {
+			bar = barColor + bar + resetColor
+		}
+		bar += " " + strconv.Itoa(count)
+		if style.Percent && total > 0 {
+			bar += fmt.Sprintf(pctFmt, 100*float64(count)/float64(total))
+		}
+		_, perr = fmt.Fprintf(w, fmtStr, label, bar)
+	}
+
+	if h.Underflow > 0 {
+		printLine(h.UnderflowLabel(), h.Underflow)
+	}
+	for _, b := range h.Buckets {
+		printLine(b.Label(), b.Count)
+	}
+	if h.Overflow > 0 {
+		printLine(h.OverflowLabel(), h.Overflow)
+	}
+
+	return perr
+}
@@ -0,0 +1,232 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package histogram
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// bounds returns the [min, max] pairs of h's buckets.
+func bounds(h *Histogram) [][2]int64 {
+	var bs [][2]int64
+	for _, b := range h.Buckets {
+		bs = append(bs, [2]int64{b.Min, b.Max})
+	}
+	return bs
+}
+
+// counts returns h's underflow count, bucket counts, and overflow count.
+func counts(h *Histogram) []int {
+	cs := []int{h.Underflow}
+	for _, b := range h.Buckets {
+		cs = append(cs, b.Count)
+	}
+	return append(cs, h.Overflow)
+}
+
+func TestNew(t *testing.T) {
+	for _, tc := range []struct {
+		min, max int64
+		nb       int
+		want     [][2]int64
+	}{
+		{0, 9, 1, [][2]int64{{0, 9}}},
+		{0, 9, 2, [][2]int64{{0, 4}, {5, 9}}},
+		{0, 9, 10, [][2]int64{{0, 0}, {1, 1}, {2, 2}, {3, 3}, {4, 4}, {5, 5}, {6, 6}, {7, 7}, {8, 8}, {9, 9}}},
+		{1, 10, 3, [][2]int64{{1, 3}, {4, 6}, {7, 10}}},
+		{-5, 4, 2, [][2]int64{{-5, -1}, {0, 4}}},
+		{4, 50, 10, [][2]int64{{4, 7}, {8, 12}, {13, 17}, {18, 21}, {22, 26},
+			{27, 31}, {32, 35}, {36, 40}, {41, 45}, {46, 50}}},
+		// Buckets are limited to the number of values in the range.
+		{0, 2, 5, [][2]int64{{0, 0}, {1, 1}, {2, 2}}},
+		{7, 7, 3, [][2]int64{{7, 7}}},
+		{-1, 1, 100, [][2]int64{{-1, -1}, {0, 0}, {1, 1}}},
+		{0, 9, 0, [][2]int64{{0, 9}}},
+		{0, 9, -2, [][2]int64{{0, 9}}},
+	} {
+		if got := bounds(New(tc.min, tc.max, tc.nb)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("New(%v, %v, %v) buckets = %v; want %v", tc.min, tc.max, tc.nb, got, tc.want)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	// These ranges produce non-integral steps, so computing buckets directly from
+	// values would put values near the truncated boundaries in the wrong buckets.
+	for _, tc := range []struct {
+		min, max int64
+		nb       int
+	}{
+		{4, 50, 10},
+		{0, 99, 7},
+		{1, 1000, 13},
+		{-37, 41, 9},
+		{0, 5, 6},
+	} {
+		h := New(tc.min, tc.max, tc.nb)
+		if got := h.Index(tc.min - 1); got != -1 {
+			t.Errorf("New(%v, %v, %v).Index(%v) = %v; want -1",
+				tc.min, tc.max, tc.nb, tc.min-1, got)
+		}
+		if got := h.Index(tc.max + 1); got != tc.nb {
+			t.Errorf("New(%v, %v, %v).Index(%v) = %v; want %v",
+				tc.min, tc.max, tc.nb, tc.max+1, got, tc.nb)
+		}
+		for n := tc.min; n <= tc.max; n++ {
+			i := h.Index(n)
+			if i < 0 || i >= len(h.Buckets) || n < h.Buckets[i].Min || n > h.Buckets[i].Max {
+				t.Errorf("New(%v, %v, %v).Index(%v) = %v; buckets are %v",
+					tc.min, tc.max, tc.nb, n, i, bounds(h))
+			}
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	h := New(0, 9, 2)
+	for _, n := range []int64{-100, -1, 0, 4, 5, 9, 10, 1000} {
+		h.Add(n)
+	}
+	h.AddWeighted(3, 5)
+	h.AddWeighted(-3, 2)
+	if got, want := counts(h), []int{4, 7, 2, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Counts after adding = %v; want %v", got, want)
+	}
+
+	e := h.Empty()
+	if got, want := counts(e), []int{0, 0, 0, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Empty() counts = %v; want %v", got, want)
+	}
+	if got, want := bounds(e), bounds(h); !reflect.DeepEqual(got, want) {
+		t.Errorf("Empty() buckets = %v; want %v", got, want)
+	}
+	e.Add(7)
+	if got, want := counts(h), []int{4, 7, 2, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Adding to Empty() changed original counts to %v; want %v", got, want)
+	}
+}
+
+func TestNewQuantile(t *testing.T) {
+	for _, tc := range []struct {
+		vals []int64
+		nb   int
+		want [][2]int64
+	}{
+		{nil, 4, [][2]int64{{0, 0}}},
+		{[]int64{5}, 3, [][2]int64{{5, 5}}},
+		{[]int64{8, 1, 2, 7, 3, 6, 4, 5}, 4, [][2]int64{{1, 2}, {3, 4}, {5, 6}, {7, 8}}},
+		{[]int64{1, 1, 1, 1, 1, 1, 2, 100}, 4, [][2]int64{{1, 1}, {2, 100}}},
+		{[]int64{3, 3, 3}, 5, [][2]int64{{3, 3}}},
+		{[]int64{1, 2, 3}, 0, [][2]int64{{1, 3}}},
+		{[]int64{1, 2, 3}, -1, [][2]int64{{1, 3}}},
+		{nil, 0, [][2]int64{{0, 0}}},
+	} {
+		h := NewQuantile(tc.vals, tc.nb)
+		if got := bounds(h); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("NewQuantile(%v, %v) buckets = %v; want %v", tc.vals, tc.nb, got, tc.want)
+		}
+		for _, v := range tc.vals {
+			if i := h.Index(v); i < 0 || i >= len(h.Buckets) {
+				t.Errorf("NewQuantile(%v, %v).Index(%v) = %v", tc.vals, tc.nb, v, i)
+			}
+		}
+	}
+}
+
+func TestNewMoreBucketsThanValues(t *testing.T) {
+	h := New(0, 2, 5)
+	for _, n := range []int64{0, 1, 1, 2} {
+		h.Add(n)
+	}
+	if got, want := h.Labels(), []string{"<0", "0", "1", "2", ">2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Labels() = %q; want %q", got, want)
+	}
+	if got, want := counts(h), []int{0, 1, 2, 1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Counts after adding = %v; want %v", got, want)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	h := New(1, 10, 3)
+	h.Buckets[1].Max = h.Buckets[1].Min
+	h.Buckets[2].Min = h.Buckets[1].Max + 1
+	if got, want := h.Labels(), []string{"<1", "1-3", "4", "5-10", ">10"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Labels() = %q; want %q", got, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	h := New(0, 9, 2)
+	for _, n := range []int64{-1, 1, 2, 7, 20, 21} {
+		h.Add(n)
+	}
+	for _, tc := range []struct {
+		style Style
+		want  []string
+	}{
+		{Style{BarWidth: 4}, []string{
+			" <0 |## 1",
+			"0-4 |#### 2",
+			"5-9 |## 1",
+			" >9 |#### 2",
+		}},
+		{Style{BarWidth: 4, Percent: true}, []string{
+			" <0 |## 1 ( 16.7%)",
+			"0-4 |#### 2 ( 33.3%)",
+			"5-9 |## 1 ( 16.7%)",
+			" >9 |#### 2 ( 33.3%)",
+		}},
+		{Style{BarWidth: 1, Unicode: true}, []string{
+			" <0 |▌ 1",
+			"0-4 |█ 2",
+			"5-9 |▌ 1",
+			" >9 |█ 2",
+		}},
+		{Style{BarWidth: 2, Color: true}, []string{
+			" <0 |" + barColor + "#" + resetColor + " 1",
+			"0-4 |" + barColor + "##" + resetColor + " 2",
+			"5-9 |" + barColor + "#" + resetColor + " 1",
+			" >9 |" + barColor + "##" + resetColor + " 2",
+		}},
+		// The bar fills the width left after the label and the largest count.
+		{Style{Width: 20}, []string{
+			" <0 |####### 1",
+			"0-4 |############# 2",
+			"5-9 |####### 1",
+			" >9 |############# 2",
+		}},
+		// Bars are never narrower than minBarWidth.
+		{Style{Width: 5}, []string{
+			" <0 |##### 1",
+			"0-4 |########## 2",
+			"5-9 |##### 1",
+			" >9 |########## 2",
+		}},
+	} {
+		var b strings.Builder
+		if err := h.Write(&b, 0, &tc.style); err != nil {
+			t.Errorf("Write(%+v) failed: %v", tc.style, err)
+		} else if got, want := b.String(), strings.Join(tc.want, "\n")+"\n"; got != want {
+			t.Errorf("Write(%+v) wrote:\n%s\nwant:\n%s", tc.style, got, want)
+		}
+	}
+}
+
+func TestWriteOmitsEmptyFlows(t *testing.T) {
+	// Underflow and overflow lines are omitted when they're empty, but empty buckets
+	// are still written (without color codes), and labelWidth is respected.
+	h := New(0, 9, 2)
+	h.Add(3)
+	var b strings.Builder
+	if err := h.Write(&b, 5, &Style{BarWidth: 3, Color: true}); err != nil {
+		t.Fatal("Write failed: ", err)
+	}
+	want := "  0-4 |" + barColor + "###" + resetColor + " 1\n" +
+		"  5-9 | 0\n"
+	if got := b.String(); got != want {
+		t.Errorf("Write wrote:\n%q\nwant:\n%q", got, want)
+	}
+}
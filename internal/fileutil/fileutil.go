@@ -0,0 +1,54 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+// Package fileutil contains file-related code shared by this module's packages.
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// OutputFile is a file that is written at a temporary path and moved to
+// its final path once writing is complete, so that partially-written files
+// aren't left behind on failure.
+type OutputFile struct {
+	*os.File
+	path string // final path
+}
+
+// CreateOutputFile creates a new OutputFile that will be moved to p,
+// creating p's parent directories if needed.
+func CreateOutputFile(p string) (*OutputFile, error) {
+	dir, base := filepath.Split(p)
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(dir, "."+base+".*")
+	if err != nil {
+		return nil, err
+	}
+	return &OutputFile{f, p}, nil
+}
+
+// Commit closes the file and moves it to its final path.
+func (of *OutputFile) Commit() error {
+	if err := of.Chmod(0644); err != nil {
+		of.Abort()
+		return err
+	}
+	if err := of.Close(); err != nil {
+		os.Remove(of.Name())
+		return err
+	}
+	return os.Rename(of.Name(), of.path)
+}
+
+// Abort closes and deletes the file.
+func (of *OutputFile) Abort() {
+	of.Close()
+	os.Remove(of.Name())
+}
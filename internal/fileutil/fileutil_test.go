@@ -0,0 +1,76 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// listDir returns the names of the files in dir.
+func listDir(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestOutputFile_Commit(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "sub/out.txt")
+	of, err := CreateOutputFile(p)
+	if err != nil {
+		t.Fatal("CreateOutputFile failed: ", err)
+	}
+	if _, err := of.WriteString("hello\n"); err != nil {
+		t.Fatal("Write failed: ", err)
+	}
+	if _, err := os.Stat(p); !os.IsNotExist(err) {
+		t.Errorf("%v exists before Commit (err = %v)", p, err)
+	}
+	if err := of.Commit(); err != nil {
+		t.Fatal("Commit failed: ", err)
+	}
+	if b, err := os.ReadFile(p); err != nil {
+		t.Error("Failed reading committed file: ", err)
+	} else if string(b) != "hello\n" {
+		t.Errorf("Committed file contains %q; want %q", b, "hello\n")
+	}
+	if fi, err := os.Stat(p); err == nil && fi.Mode().Perm() != 0644 {
+		t.Errorf("Committed file has mode %v; want %v", fi.Mode().Perm(), os.FileMode(0644))
+	}
+	if names := listDir(t, filepath.Dir(p)); len(names) != 1 {
+		t.Errorf("Dir contains %q after Commit; want only %q", names, filepath.Base(p))
+	}
+}
+
+func TestOutputFile_Abort(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "out.txt")
+	if err := os.WriteFile(p, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	of, err := CreateOutputFile(p)
+	if err != nil {
+		t.Fatal("CreateOutputFile failed: ", err)
+	}
+	if _, err := of.WriteString("partial"); err != nil {
+		t.Fatal("Write failed: ", err)
+	}
+	of.Abort()
+	if b, err := os.ReadFile(p); err != nil {
+		t.Error("Failed reading original file: ", err)
+	} else if string(b) != "old\n" {
+		t.Errorf("Original file contains %q after Abort; want %q", b, "old\n")
+	}
+	if names := listDir(t, dir); len(names) != 1 {
+		t.Errorf("Dir contains %q after Abort; want only %q", names, filepath.Base(p))
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+// Package logutil implements leveled logging shared by this module's commands.
+package logutil
+
+import (
+	"errors"
+	"flag"
+	"log"
+)
+
+// Level specifies which messages are logged.
+type Level int
+
+const (
+	Error Level = iota // only errors
+	Info               // warnings and progress messages
+	Debug              // detailed diagnostic messages
+)
+
+// curLevel is the current logging level.
+// Errors are always logged via the log package or written to stderr directly.
+var curLevel = Info
+
+// SetLevel sets the current logging level.
+func SetLevel(l Level) { curLevel = l }
+
+// Infof logs an informational message if the current level is at least Info.
+func Infof(format string, args ...interface{}) {
+	if curLevel >= Info {
+		log.Printf(format, args...)
+	}
+}
+
+// Debugf logs a diagnostic message if the current level is at least Debug.
+func Debugf(format string, args ...interface{}) {
+	if curLevel >= Debug {
+		log.Printf(format, args...)
+	}
+}
+
+// Flags holds the values of the -q and -v flags.
+type Flags struct {
+	quiet, verbose bool
+}
+
+// AddFlags registers -q and -v flags in fs.
+// Apply should be called after fs is parsed.
+func AddFlags(fs *flag.FlagSet) *Flags {
+	var f Flags
+	fs.BoolVar(&f.quiet, "q", false, "Only log errors")
+	fs.BoolVar(&f.verbose, "v", false, "Log detailed diagnostic messages")
+	return &f
+}
+
+// Apply sets the current logging level from the flags.
+// An error is returned if the flags conflict.
+func (f *Flags) Apply() error {
+	switch {
+	case f.quiet && f.verbose:
+		return errors.New("-q and -v are mutually exclusive")
+	case f.quiet:
+		SetLevel(Error)
+	case f.verbose:
+		SetLevel(Debug)
+	default:
+		SetLevel(Info)
+	}
+	return nil
+}
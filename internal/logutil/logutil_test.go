@@ -0,0 +1,70 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package logutil
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestFlags(t *testing.T) {
+	defer SetLevel(Info)
+	for _, tc := range []struct {
+		args []string
+		want Level
+		err  bool
+	}{
+		{nil, Info, false},
+		{[]string{"-q"}, Error, false},
+		{[]string{"-v"}, Debug, false},
+		{[]string{"-q", "-v"}, Info, true},
+	} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		f := AddFlags(fs)
+		if err := fs.Parse(tc.args); err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tc.args, err)
+		}
+		SetLevel(Info)
+		if err := f.Apply(); err != nil && !tc.err {
+			t.Errorf("Apply() with %q failed: %v", tc.args, err)
+		} else if err == nil && tc.err {
+			t.Errorf("Apply() with %q unexpectedly succeeded", tc.args)
+		} else if curLevel != tc.want {
+			t.Errorf("Apply() with %q set level %v; want %v", tc.args, curLevel, tc.want)
+		}
+	}
+}
+
+func TestLevels(t *testing.T) {
+	var b bytes.Buffer
+	log.SetOutput(&b)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+		SetLevel(Info)
+	}()
+
+	for _, tc := range []struct {
+		level Level
+		want  string
+	}{
+		{Error, ""},
+		{Info, "info\n"},
+		{Debug, "info\ndebug\n"},
+	} {
+		b.Reset()
+		SetLevel(tc.level)
+		Infof("info")
+		Debugf("debug")
+		if got := b.String(); got != tc.want {
+			t.Errorf("Level %v logged %q; want %q", tc.level, got, tc.want)
+		}
+	}
+}
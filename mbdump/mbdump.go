@@ -0,0 +1,253 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+// Package mbdump reads tables from MusicBrainz database dumps, i.e. .tar.bz2
+// archives like mbdump-edit.tar.bz2 containing PostgreSQL tables in text format.
+//
+// A typical use looks like this:
+//
+//	ar, err := mbdump.Open("mbdump-edit.tar.bz2")
+//	if err != nil {
+//		...
+//	}
+//	defer ar.Close()
+//	err = ar.ForEachRow(ctx, []string{mbdump.TableDir + "edit"}, func(_ string, row *mbdump.Row) error {
+//		editor, created := row.Int32(1), row.Time(5)
+//		...
+//		return nil
+//	})
+package mbdump
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/bzip2"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// TableDir is the directory containing tables within archives.
+	TableDir = "mbdump/"
+	// TimestampFile is the name of the archive member identifying when the dump was created.
+	TimestampFile = "TIMESTAMP"
+	// TimeLayout is the format of timestamps in PostgreSQL dumps.
+	TimeLayout = "2006-01-02 15:04:05.999-07"
+	// Null is the value of null columns in PostgreSQL dumps.
+	Null = `\N`
+)
+
+// ErrNoTimestamp is returned by Archive.DumpTime if the archive doesn't contain TimestampFile.
+var ErrNoTimestamp = errors.New("no " + TimestampFile + " in archive")
+
+// errNotFound is wrapped by errors returned by forEachChunk for missing members.
+var errNotFound = errors.New("not found in archive")
+
+const (
+	logFreq = 5 * time.Second
+	mb      = 1024 * 1024
+)
+
+// Archive is an open .tar.bz2 dump archive.
+//
+// Archives can only be read sequentially, so each call to ForEachRow or CopyTable
+// reads from the beginning of the file. An Archive is not safe for concurrent use.
+type Archive struct {
+	// Infof and Debugf are used to log progress and diagnostic messages, respectively.
+	// Nothing is logged if they are nil.
+	Infof, Debugf func(format string, args ...interface{})
+
+	f *os.File
+}
+
+// Open opens the .tar.bz2 file at path p.
+func Open(p string) (*Archive, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	return &Archive{f: f}, nil
+}
+
+// Close closes the underlying file.
+func (ar *Archive) Close() error {
+	return ar.f.Close()
+}
+
+func (ar *Archive) infof(format string, args ...interface{}) {
+	if ar.Infof != nil {
+		ar.Infof(format, args...)
+	}
+}
+
+func (ar *Archive) debugf(format string, args ...interface{}) {
+	if ar.Debugf != nil {
+		ar.Debugf(format, args...)
+	}
+}
+
+// forEachChunk reads the first table within ar whose name appears in names. Tables may
+// be split across multiple chunks (see isTableMember), in which case fn is invoked for
+// each chunk. fn receives the table's name, the chunk's header, and a reader positioned
+// at the start of the chunk's contents. Reads fail with ctx's error once ctx is done.
+//
+// Header members outside of TableDir (e.g. TimestampFile) precede the tables in dumps,
+// so if none of names are in TableDir, the search stops at the first table.
+func (ar *Archive) forEachChunk(ctx context.Context, names []string,
+	fn func(name string, head *tar.Header, r io.Reader) error) error {
+	if _, err := ar.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	var wantTable bool
+	for _, name := range names {
+		wantTable = wantTable || strings.HasPrefix(name, TableDir)
+	}
+	tr := tar.NewReader(bzip2.NewReader(&ctxReader{ctx: ctx, r: ar.f}))
+	var table string // matched name from names
+	for {
+		head, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if head.Typeflag != tar.TypeReg {
+			continue
+		}
+		if table == "" && !wantTable && strings.HasPrefix(head.Name, TableDir) {
+			break
+		}
+		if table == "" {
+			for _, name := range names {
+				if isTableMember(head.Name, name) {
+					table = name
+					break
+				}
+			}
+			if table == "" {
+				ar.debugf("Skipping %v", head.Name)
+				continue
+			}
+		} else if !isTableMember(head.Name, table) {
+			// Chunks are written consecutively, so stop instead of reading
+			// through the (possibly much larger) tables that follow.
+			break
+		}
+		if err := fn(table, head, tr); err != nil {
+			return err
+		}
+	}
+	if table == "" {
+		return fmt.Errorf("%q %w", strings.Join(names, `" or "`), errNotFound)
+	}
+	return nil
+}
+
+// isTableMember returns true if the archive member named member holds the named table.
+// In addition to an exact match, chunks like "mbdump/edit.001" or "mbdump/edit/001"
+// are accepted for a table named "mbdump/edit".
+func isTableMember(member, table string) bool {
+	if !strings.HasPrefix(member, table) {
+		return false
+	}
+	rest := member[len(table):]
+	return rest == "" || rest[0] == '.' || rest[0] == '/'
+}
+
+// ForEachRow reads the first table within ar whose name appears in names, e.g.
+// TableDir+"editor_sanitised" or TableDir+"editor". fn is invoked with the table's
+// name and each row from the table, including rows from all of the table's chunks.
+// Reading stops if fn returns an error, if an accessor fails to parse a column, or if
+// ctx is done.
+func (ar *Archive) ForEachRow(ctx context.Context, names []string,
+	fn func(table string, row *Row) error) error {
+	return ar.forEachChunk(ctx, names, func(name string, head *tar.Header, tr io.Reader) error {
+		size := head.Size
+		ar.infof("Processing %v (%0.1f MB)", head.Name, float64(size)/mb)
+		logTime := time.Now()
+
+		r := &countReader{r: tr}
+		sc := bufio.NewScanner(r)
+		var nrows int
+		for sc.Scan() {
+			row := newRow(sc.Text())
+			err := fn(name, row)
+			if err == nil {
+				err = row.err
+			}
+			if err != nil {
+				return fmt.Errorf("bad row %q: %v", sc.Text(), err)
+			}
+
+			nrows++
+			if now := time.Now(); now.Sub(logTime) > logFreq {
+				ar.infof("Read %4.1f%% (%d rows, %0.1f MB)",
+					float64(r.nbytes)/float64(size)*100,
+					nrows, float64(r.nbytes)/mb)
+				logTime = now
+			}
+		}
+		ar.debugf("Read %d rows from %v", nrows, head.Name)
+		return sc.Err()
+	})
+}
+
+// CopyTable copies the named table from ar to w.
+// The TableDir prefix is added to table if it is not already present.
+// Rows are written as-is, i.e. as tab-separated values in PostgreSQL's text format.
+func (ar *Archive) CopyTable(ctx context.Context, w io.Writer, table string) error {
+	name := table
+	if !strings.HasPrefix(name, TableDir) {
+		name = TableDir + name
+	}
+	return ar.forEachChunk(ctx, []string{name}, func(_ string, _ *tar.Header, r io.Reader) error {
+		_, err := io.Copy(w, r)
+		return err
+	})
+}
+
+// DumpTime reads ar's TimestampFile, which identifies the point at which the dump was created.
+// ErrNoTimestamp is returned if the file isn't present.
+func (ar *Archive) DumpTime(ctx context.Context) (time.Time, error) {
+	var t time.Time
+	err := ar.ForEachRow(ctx, []string{TimestampFile}, func(_ string, row *Row) error {
+		t = row.Time(0)
+		return nil
+	})
+	if errors.Is(err, errNotFound) {
+		return t, ErrNoTimestamp
+	} else if err == nil && t.IsZero() {
+		err = fmt.Errorf("no timestamp in %v", ar.f.Name())
+	}
+	return t, err
+}
+
+// countReader wraps an io.Reader and counts the number of bytes that have been read.
+type countReader struct {
+	r      io.Reader
+	nbytes int64
+}
+
+func (cr *countReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.nbytes += int64(n)
+	return n, err
+}
+
+// ctxReader wraps an io.Reader and fails with its context's error once the context is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *ctxReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
@@ -0,0 +1,223 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbdump
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// member describes a file or directory within a test archive.
+type member struct {
+	name string
+	data string // ignored for directories
+	dir  bool
+}
+
+// writeArchive writes a .tar.bz2 file containing members to a temp dir and
+// returns its path. The test is skipped if the bzip2 program isn't available,
+// since the standard library can't write bzip2 data.
+func writeArchive(t *testing.T, members []member) string {
+	bz, err := exec.LookPath("bzip2")
+	if err != nil {
+		t.Skip("bzip2 not found: ", err)
+	}
+
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	for _, m := range members {
+		head := &tar.Header{Name: m.name, Mode: 0644, Size: int64(len(m.data)), Typeflag: tar.TypeReg}
+		if m.dir {
+			head.Mode, head.Size, head.Typeflag = 0755, 0, tar.TypeDir
+		}
+		if err := tw.WriteHeader(head); err != nil {
+			t.Fatal(err)
+		}
+		if !m.dir {
+			if _, err := tw.Write([]byte(m.data)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(bz, "-c")
+	cmd.Stdin = &b
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal("bzip2 failed: ", err)
+	}
+	p := filepath.Join(t.TempDir(), "mbdump-test.tar.bz2")
+	if err := os.WriteFile(p, out, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// openTestArchive writes an archive containing members and opens it.
+func openTestArchive(t *testing.T, members []member) *Archive {
+	ar, err := Open(writeArchive(t, members))
+	if err != nil {
+		t.Fatal("Open failed: ", err)
+	}
+	t.Cleanup(func() { ar.Close() })
+	return ar
+}
+
+// testMembers contains a table split into chunks with both naming schemes,
+// surrounded by other tables whose names share its prefix.
+var testMembers = []member{
+	{name: TimestampFile, data: "2022-01-02 03:04:05.678+00\n"},
+	{name: "mbdump", dir: true},
+	{name: "mbdump/edit_data", data: "1\tdata\n"},
+	{name: "mbdump/edit.001", data: "1\tfirst\n2\tsecond\n"},
+	{name: "mbdump/edit", dir: true},
+	{name: "mbdump/edit/002", data: "3\tthird\n"},
+	{name: "mbdump/edit_note", data: "1\tnote\n"},
+	{name: "mbdump/editor", data: "5\talice\n"},
+}
+
+// readRows returns the tables and first two columns of each row read by
+// ar.ForEachRow for names.
+func readRows(ctx context.Context, ar *Archive, names []string) (tables, rows []string, err error) {
+	err = ar.ForEachRow(ctx, names, func(table string, row *Row) error {
+		tables = append(tables, table)
+		rows = append(rows, row.String(0)+":"+row.String(1))
+		return nil
+	})
+	return tables, rows, err
+}
+
+func TestArchive_ForEachRow_Chunks(t *testing.T) {
+	ar := openTestArchive(t, testMembers)
+	tables, rows, err := readRows(context.Background(), ar,
+		[]string{TableDir + "edit_sanitised", TableDir + "edit"})
+	if err != nil {
+		t.Fatal("ForEachRow failed: ", err)
+	}
+	if want := []string{"1:first", "2:second", "3:third"}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("ForEachRow read rows %q; want %q", rows, want)
+	}
+	for _, tbl := range tables {
+		if tbl != TableDir+"edit" {
+			t.Errorf("ForEachRow passed table %q; want %q", tbl, TableDir+"edit")
+		}
+	}
+
+	// The archive should be reread from the beginning on each call,
+	// and tables should only be matched by their full names.
+	if _, rows, err := readRows(context.Background(), ar, []string{TableDir + "edit_note"}); err != nil {
+		t.Error("ForEachRow failed for edit_note: ", err)
+	} else if want := []string{"1:note"}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("ForEachRow read rows %q for edit_note; want %q", rows, want)
+	}
+}
+
+func TestArchive_ForEachRow_FirstMatch(t *testing.T) {
+	// The first table present in the archive is read, regardless of the order of names.
+	ar := openTestArchive(t, testMembers)
+	tables, rows, err := readRows(context.Background(), ar,
+		[]string{TableDir + "editor", TableDir + "edit_data"})
+	if err != nil {
+		t.Fatal("ForEachRow failed: ", err)
+	}
+	if want := []string{TableDir + "edit_data"}; !reflect.DeepEqual(tables, want) {
+		t.Errorf("ForEachRow read tables %q; want %q", tables, want)
+	}
+	if want := []string{"1:data"}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("ForEachRow read rows %q; want %q", rows, want)
+	}
+}
+
+func TestArchive_ForEachRow_Errors(t *testing.T) {
+	ar := openTestArchive(t, testMembers)
+	ctx := context.Background()
+
+	if _, _, err := readRows(ctx, ar, []string{TableDir + "missing"}); err == nil {
+		t.Error("ForEachRow unexpectedly succeeded for missing table")
+	} else if !strings.Contains(err.Error(), "not found") {
+		t.Errorf("ForEachRow returned %q for missing table; want not-found error", err)
+	}
+
+	// Errors returned by fn should stop reading.
+	fnErr := errors.New("intentional")
+	var nrows int
+	if err := ar.ForEachRow(ctx, []string{TableDir + "edit"}, func(string, *Row) error {
+		nrows++
+		return fnErr
+	}); err == nil || !strings.Contains(err.Error(), fnErr.Error()) {
+		t.Errorf("ForEachRow returned %v; want %q", err, fnErr)
+	}
+	if nrows != 1 {
+		t.Errorf("fn called %d time(s) after returning error; want 1", nrows)
+	}
+
+	// Errors latched by accessors should also stop reading, even if fn succeeds.
+	nrows = 0
+	if err := ar.ForEachRow(ctx, []string{TableDir + "edit"}, func(_ string, row *Row) error {
+		nrows++
+		row.Int32(1) // not an integer
+		return nil
+	}); err == nil || !strings.Contains(err.Error(), "bad row") {
+		t.Errorf("ForEachRow returned %v for bad column; want bad row error", err)
+	}
+	if nrows != 1 {
+		t.Errorf("fn called %d time(s) after accessor error; want 1", nrows)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, err := readRows(canceled, ar, []string{TableDir + "edit"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("ForEachRow returned %v for canceled context; want %v", err, context.Canceled)
+	}
+}
+
+func TestArchive_CopyTable(t *testing.T) {
+	ar := openTestArchive(t, testMembers)
+	for _, table := range []string{"edit", TableDir + "edit"} {
+		var b bytes.Buffer
+		if err := ar.CopyTable(context.Background(), &b, table); err != nil {
+			t.Errorf("CopyTable(%q) failed: %v", table, err)
+		} else if got, want := b.String(), "1\tfirst\n2\tsecond\n3\tthird\n"; got != want {
+			t.Errorf("CopyTable(%q) wrote %q; want %q", table, got, want)
+		}
+	}
+	if err := ar.CopyTable(context.Background(), &bytes.Buffer{}, "missing"); err == nil {
+		t.Error("CopyTable unexpectedly succeeded for missing table")
+	}
+}
+
+func TestArchive_DumpTime(t *testing.T) {
+	ar := openTestArchive(t, testMembers)
+	if got, err := ar.DumpTime(context.Background()); err != nil {
+		t.Error("DumpTime failed: ", err)
+	} else if want := time.Date(2022, 1, 2, 3, 4, 5, 678000000, time.UTC); !got.Equal(want) {
+		t.Errorf("DumpTime returned %v; want %v", got, want)
+	}
+
+	ar = openTestArchive(t, []member{{name: TimestampFile, data: ""}})
+	if _, err := ar.DumpTime(context.Background()); err == nil {
+		t.Error("DumpTime unexpectedly succeeded for empty timestamp")
+	}
+
+	// The search should stop at the first table rather than reading the whole archive.
+	ar = openTestArchive(t, []member{
+		{name: "mbdump/edit", data: "1\tfirst\n"},
+		{name: TimestampFile, data: "2022-01-02 03:04:05.678+00\n"},
+	})
+	if _, err := ar.DumpTime(context.Background()); !errors.Is(err, ErrNoTimestamp) {
+		t.Errorf("DumpTime returned %v for missing timestamp; want %v", err, ErrNoTimestamp)
+	}
+}
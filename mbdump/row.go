@@ -0,0 +1,90 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbdump
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Row contains the tab-separated columns from a single line of a table.
+//
+// Its accessors take 0-based column indexes. If an accessor fails (e.g. because
+// the column is missing or can't be parsed), it returns the zero value and the
+// error is saved and returned by Err. Subsequent calls also return zero values.
+type Row struct {
+	cols []string
+	err  error
+}
+
+func newRow(ln string) *Row {
+	return &Row{cols: strings.Split(ln, "\t")}
+}
+
+// Len returns the number of columns in the row.
+func (r *Row) Len() int { return len(r.cols) }
+
+// Err returns the first error encountered by an accessor.
+func (r *Row) Err() error { return r.err }
+
+// String returns the raw value of column i.
+// Null values are returned as Null.
+func (r *Row) String(i int) string {
+	if r.err != nil {
+		return ""
+	}
+	if i < 0 {
+		r.err = fmt.Errorf("invalid column %d requested", i)
+		return ""
+	}
+	if i >= len(r.cols) {
+		r.err = fmt.Errorf("column %d requested but only have %d", i, len(r.cols))
+		return ""
+	}
+	return r.cols[i]
+}
+
+// Null returns true if column i is null.
+func (r *Row) Null(i int) bool {
+	return r.String(i) == Null && r.err == nil
+}
+
+// Int32 parses column i as a 32-bit integer, e.g. an INTEGER or SMALLINT column.
+func (r *Row) Int32(i int) int32 {
+	s := r.String(i)
+	if r.err != nil {
+		return 0
+	}
+	var v int64
+	v, r.err = strconv.ParseInt(s, 10, 32)
+	return int32(v)
+}
+
+// Bool parses column i as a BOOLEAN column.
+func (r *Row) Bool(i int) bool {
+	switch s := r.String(i); s {
+	case "t":
+		return true
+	case "f":
+		return false
+	default:
+		if r.err == nil {
+			r.err = fmt.Errorf("bad boolean %q in column %d", s, i)
+		}
+		return false
+	}
+}
+
+// Time parses column i as a TIMESTAMP WITH TIME ZONE column.
+func (r *Row) Time(i int) time.Time {
+	s := r.String(i)
+	if r.err != nil {
+		return time.Time{}
+	}
+	var t time.Time
+	t, r.err = time.Parse(TimeLayout, s)
+	return t
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbdump
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRow_Accessors(t *testing.T) {
+	row := newRow("12\t-3\tt\tf\t2020-03-04 05:06:07.89+00\t\\N\tabc")
+	if n := row.Len(); n != 7 {
+		t.Errorf("Len() = %v; want 7", n)
+	}
+	if v := row.Int32(0); v != 12 {
+		t.Errorf("Int32(0) = %v; want 12", v)
+	}
+	if v := row.Int32(1); v != -3 {
+		t.Errorf("Int32(1) = %v; want -3", v)
+	}
+	if v := row.Bool(2); v != true {
+		t.Errorf("Bool(2) = %v; want true", v)
+	}
+	if v := row.Bool(3); v != false {
+		t.Errorf("Bool(3) = %v; want false", v)
+	}
+	if v, want := row.Time(4), time.Date(2020, 3, 4, 5, 6, 7, 890000000, time.UTC); !v.Equal(want) {
+		t.Errorf("Time(4) = %v; want %v", v, want)
+	}
+	if !row.Null(5) {
+		t.Error("Null(5) = false; want true")
+	}
+	if row.Null(6) {
+		t.Error("Null(6) = true; want false")
+	}
+	if v := row.String(5); v != Null {
+		t.Errorf("String(5) = %q; want %q", v, Null)
+	}
+	if v := row.String(6); v != "abc" {
+		t.Errorf("String(6) = %q; want %q", v, "abc")
+	}
+	if err := row.Err(); err != nil {
+		t.Error("Err() = ", err)
+	}
+}
+
+func TestRow_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		fn   func(r *Row)
+	}{
+		{"missing column", func(r *Row) { r.String(5) }},
+		{"negative column", func(r *Row) { r.String(-1) }},
+		{"negative Int32 column", func(r *Row) { r.Int32(-2) }},
+		{"bad int", func(r *Row) { r.Int32(2) }},
+		{"int out of range", func(r *Row) { r.Int32(1) }},
+		{"bad bool", func(r *Row) { r.Bool(0) }},
+		{"empty bool", func(r *Row) { r.Bool(3) }},
+		{"bad time", func(r *Row) { r.Time(2) }},
+		{"null int", func(r *Row) { r.Int32(4) }},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			row := newRow("1\t9999999999\tabc\t\t\\N")
+			tc.fn(row)
+			err := row.Err()
+			if err == nil {
+				t.Fatal("Err() = nil after failed accessor")
+			}
+
+			// Later calls should return zero values and preserve the first error,
+			// even for columns that would otherwise be valid.
+			if v := row.Int32(0); v != 0 {
+				t.Errorf("Int32(0) = %v after error; want 0", v)
+			}
+			if v := row.String(0); v != "" {
+				t.Errorf("String(0) = %q after error; want empty", v)
+			}
+			if row.Null(4) {
+				t.Error("Null(4) = true after error; want false")
+			}
+			if v := row.Time(0); !v.IsZero() {
+				t.Errorf("Time(0) = %v after error; want zero", v)
+			}
+			if got := row.Err(); got != err {
+				t.Errorf("Err() = %v after more calls; want %v", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbdump
+
+import "fmt"
+
+// Table describes the columns of a table within dumps.
+//
+// The MusicBrainz database schema lives here:
+// https://github.com/metabrainz/musicbrainz-server/blob/master/admin/sql/CreateTables.sql
+type Table struct {
+	Name    string   // e.g. "edit"
+	Columns []string // column names in order
+}
+
+// Path returns the table's path within archives, e.g. "mbdump/edit".
+func (t *Table) Path() string { return TableDir + t.Name }
+
+// Col returns the 0-based index of the named column. It panics if the column
+// doesn't exist, so it should only be called with constant names.
+func (t *Table) Col(name string) int {
+	for i, c := range t.Columns {
+		if c == name {
+			return i
+		}
+	}
+	panic(fmt.Sprintf("no %q column in %v table", name, t.Name))
+}
+
+// EditorTable describes the editor table. Public dumps contain the editor_sanitised
+// table instead, which has the same columns (with private values like email addresses
+// cleared). Old dumps may also be missing the final 'deleted' column.
+//
+//	CREATE TABLE editor
+//	(
+//		id                  SERIAL,
+//		name                VARCHAR(64) NOT NULL,
+//		privs               INTEGER DEFAULT 0,
+//		email               VARCHAR(64) DEFAULT NULL,
+//		website             VARCHAR(255) DEFAULT NULL,
+//		bio                 TEXT DEFAULT NULL,
+//		member_since        TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+//		email_confirm_date  TIMESTAMP WITH TIME ZONE,
+//		last_login_date     TIMESTAMP WITH TIME ZONE DEFAULT now(),
+//		last_updated        TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+//		birth_date          DATE,
+//		gender              INTEGER, -- references gender.id
+//		area                INTEGER, -- references area.id
+//		password            VARCHAR(128) NOT NULL,
+//		ha1                 CHAR(32) NOT NULL,
+//		deleted             BOOLEAN NOT NULL DEFAULT FALSE
+//	);
+var EditorTable = &Table{"editor", []string{
+	"id", "name", "privs", "email", "website", "bio", "member_since",
+	"email_confirm_date", "last_login_date", "last_updated", "birth_date",
+	"gender", "area", "password", "ha1", "deleted",
+}}
+
+// EditTable describes the edit table.
+//
+//	CREATE TABLE edit
+//	(
+//		id                  SERIAL,
+//		editor              INTEGER NOT NULL, -- references editor.id
+//		type                SMALLINT NOT NULL,
+//		status              SMALLINT NOT NULL,
+//		autoedit            SMALLINT NOT NULL DEFAULT 0,
+//		open_time            TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+//		close_time           TIMESTAMP WITH TIME ZONE,
+//		expire_time          TIMESTAMP WITH TIME ZONE NOT NULL,
+//		language            INTEGER, -- references language.id
+//		quality             SMALLINT NOT NULL DEFAULT 1
+//	);
+var EditTable = &Table{"edit", []string{
+	"id", "editor", "type", "status", "autoedit", "open_time", "close_time",
+	"expire_time", "language", "quality",
+}}
+
+// EditNoteTable describes the edit_note table.
+//
+//	CREATE TABLE edit_note
+//	(
+//		id                  SERIAL,
+//		editor              INTEGER NOT NULL, -- references editor.id
+//		edit                INTEGER NOT NULL, -- references edit.id
+//		text                TEXT NOT NULL,
+//		post_time            TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+//	);
+var EditNoteTable = &Table{"edit_note", []string{
+	"id", "editor", "edit", "text", "post_time",
+}}
+
+// VoteTable describes the vote table.
+//
+//	CREATE TABLE vote
+//	(
+//		id                  SERIAL,
+//		editor              INTEGER NOT NULL, -- references editor.id
+//		edit                INTEGER NOT NULL, -- references edit.id
+//		vote                SMALLINT NOT NULL,
+//		vote_time            TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+//		superseded          BOOLEAN NOT NULL DEFAULT FALSE
+//	);
+var VoteTable = &Table{"vote", []string{
+	"id", "editor", "edit", "vote", "vote_time", "superseded",
+}}
+
+// Tables lists the tables described by this package.
+var Tables = []*Table{EditorTable, EditTable, EditNoteTable, VoteTable}
@@ -0,0 +1,35 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbdump
+
+import "testing"
+
+func TestTable(t *testing.T) {
+	if got, want := EditTable.Path(), "mbdump/edit"; got != want {
+		t.Errorf("EditTable.Path() = %q; want %q", got, want)
+	}
+	for _, tc := range []struct {
+		table *Table
+		col   string
+		want  int
+	}{
+		{EditorTable, "id", 0},
+		{EditorTable, "member_since", 6},
+		{EditorTable, "deleted", 15},
+		{EditTable, "open_time", 5},
+		{EditNoteTable, "post_time", 4},
+		{VoteTable, "superseded", 5},
+	} {
+		if got := tc.table.Col(tc.col); got != tc.want {
+			t.Errorf("%v.Col(%q) = %d; want %d", tc.table.Name, tc.col, got, tc.want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Col didn't panic for missing column")
+		}
+	}()
+	EditTable.Col("bogus")
+}
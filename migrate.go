@@ -0,0 +1,50 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbstats
+
+import "fmt"
+
+// StatsVersion is the version of the format of the stats files written by this
+// package. It is recorded in Metadata.Version and should be incremented whenever
+// the format changes, with an entry added to editorStatsMigrations if records from
+// older files need to be upgraded when they're read.
+//
+// Version history:
+//
+//	0: edit counts keyed by numeric edit type IDs (files written before versioning)
+//	1: edit counts keyed by edit type names
+//	2: editor information moved from editor stats records to EditorsFile
+const StatsVersion = 2
+
+// editorStatsMigrations upgrades EditorStats objects decoded from older stats files.
+// The function keyed by version v upgrades an object from version v to v+1.
+//
+// No versions currently need upgrades, since older records decode directly into
+// the current EditorStats type: version 0 records key edit counts by numeric IDs,
+// which EditType.UnmarshalText accepts, and version 1 records contain their own
+// editor information, which is used as-is since their directories lack an EditorsFile.
+var editorStatsMigrations = map[int]func(es *EditorStats){}
+
+// CheckStatsVersion returns an error if version, as recorded in Metadata.Version,
+// can't be read by this package.
+func CheckStatsVersion(version int) error {
+	if version < 0 || version > StatsVersion {
+		return fmt.Errorf("unsupported stats version %d (want at most %d)", version, StatsVersion)
+	}
+	return nil
+}
+
+// MigrateEditorStats upgrades es, which was decoded from a stats file with the
+// supplied version, to StatsVersion.
+func MigrateEditorStats(es *EditorStats, version int) error {
+	if err := CheckStatsVersion(version); err != nil {
+		return err
+	}
+	for v := version; v < StatsVersion; v++ {
+		if fn := editorStatsMigrations[v]; fn != nil {
+			fn(es)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbstats
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadEditorStatsVersion_OldFormats(t *testing.T) {
+	created := time.Date(2015, 4, 1, 0, 0, 0, 0, time.UTC)
+	active := time.Date(2021, 6, 2, 0, 0, 0, 0, time.UTC)
+	alice := EditorStats{ID: 1, Name: "alice", Created: created, Active: active,
+		Edits: map[EditType]int32{EDIT_ARTIST_CREATE: 3, EDIT_ARTIST_EDIT: 1}}
+	bot := EditorStats{ID: 2, Name: "bot", Created: created, Active: active, Bot: true,
+		Edits:     map[EditType]int32{EDIT_ARTIST_EDIT: 100},
+		AutoEdits: map[EditType]int32{EDIT_ARTIST_EDIT: 100}}
+
+	for _, tc := range []struct {
+		version int
+		data    string
+		want    []EditorStats
+	}{
+		{
+			// Edit counts are keyed by numeric IDs. Old files may lack bot
+			// flags and autoedit counts.
+			0,
+			`{"id":1,"name":"alice","created":"2015-04-01T00:00:00Z","active":"2021-06-02T00:00:00Z","edits":{"1":3,"2":1}}
+{"id":2,"name":"bot","created":"2015-04-01T00:00:00Z","active":"2021-06-02T00:00:00Z","bot":true,"edits":{"2":100},"autoEdits":{"2":100}}
+`,
+			[]EditorStats{alice, bot},
+		},
+		{
+			// Edit counts are keyed by names, and records contain editor information.
+			1,
+			`{"id":1,"name":"alice","created":"2015-04-01T00:00:00Z","active":"2021-06-02T00:00:00Z","edits":{"ARTIST_CREATE":3,"ARTIST_EDIT":1}}
+{"id":2,"name":"bot","created":"2015-04-01T00:00:00Z","active":"2021-06-02T00:00:00Z","bot":true,"edits":{"ARTIST_EDIT":100},"autoEdits":{"ARTIST_EDIT":100}}
+`,
+			[]EditorStats{alice, bot},
+		},
+		{
+			// Records only contain IDs and counts.
+			2,
+			`{"id":1,"edits":{"ARTIST_CREATE":3,"ARTIST_EDIT":1}}
+`,
+			[]EditorStats{{ID: 1, Edits: alice.Edits}},
+		},
+	} {
+		var got []EditorStats
+		if err := ReadEditorStatsVersion(strings.NewReader(tc.data), tc.version, func(es EditorStats) error {
+			got = append(got, es)
+			return nil
+		}); err != nil {
+			t.Errorf("ReadEditorStatsVersion(..., %d, ...) failed: %v", tc.version, err)
+		} else if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ReadEditorStatsVersion(..., %d, ...) read %+v; want %+v", tc.version, got, tc.want)
+		}
+	}
+
+	if err := ReadEditorStatsVersion(strings.NewReader(""), StatsVersion+1,
+		func(EditorStats) error { return nil }); err == nil {
+		t.Errorf("ReadEditorStatsVersion(..., %d, ...) unexpectedly succeeded", StatsVersion+1)
+	}
+}
+
+func TestReadEditorStatsFile_Version1(t *testing.T) {
+	// Version 1 directories have no editors file, so records' own editor
+	// information is used.
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		MetadataFile: `{"version":1}`,
+		"editors-2020.json": `{"id":1,"name":"alice","created":"2015-04-01T00:00:00Z",` +
+			`"active":"2021-06-02T00:00:00Z","bot":true,"edits":{"ARTIST_CREATE":3}}` + "\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got []EditorStats
+	if err := ReadEditorStatsFile(filepath.Join(dir, "editors-2020.json"), func(es EditorStats) error {
+		got = append(got, es)
+		return nil
+	}); err != nil {
+		t.Fatal("ReadEditorStatsFile failed: ", err)
+	}
+	want := []EditorStats{{ID: 1, Name: "alice", Bot: true,
+		Created: time.Date(2015, 4, 1, 0, 0, 0, 0, time.UTC),
+		Active:  time.Date(2021, 6, 2, 0, 0, 0, 0, time.UTC),
+		Edits:   map[EditType]int32{EDIT_ARTIST_CREATE: 3}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadEditorStatsFile read %+v; want %+v", got, want)
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbstats
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GzipSuffix is appended to the paths of gzip-compressed stats files.
+const GzipSuffix = ".gz"
+
+// ReadEditorStats decodes JSON-marshaled EditorStats objects from r (i.e. the
+// contents of an editors-<period>.json file) and calls fn with each one.
+// The objects are expected to use the current StatsVersion.
+// Reading stops if fn returns an error, which is returned.
+func ReadEditorStats(r io.Reader, fn func(EditorStats) error) error {
+	return ReadEditorStatsVersion(r, StatsVersion, fn)
+}
+
+// ReadEditorStatsVersion is like ReadEditorStats, but the objects in r use the
+// supplied version (see Metadata.Version) and are upgraded to StatsVersion using
+// MigrateEditorStats before being passed to fn.
+func ReadEditorStatsVersion(r io.Reader, version int, fn func(EditorStats) error) error {
+	if err := CheckStatsVersion(version); err != nil {
+		return err
+	}
+	dec := json.NewDecoder(r)
+	for {
+		var es EditorStats
+		if err := dec.Decode(&es); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := MigrateEditorStats(&es, version); err != nil {
+			return err
+		}
+		if err := fn(es); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadEditorStatsFile opens the stats file at p using OpenStatsFile and passes its
+// contents to ReadEditorStatsVersion, using the version from the metadata in p's directory.
+// Editor information is filled in from the directory's EditorsFile, which is read on
+// each call; use WalkEditorStats to read all of a directory's files.
+func ReadEditorStatsFile(p string, fn func(EditorStats) error) error {
+	dir := filepath.Dir(p)
+	md, err := ReadMetadata(dir)
+	if err != nil {
+		return err
+	}
+	editors, err := ReadEditors(dir)
+	if err != nil {
+		return err
+	}
+	return readEditorStatsFile(p, md.Version, editors, fn)
+}
+
+// readEditorStatsFile opens the stats file at p using OpenStatsFile and passes its
+// contents to ReadEditorStatsVersion. Records for editors in editors (which may be nil)
+// are updated using EditorStats.SetEditor.
+func readEditorStatsFile(p string, version int, editors map[EditorID]*Editor,
+	fn func(EditorStats) error) error {
+	f, _, err := OpenStatsFile(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := ReadEditorStatsVersion(f, version, func(es EditorStats) error {
+		if ed, ok := editors[es.ID]; ok {
+			es.SetEditor(ed)
+		}
+		return fn(es)
+	}); err != nil {
+		return fmt.Errorf("%v: %v", p, err)
+	}
+	return nil
+}
+
+// ReadEditors reads dir's EditorsFile and returns its editors keyed by ID.
+// An empty map is returned if the file does not exist, as is the case for
+// directories written by older versions of read-mbdump (whose stats files
+// contain editor information) or without reading the editor table.
+func ReadEditors(dir string) (map[EditorID]*Editor, error) {
+	editors := make(map[EditorID]*Editor)
+	f, _, err := OpenStatsFile(filepath.Join(dir, EditorsFile))
+	if os.IsNotExist(err) {
+		return editors, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	for {
+		var ed Editor
+		if err := dec.Decode(&ed); err == io.EOF {
+			return editors, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%v: %v", filepath.Join(dir, EditorsFile), err)
+		}
+		editors[ed.ID] = &ed
+	}
+}
+
+// ReadMetadata reads dir's MetadataFile. Default metadata is returned if the file
+// does not exist, as is the case for directories written by older versions of
+// read-mbdump. An error is returned if the directory's version is unsupported.
+func ReadMetadata(dir string) (*Metadata, error) {
+	var md Metadata
+	f, err := os.Open(filepath.Join(dir, MetadataFile))
+	if os.IsNotExist(err) {
+		return &md, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(&md); err != nil {
+		return nil, fmt.Errorf("%v: %v", f.Name(), err)
+	}
+	if err := CheckStatsVersion(md.Version); err != nil {
+		return nil, fmt.Errorf("%v: %v", f.Name(), err)
+	}
+	return &md, nil
+}
+
+// EditorStatsFiles returns the paths of the yearly and monthly stats files
+// (e.g. "editors-2020.json" and "editors-2020-03.json") in dir, keyed by period.
+// Paths are as returned by GlobStats. Files with unparseable periods are skipped.
+func EditorStatsFiles(dir string) (*Series[string], error) {
+	var files Series[string]
+	for _, pattern := range []string{"editors-????.json", "editors-????-??.json"} {
+		matches, err := GlobStats(dir, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range matches {
+			name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(p), "editors-"), ".json")
+			if per, err := ParsePeriod(name); err == nil {
+				files.Set(per, p)
+			}
+		}
+	}
+	return &files, nil
+}
+
+// WalkEditorStats calls fn with each record in the yearly and monthly stats files
+// in dir (see EditorStatsFiles). Files are read in ascending order by period,
+// which is passed to fn. Walking stops if fn returns an error, which is returned.
+func WalkEditorStats(dir string, fn func(per Period, es EditorStats) error) error {
+	md, err := ReadMetadata(dir)
+	if err != nil {
+		return err
+	}
+	editors, err := ReadEditors(dir)
+	if err != nil {
+		return err
+	}
+	files, err := EditorStatsFiles(dir)
+	if err != nil {
+		return err
+	}
+	return files.Each(func(per Period, p string) error {
+		return readEditorStatsFile(p, md.Version, editors,
+			func(es EditorStats) error { return fn(per, es) })
+	})
+}
+
+// OpenStatsFile opens the JSON file at p. If p doesn't exist but a gzip-compressed
+// copy with GzipSuffix does, the copy is opened instead. Gzip-compressed data
+// is transparently decompressed. The returned os.FileInfo describes the opened file.
+func OpenStatsFile(p string) (io.ReadCloser, os.FileInfo, error) {
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		if gf, gerr := os.Open(p + GzipSuffix); gerr == nil {
+			f, err = gf, nil
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(f)
+	sf := &statsFile{Reader: br, f: f}
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		if sf.zr, err = gzip.NewReader(br); err != nil {
+			f.Close()
+			return nil, nil, fmt.Errorf("%v: %v", f.Name(), err)
+		}
+		sf.Reader = sf.zr
+	}
+	return sf, fi, nil
+}
+
+// statsFile is an io.ReadCloser returned by OpenStatsFile.
+type statsFile struct {
+	io.Reader
+	f  *os.File
+	zr *gzip.Reader // nil if uncompressed
+}
+
+func (sf *statsFile) Close() error {
+	if sf.zr != nil {
+		sf.zr.Close()
+	}
+	return sf.f.Close()
+}
+
+// GlobStats returns the paths of JSON files in dir matching pattern (e.g.
+// "editors-????.json"), sorted in ascending order. Files that only exist as
+// gzip-compressed copies are included with GzipSuffix removed, as expected
+// by OpenStatsFile.
+func GlobStats(dir, pattern string) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, pat := range []string{pattern, pattern + GzipSuffix} {
+		matches, err := filepath.Glob(filepath.Join(dir, pat))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range matches {
+			if p = strings.TrimSuffix(p, GzipSuffix); !seen[p] {
+				paths = append(paths, p)
+				seen[p] = true
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
@@ -0,0 +1,187 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbstats
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Period identifies a year or a calendar month.
+type Period struct {
+	// Year is the year as returned by Metadata.Year for yearly periods
+	// or the calendar year for monthly periods.
+	Year int
+	// Month is the calendar month for monthly periods or zero for yearly periods.
+	Month time.Month
+}
+
+// YearPeriod returns a Period for the specified year.
+func YearPeriod(year int) Period { return Period{Year: year} }
+
+// MonthPeriod returns a Period for the specified calendar month.
+func MonthPeriod(year int, month time.Month) Period { return Period{Year: year, Month: month} }
+
+// ParsePeriod parses a period formatted by Period.String, e.g. "2020" or "2020-03".
+func ParsePeriod(s string) (Period, error) {
+	if len(s) == 4 {
+		year, err := strconv.Atoi(s)
+		if err != nil {
+			return Period{}, fmt.Errorf("bad year %q", s)
+		}
+		return YearPeriod(year), nil
+	}
+	t, err := time.Parse(monthLayout, s)
+	if err != nil {
+		return Period{}, fmt.Errorf("bad period %q", s)
+	}
+	return MonthPeriod(t.Year(), t.Month()), nil
+}
+
+// monthLayout is the time layout used by Period.String for monthly periods.
+const monthLayout = "2006-01"
+
+// String returns p as e.g. "2020" or "2020-03".
+// This is the format used in stats filenames.
+func (p Period) String() string {
+	if p.IsMonth() {
+		return fmt.Sprintf("%04d-%02d", p.Year, p.Month)
+	}
+	return fmt.Sprintf("%04d", p.Year)
+}
+
+// IsMonth returns true if p identifies a month rather than a year.
+func (p Period) IsMonth() bool { return p.Month != 0 }
+
+// Index returns a number identifying p among periods of the same granularity:
+// the year for yearly periods or a month count for monthly periods.
+// Consecutive periods have consecutive indexes.
+func (p Period) Index() int {
+	if p.IsMonth() {
+		return p.Year*12 + int(p.Month) - 1
+	}
+	return p.Year
+}
+
+// Next returns the period following p with the same granularity.
+func (p Period) Next() Period {
+	if !p.IsMonth() {
+		return YearPeriod(p.Year + 1)
+	}
+	if p.Month == time.December {
+		return MonthPeriod(p.Year+1, time.January)
+	}
+	return MonthPeriod(p.Year, p.Month+1)
+}
+
+// Before returns true if p sorts before o. Periods are ordered by year and then
+// by month, with a yearly period sorting before the months with the same year.
+func (p Period) Before(o Period) bool {
+	if p.Year != o.Year {
+		return p.Year < o.Year
+	}
+	return p.Month < o.Month
+}
+
+// Series holds values of type T keyed by Period, e.g. a year's editor stats.
+// Values are kept sorted by ascending period as described by Period.Before.
+// The zero value is an empty series ready for use.
+type Series[T any] struct {
+	periods []Period
+	values  []T
+}
+
+// search returns the index of p within s.periods, or the index
+// at which it should be inserted if it isn't present.
+func (s *Series[T]) search(p Period) int {
+	return sort.Search(len(s.periods), func(i int) bool { return !s.periods[i].Before(p) })
+}
+
+// Len returns the number of periods in s.
+func (s *Series[T]) Len() int { return len(s.periods) }
+
+// Get returns the value for p. false is returned if p isn't present.
+func (s *Series[T]) Get(p Period) (T, bool) {
+	if i := s.search(p); i < len(s.periods) && s.periods[i] == p {
+		return s.values[i], true
+	}
+	var zero T
+	return zero, false
+}
+
+// Set sets p's value to v, replacing any existing value.
+func (s *Series[T]) Set(p Period, v T) {
+	i := s.search(p)
+	if i < len(s.periods) && s.periods[i] == p {
+		s.values[i] = v
+		return
+	}
+	var zero T
+	s.periods = append(s.periods, Period{})
+	s.values = append(s.values, zero)
+	copy(s.periods[i+1:], s.periods[i:])
+	copy(s.values[i+1:], s.values[i:])
+	s.periods[i], s.values[i] = p, v
+}
+
+// At returns the period and value at index i, which must be in [0, s.Len()).
+func (s *Series[T]) At(i int) (Period, T) { return s.periods[i], s.values[i] }
+
+// Periods returns s's periods in ascending order.
+func (s *Series[T]) Periods() []Period { return append([]Period(nil), s.periods...) }
+
+// Values returns s's values in ascending order by period. The returned slice
+// shares storage with s, so its elements may be modified in place (e.g. to fill
+// in values in parallel), but it must not be appended to.
+func (s *Series[T]) Values() []T { return s.values }
+
+// Each calls fn with each period and value in ascending order.
+// Iteration stops if fn returns an error, which is returned.
+func (s *Series[T]) Each(fn func(p Period, v T) error) error {
+	for i, p := range s.periods {
+		if err := fn(p, s.values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Filter returns a new series containing the periods and values for which fn returns true.
+func (s *Series[T]) Filter(fn func(p Period, v T) bool) *Series[T] {
+	res := &Series[T]{}
+	for i, p := range s.periods {
+		if fn(p, s.values[i]) {
+			res.periods = append(res.periods, p)
+			res.values = append(res.values, s.values[i])
+		}
+	}
+	return res
+}
+
+// Range returns a new series containing the periods in [start, end].
+// Note that a monthly period sorts after the yearly period with the same year,
+// so e.g. "2020-03" is excluded from the range ["2019", "2020"].
+func (s *Series[T]) Range(start, end Period) *Series[T] {
+	return s.Filter(func(p Period, _ T) bool { return !p.Before(start) && !end.Before(p) })
+}
+
+// Years returns a new series with a yearly period for each year in s, with years
+// determined using md. The value for each year is produced by calling merge with
+// an accumulated value (initially T's zero value) and each of the year's values
+// from s in ascending order. merge must not modify v.
+func (s *Series[T]) Years(md *Metadata, merge func(acc, v T) T) *Series[T] {
+	res := &Series[T]{}
+	for i, p := range s.periods {
+		year := p.Year
+		if p.IsMonth() {
+			year = md.Year(md.PeriodStart(p))
+		}
+		yp := YearPeriod(year)
+		acc, _ := res.Get(yp)
+		res.Set(yp, merge(acc, s.values[i]))
+	}
+	return res
+}
@@ -7,6 +7,10 @@ package mbstats
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,14 +21,209 @@ import (
 type EditorID int32
 type EditType int16
 
+// MarshalText returns et's name as returned by EditTypeName, or its numeric ID if
+// the type is unknown. This causes EditType keys and values to be encoded as
+// human-readable names in JSON.
+func (et EditType) MarshalText() ([]byte, error) {
+	if name, ok := editTypeNames[et]; ok {
+		return []byte(name), nil
+	}
+	return []byte(strconv.Itoa(int(et))), nil
+}
+
+// UnmarshalText parses b as a name accepted by NamedEditType or as a numeric ID.
+// Numeric IDs are accepted even for unknown types so that files written before
+// edit types were marshaled as names can still be read.
+func (et *EditType) UnmarshalText(b []byte) error {
+	s := string(b)
+	if id, err := strconv.ParseInt(s, 10, 16); err == nil {
+		*et = EditType(id)
+		return nil
+	}
+	v, err := NamedEditType(s)
+	if err != nil {
+		return fmt.Errorf("%v %q", err, s)
+	}
+	*et = v
+	return nil
+}
+
 // EditorStats contains information about a single editor and counts of their
 // edits within a given time period.
+//
+// Since stats version 2, stats files only contain editors' IDs and counts, and
+// Name, Created, Active, and Bot are filled in from the directory's EditorsFile
+// (see SetEditor) when records are read.
 type EditorStats struct {
-	ID      EditorID           `json:"id"`
-	Name    string             `json:"name"`
-	Created time.Time          `json:"created"`
-	Active  time.Time          `json:"active"`
-	Edits   map[EditType]int32 `json:"edits"`
+	ID        EditorID           `json:"id"`
+	Name      string             `json:"name"`
+	Created   time.Time          `json:"created"`
+	Active    time.Time          `json:"active"`
+	Bot       bool               `json:"bot,omitempty"`
+	Edits     map[EditType]int32 `json:"edits"`
+	AutoEdits map[EditType]int32 `json:"autoEdits,omitempty"` // subset of Edits applied without voting
+}
+
+// SetEditor copies the information in ed into es.
+func (es *EditorStats) SetEditor(ed *Editor) {
+	es.Name = ed.Name
+	es.Created = ed.Created
+	es.Active = ed.Active
+	es.Bot = ed.Privs.Has(BotFlag)
+}
+
+// EditorsFile is the name of the file within a stats directory that
+// contains JSON-marshaled Editor objects.
+const EditorsFile = "editors.json"
+
+// Editor contains information about a single editor from the editor table.
+// Fields that are cleared in public (sanitised) dumps are zero in that case.
+type Editor struct {
+	ID      EditorID    `json:"id"`
+	Name    string      `json:"name"`
+	Created time.Time   `json:"created"`           // member_since; zero if unknown
+	Active  time.Time   `json:"active"`            // last_login_date
+	Deleted bool        `json:"deleted,omitempty"` // account was deleted
+	Privs   EditorPrivs `json:"privs,omitempty"`
+	Area    int32       `json:"area,omitempty"`   // area.id; zero if unset
+	Gender  int32       `json:"gender,omitempty"` // gender.id; zero if unset
+}
+
+// EditorPrivs holds an editor's privilege flags (the editor table's privs column).
+type EditorPrivs int32
+
+// Flags in EditorPrivs. See lib/MusicBrainz/Server/Constants.pm.
+const (
+	AutoEditorFlag          EditorPrivs = 1
+	BotFlag                 EditorPrivs = 2
+	UntrustedFlag           EditorPrivs = 4
+	RelationshipEditorFlag  EditorPrivs = 8
+	DontNagFlag             EditorPrivs = 16
+	WikiTransclusionFlag    EditorPrivs = 32
+	MBIDSubmitterFlag       EditorPrivs = 64
+	AccountAdminFlag        EditorPrivs = 128
+	LocationEditorFlag      EditorPrivs = 256
+	BannerEditorFlag        EditorPrivs = 512
+	EditingDisabledFlag     EditorPrivs = 1024
+	AddingNotesDisabledFlag EditorPrivs = 2048
+	SpammerFlag             EditorPrivs = 4096
+)
+
+// Has returns true if all of the bits in flag are set in p.
+func (p EditorPrivs) Has(flag EditorPrivs) bool { return p&flag == flag }
+
+// VoterStats contains counts of the votes cast by a single editor within a given
+// time period. Superseded votes (i.e. ones that were later changed) are not counted.
+type VoterStats struct {
+	ID      EditorID `json:"id"`
+	Name    string   `json:"name"`
+	Yes     int32    `json:"yes"`
+	No      int32    `json:"no"`
+	Abstain int32    `json:"abstain"`
+	Approve int32    `json:"approve"`
+}
+
+// Total returns the total number of votes in vs.
+func (vs *VoterStats) Total() int {
+	return int(vs.Yes + vs.No + vs.Abstain + vs.Approve)
+}
+
+// NoteStats contains the number of edit notes written by a single editor
+// within a given time period.
+type NoteStats struct {
+	ID    EditorID `json:"id"`
+	Name  string   `json:"name"`
+	Notes int32    `json:"notes"`
+}
+
+// EditorNameHistory contains the names that were used by a single editor
+// across multiple database dumps.
+type EditorNameHistory struct {
+	ID    EditorID     `json:"id"`
+	Names []EditorName `json:"names"` // sorted by ascending time
+}
+
+// EditorName describes a name that was used by an editor.
+type EditorName struct {
+	Name  string    `json:"name"`
+	First time.Time `json:"first"` // timestamp of first dump containing name
+	Last  time.Time `json:"last"`  // timestamp of last dump containing name
+}
+
+// EditorIndexFile is the name of the file within a stats directory that
+// contains JSON-marshaled EditorIndexEntry objects.
+const EditorIndexFile = "editors-index.json"
+
+// EditorIndexEntry describes where a single editor's records are located
+// within a stats directory's editors-<year>.json files.
+type EditorIndexEntry struct {
+	ID      EditorID      `json:"id"`
+	Name    string        `json:"name"`
+	Offsets map[int]int64 `json:"offsets"` // byte offsets of records, keyed by year
+}
+
+// MetadataFile is the name of the file within a stats directory that
+// contains a JSON-marshaled Metadata object.
+const MetadataFile = "metadata.json"
+
+// Metadata describes how the files in a stats directory were generated.
+type Metadata struct {
+	// YearStartMonth is the first month of the years that edits were grouped into,
+	// e.g. time.July for July-June fiscal years. Zero is treated as January.
+	YearStartMonth time.Month `json:"yearStartMonth,omitempty"`
+	// Version is the StatsVersion of the directory's stats files.
+	// Zero is used for directories written before versioning was added.
+	Version int `json:"version,omitempty"`
+	// DumpTime is the time at which the database dump was created.
+	// It is zero for directories written before it was recorded.
+	DumpTime time.Time `json:"dumpTime"`
+}
+
+func (md *Metadata) startMonth() time.Month {
+	if md.YearStartMonth < time.January || md.YearStartMonth > time.December {
+		return time.January
+	}
+	return md.YearStartMonth
+}
+
+// Year returns the year containing t.
+// Years are identified by the calendar year in which they start.
+func (md *Metadata) Year(t time.Time) int {
+	if t.Month() < md.startMonth() {
+		return t.Year() - 1
+	}
+	return t.Year()
+}
+
+// YearStart returns the time at which the specified year starts in UTC.
+func (md *Metadata) YearStart(year int) time.Time {
+	return time.Date(year, md.startMonth(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// YearLabel returns a human-readable label for the specified year,
+// e.g. "2022" for calendar years or "2022-23" for years starting in July.
+func (md *Metadata) YearLabel(year int) string {
+	if md.startMonth() == time.January {
+		return strconv.Itoa(year)
+	}
+	return fmt.Sprintf("%d-%02d", year, (year+1)%100)
+}
+
+// PeriodStart returns the time at which p starts in UTC.
+func (md *Metadata) PeriodStart(p Period) time.Time {
+	if p.IsMonth() {
+		return time.Date(p.Year, p.Month, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return md.YearStart(p.Year)
+}
+
+// PeriodLabel returns a human-readable label for p, e.g. "2022-23" for a year
+// starting in July or "2022-03" for a month.
+func (md *Metadata) PeriodLabel(p Period) string {
+	if p.IsMonth() {
+		return p.String()
+	}
+	return md.YearLabel(p.Year)
 }
 
 // EditTypeName returns a human-readable string describing et.
@@ -35,14 +234,184 @@ func EditTypeName(et EditType) string {
 	return fmt.Sprintf("UNKNOWN_%d", et)
 }
 
+// EditTypes returns all known edit types in ascending order.
+func EditTypes() []EditType {
+	types := make([]EditType, 0, len(editTypeNames))
+	for et := range editTypeNames {
+		types = append(types, et)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// EditTypeInfo returns the entity (e.g. "ARTIST" or "RELEASEGROUP") affected by
+// edits of type et and the action (e.g. "CREATE" or "ADD_ALIAS") that they perform.
+// Historic types (e.g. HISTORIC_ADD_RELEASE) are reported using their entity
+// in the current schema (e.g. "RELEASE"); their names identify them as historic.
+// Empty strings are returned for unknown types.
+func EditTypeInfo(et EditType) (entity, action string) {
+	info := editTypeInfos[et]
+	return info[0], info[1]
+}
+
+// categoryRegexp matches category patterns like "ARTIST_*".
+var categoryRegexp = regexp.MustCompile(`^[A-Z_]+\*$`)
+
+// MatchEditTypes returns the edit types matched by pattern in ascending order.
+// pattern may be a name as returned by EditTypeName, a category like "ARTIST_*"
+// that matches all types with names beginning with the text before the asterisk,
+// or a regular expression like "RELEASE_(CREATE|EDIT)" that must match entire names.
+func MatchEditTypes(pattern string) ([]EditType, error) {
+	if et, err := NamedEditType(pattern); err == nil {
+		return []EditType{et}, nil
+	}
+
+	var match func(name string) bool
+	if categoryRegexp.MatchString(pattern) {
+		prefix := strings.TrimSuffix(pattern, "*")
+		match = func(name string) bool { return strings.HasPrefix(name, prefix) }
+	} else {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, err
+		}
+		match = re.MatchString
+	}
+
+	var types []EditType
+	for _, et := range EditTypes() {
+		if match(editTypeNames[et]) {
+			types = append(types, et)
+		}
+	}
+	if len(types) == 0 {
+		return nil, errors.New("no matching edit types")
+	}
+	return types, nil
+}
+
+// editTypeAliases maps from alternate spellings of the beginnings of edit type names
+// to the spellings used by EditTypeName.
+var editTypeAliases = map[string]string{
+	"RELEASE_GROUP":        "RELEASEGROUP",
+	"RELATIONSHIP_REORDER": "RELATIONSHIPS_REORDER",
+}
+
 // NamedEditType returns the edit type corresponding to a human-readable
-// string as returned by EditTypeName.
+// string as returned by EditTypeName. Matching is case-insensitive, and
+// hyphens are treated as underscores. Names may also have an "EDIT_" prefix
+// (as used by the MusicBrainz server), use aliases like "RELEASE_GROUP" for
+// "RELEASEGROUP", or be numeric IDs.
 func NamedEditType(name string) (EditType, error) {
-	// TODO: Build an inverted map if this is used for anything performance-critical.
-	for k, v := range editTypeNames {
-		if v == name {
-			return k, nil
+	if id, err := strconv.ParseInt(name, 10, 16); err == nil {
+		if _, ok := editTypeNames[EditType(id)]; ok {
+			return EditType(id), nil
 		}
+		return 0, errors.New("unknown edit type")
+	}
+	if et, ok := editTypeIDs[name]; ok {
+		return et, nil
+	}
+
+	name = strings.TrimPrefix(strings.ToUpper(strings.ReplaceAll(name, "-", "_")), "EDIT_")
+	for from, to := range editTypeAliases {
+		if name == from || strings.HasPrefix(name, from+"_") {
+			name = to + name[len(from):]
+			break
+		}
+	}
+	if et, ok := editTypeIDs[name]; ok {
+		return et, nil
 	}
 	return 0, errors.New("unknown edit type")
 }
+
+// CheckEditTypes checks the internal consistency of the edit type metadata, which
+// is generated from MusicBrainz server code by gen_types.sh: every type must have
+// a unique name, an entity, and an action, the map from names back to types must
+// be the exact inverse of the map from types to names, and editTypeAliases
+// must not contain chains or cycles. An error is returned for each problem that is
+// found; the returned slice is empty if no problems were found.
+func CheckEditTypes() []error {
+	var errs []error
+	addErr := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	types := EditTypes()
+	if len(types) == 0 {
+		addErr("no edit types")
+	}
+	seen := make(map[string]EditType, len(types))
+	for _, et := range types {
+		name := editTypeNames[et]
+		if name == "" {
+			addErr("type %d has empty name", et)
+			continue
+		}
+		if other, ok := seen[name]; ok {
+			addErr("types %d and %d both named %q", other, et, name)
+			continue
+		}
+		seen[name] = et
+
+		if got, err := NamedEditType(name); err != nil {
+			addErr("lookup of %q failed: %v", name, err)
+		} else if got != et {
+			addErr("lookup of %q returned %d; want %d", name, got, et)
+		}
+		if entity, action := EditTypeInfo(et); entity == "" || action == "" {
+			addErr("%q has entity %q and action %q", name, entity, action)
+		}
+	}
+	for et := range editTypeInfos {
+		if _, ok := editTypeNames[et]; !ok {
+			addErr("entity and action for unknown type %d", et)
+		}
+	}
+
+	// The name-to-type map is generated separately, so make sure it's complete.
+	for name, et := range editTypeIDs {
+		if got, ok := editTypeNames[et]; !ok {
+			addErr("name %q maps to unknown type %d", name, et)
+		} else if got != name {
+			addErr("name %q maps to type %d, which is named %q", name, et, got)
+		}
+	}
+	for et, name := range editTypeNames {
+		if _, ok := editTypeIDs[name]; !ok {
+			addErr("type %d's name %q is missing from name map", et, name)
+		}
+	}
+
+	// NamedEditType only applies a single alias, so an alias whose replacement is
+	// itself aliased (including an alias of itself) would never be resolved.
+	hasPrefix := func(name, prefix string) bool {
+		return name == prefix || strings.HasPrefix(name, prefix+"_")
+	}
+	for from, to := range editTypeAliases {
+		for other := range editTypeAliases {
+			if hasPrefix(to, other) {
+				addErr("alias %q maps to %q, which is aliased by %q", from, to, other)
+			}
+		}
+		var used bool
+		for name, et := range editTypeIDs {
+			if !hasPrefix(name, to) {
+				continue
+			}
+			used = true
+			alias := from + name[len(to):]
+			if got, err := NamedEditType(alias); err != nil || got != et {
+				addErr("lookup of alias %q returned %d (%v); want %d", alias, got, err, et)
+			}
+		}
+		if !used {
+			addErr("alias %q maps to %q, which isn't used by any type", from, to)
+		}
+	}
+
+	// Make the order deterministic, since maps were iterated over.
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errs
+}
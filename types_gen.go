@@ -345,3 +345,346 @@ var editTypeNames = map[EditType]string{
 	EDIT_WORK_MERGE:                            "WORK_MERGE",
 	EDIT_WORK_REMOVE_ISWC:                      "WORK_REMOVE_ISWC",
 }
+
+var editTypeIDs = map[string]EditType{
+	"AREA_ADD_ALIAS":                        EDIT_AREA_ADD_ALIAS,
+	"AREA_ADD_ANNOTATION":                   EDIT_AREA_ADD_ANNOTATION,
+	"AREA_CREATE":                           EDIT_AREA_CREATE,
+	"AREA_DELETE":                           EDIT_AREA_DELETE,
+	"AREA_DELETE_ALIAS":                     EDIT_AREA_DELETE_ALIAS,
+	"AREA_EDIT":                             EDIT_AREA_EDIT,
+	"AREA_EDIT_ALIAS":                       EDIT_AREA_EDIT_ALIAS,
+	"AREA_MERGE":                            EDIT_AREA_MERGE,
+	"ARTIST_ADD_ALIAS":                      EDIT_ARTIST_ADD_ALIAS,
+	"ARTIST_ADD_ANNOTATION":                 EDIT_ARTIST_ADD_ANNOTATION,
+	"ARTIST_CREATE":                         EDIT_ARTIST_CREATE,
+	"ARTIST_DELETE":                         EDIT_ARTIST_DELETE,
+	"ARTIST_DELETE_ALIAS":                   EDIT_ARTIST_DELETE_ALIAS,
+	"ARTIST_EDIT":                           EDIT_ARTIST_EDIT,
+	"ARTIST_EDITCREDIT":                     EDIT_ARTIST_EDITCREDIT,
+	"ARTIST_EDIT_ALIAS":                     EDIT_ARTIST_EDIT_ALIAS,
+	"ARTIST_MERGE":                          EDIT_ARTIST_MERGE,
+	"EVENT_ADD_ALIAS":                       EDIT_EVENT_ADD_ALIAS,
+	"EVENT_ADD_ANNOTATION":                  EDIT_EVENT_ADD_ANNOTATION,
+	"EVENT_CREATE":                          EDIT_EVENT_CREATE,
+	"EVENT_DELETE":                          EDIT_EVENT_DELETE,
+	"EVENT_DELETE_ALIAS":                    EDIT_EVENT_DELETE_ALIAS,
+	"EVENT_EDIT":                            EDIT_EVENT_EDIT,
+	"EVENT_EDIT_ALIAS":                      EDIT_EVENT_EDIT_ALIAS,
+	"EVENT_MERGE":                           EDIT_EVENT_MERGE,
+	"GENRE_ADD_ALIAS":                       EDIT_GENRE_ADD_ALIAS,
+	"GENRE_ADD_ANNOTATION":                  EDIT_GENRE_ADD_ANNOTATION,
+	"GENRE_CREATE":                          EDIT_GENRE_CREATE,
+	"GENRE_DELETE":                          EDIT_GENRE_DELETE,
+	"GENRE_DELETE_ALIAS":                    EDIT_GENRE_DELETE_ALIAS,
+	"GENRE_EDIT":                            EDIT_GENRE_EDIT,
+	"GENRE_EDIT_ALIAS":                      EDIT_GENRE_EDIT_ALIAS,
+	"HISTORIC_ADD_DISCID":                   EDIT_HISTORIC_ADD_DISCID,
+	"HISTORIC_ADD_LINK":                     EDIT_HISTORIC_ADD_LINK,
+	"HISTORIC_ADD_RELEASE":                  EDIT_HISTORIC_ADD_RELEASE,
+	"HISTORIC_ADD_RELEASE_ANNOTATION":       EDIT_HISTORIC_ADD_RELEASE_ANNOTATION,
+	"HISTORIC_ADD_RELEASE_EVENTS":           EDIT_HISTORIC_ADD_RELEASE_EVENTS,
+	"HISTORIC_ADD_TRACK":                    EDIT_HISTORIC_ADD_TRACK,
+	"HISTORIC_ADD_TRACK_KV":                 EDIT_HISTORIC_ADD_TRACK_KV,
+	"HISTORIC_CHANGE_ARTIST_QUALITY":        EDIT_HISTORIC_CHANGE_ARTIST_QUALITY,
+	"HISTORIC_CHANGE_RELEASE_GROUP":         EDIT_HISTORIC_CHANGE_RELEASE_GROUP,
+	"HISTORIC_CHANGE_RELEASE_QUALITY":       EDIT_HISTORIC_CHANGE_RELEASE_QUALITY,
+	"HISTORIC_CHANGE_TRACK_ARTIST":          EDIT_HISTORIC_CHANGE_TRACK_ARTIST,
+	"HISTORIC_EDIT_LINK":                    EDIT_HISTORIC_EDIT_LINK,
+	"HISTORIC_EDIT_LINK_TYPE":               EDIT_HISTORIC_EDIT_LINK_TYPE,
+	"HISTORIC_EDIT_RELEASE_ATTRS":           EDIT_HISTORIC_EDIT_RELEASE_ATTRS,
+	"HISTORIC_EDIT_RELEASE_EVENTS":          EDIT_HISTORIC_EDIT_RELEASE_EVENTS,
+	"HISTORIC_EDIT_RELEASE_EVENTS_OLD":      EDIT_HISTORIC_EDIT_RELEASE_EVENTS_OLD,
+	"HISTORIC_EDIT_RELEASE_LANGUAGE":        EDIT_HISTORIC_EDIT_RELEASE_LANGUAGE,
+	"HISTORIC_EDIT_RELEASE_NAME":            EDIT_HISTORIC_EDIT_RELEASE_NAME,
+	"HISTORIC_EDIT_TRACKNAME":               EDIT_HISTORIC_EDIT_TRACKNAME,
+	"HISTORIC_EDIT_TRACKNUM":                EDIT_HISTORIC_EDIT_TRACKNUM,
+	"HISTORIC_EDIT_TRACK_LENGTH":            EDIT_HISTORIC_EDIT_TRACK_LENGTH,
+	"HISTORIC_MAC_TO_SAC":                   EDIT_HISTORIC_MAC_TO_SAC,
+	"HISTORIC_MERGE_RELEASE":                EDIT_HISTORIC_MERGE_RELEASE,
+	"HISTORIC_MERGE_RELEASE_MAC":            EDIT_HISTORIC_MERGE_RELEASE_MAC,
+	"HISTORIC_MOVE_DISCID":                  EDIT_HISTORIC_MOVE_DISCID,
+	"HISTORIC_MOVE_RELEASE":                 EDIT_HISTORIC_MOVE_RELEASE,
+	"HISTORIC_REMOVE_DISCID":                EDIT_HISTORIC_REMOVE_DISCID,
+	"HISTORIC_REMOVE_LABEL_ALIAS":           EDIT_HISTORIC_REMOVE_LABEL_ALIAS,
+	"HISTORIC_REMOVE_LINK":                  EDIT_HISTORIC_REMOVE_LINK,
+	"HISTORIC_REMOVE_LINK_TYPE":             EDIT_HISTORIC_REMOVE_LINK_TYPE,
+	"HISTORIC_REMOVE_RELEASE":               EDIT_HISTORIC_REMOVE_RELEASE,
+	"HISTORIC_REMOVE_RELEASES":              EDIT_HISTORIC_REMOVE_RELEASES,
+	"HISTORIC_REMOVE_RELEASE_EVENTS":        EDIT_HISTORIC_REMOVE_RELEASE_EVENTS,
+	"HISTORIC_REMOVE_TRACK":                 EDIT_HISTORIC_REMOVE_TRACK,
+	"HISTORIC_SAC_TO_MAC":                   EDIT_HISTORIC_SAC_TO_MAC,
+	"HISTORIC_SET_TRACK_LENGTHS_FROM_CDTOC": EDIT_HISTORIC_SET_TRACK_LENGTHS_FROM_CDTOC,
+	"INSTRUMENT_ADD_ALIAS":                  EDIT_INSTRUMENT_ADD_ALIAS,
+	"INSTRUMENT_ADD_ANNOTATION":             EDIT_INSTRUMENT_ADD_ANNOTATION,
+	"INSTRUMENT_CREATE":                     EDIT_INSTRUMENT_CREATE,
+	"INSTRUMENT_DELETE":                     EDIT_INSTRUMENT_DELETE,
+	"INSTRUMENT_DELETE_ALIAS":               EDIT_INSTRUMENT_DELETE_ALIAS,
+	"INSTRUMENT_EDIT":                       EDIT_INSTRUMENT_EDIT,
+	"INSTRUMENT_EDIT_ALIAS":                 EDIT_INSTRUMENT_EDIT_ALIAS,
+	"INSTRUMENT_MERGE":                      EDIT_INSTRUMENT_MERGE,
+	"LABEL_ADD_ALIAS":                       EDIT_LABEL_ADD_ALIAS,
+	"LABEL_ADD_ANNOTATION":                  EDIT_LABEL_ADD_ANNOTATION,
+	"LABEL_CREATE":                          EDIT_LABEL_CREATE,
+	"LABEL_DELETE":                          EDIT_LABEL_DELETE,
+	"LABEL_DELETE_ALIAS":                    EDIT_LABEL_DELETE_ALIAS,
+	"LABEL_EDIT":                            EDIT_LABEL_EDIT,
+	"LABEL_EDIT_ALIAS":                      EDIT_LABEL_EDIT_ALIAS,
+	"LABEL_MERGE":                           EDIT_LABEL_MERGE,
+	"MEDIUM_ADD_DISCID":                     EDIT_MEDIUM_ADD_DISCID,
+	"MEDIUM_CREATE":                         EDIT_MEDIUM_CREATE,
+	"MEDIUM_DELETE":                         EDIT_MEDIUM_DELETE,
+	"MEDIUM_EDIT":                           EDIT_MEDIUM_EDIT,
+	"MEDIUM_MOVE_DISCID":                    EDIT_MEDIUM_MOVE_DISCID,
+	"MEDIUM_REMOVE_DISCID":                  EDIT_MEDIUM_REMOVE_DISCID,
+	"PLACE_ADD_ALIAS":                       EDIT_PLACE_ADD_ALIAS,
+	"PLACE_ADD_ANNOTATION":                  EDIT_PLACE_ADD_ANNOTATION,
+	"PLACE_CREATE":                          EDIT_PLACE_CREATE,
+	"PLACE_DELETE":                          EDIT_PLACE_DELETE,
+	"PLACE_DELETE_ALIAS":                    EDIT_PLACE_DELETE_ALIAS,
+	"PLACE_EDIT":                            EDIT_PLACE_EDIT,
+	"PLACE_EDIT_ALIAS":                      EDIT_PLACE_EDIT_ALIAS,
+	"PLACE_MERGE":                           EDIT_PLACE_MERGE,
+	"RECORDING_ADD_ALIAS":                   EDIT_RECORDING_ADD_ALIAS,
+	"RECORDING_ADD_ANNOTATION":              EDIT_RECORDING_ADD_ANNOTATION,
+	"RECORDING_ADD_ISRCS":                   EDIT_RECORDING_ADD_ISRCS,
+	"RECORDING_CREATE":                      EDIT_RECORDING_CREATE,
+	"RECORDING_DELETE":                      EDIT_RECORDING_DELETE,
+	"RECORDING_DELETE_ALIAS":                EDIT_RECORDING_DELETE_ALIAS,
+	"RECORDING_EDIT":                        EDIT_RECORDING_EDIT,
+	"RECORDING_EDIT_ALIAS":                  EDIT_RECORDING_EDIT_ALIAS,
+	"RECORDING_MERGE":                       EDIT_RECORDING_MERGE,
+	"RECORDING_REMOVE_ISRC":                 EDIT_RECORDING_REMOVE_ISRC,
+	"RELATIONSHIPS_REORDER":                 EDIT_RELATIONSHIPS_REORDER,
+	"RELATIONSHIP_ADD_ATTRIBUTE":            EDIT_RELATIONSHIP_ADD_ATTRIBUTE,
+	"RELATIONSHIP_ADD_TYPE":                 EDIT_RELATIONSHIP_ADD_TYPE,
+	"RELATIONSHIP_ATTRIBUTE":                EDIT_RELATIONSHIP_ATTRIBUTE,
+	"RELATIONSHIP_CREATE":                   EDIT_RELATIONSHIP_CREATE,
+	"RELATIONSHIP_DELETE":                   EDIT_RELATIONSHIP_DELETE,
+	"RELATIONSHIP_EDIT":                     EDIT_RELATIONSHIP_EDIT,
+	"RELATIONSHIP_EDIT_LINK_TYPE":           EDIT_RELATIONSHIP_EDIT_LINK_TYPE,
+	"RELATIONSHIP_REMOVE_LINK_ATTRIBUTE":    EDIT_RELATIONSHIP_REMOVE_LINK_ATTRIBUTE,
+	"RELATIONSHIP_REMOVE_LINK_TYPE":         EDIT_RELATIONSHIP_REMOVE_LINK_TYPE,
+	"RELEASEGROUP_ADD_ALIAS":                EDIT_RELEASEGROUP_ADD_ALIAS,
+	"RELEASEGROUP_ADD_ANNOTATION":           EDIT_RELEASEGROUP_ADD_ANNOTATION,
+	"RELEASEGROUP_CREATE":                   EDIT_RELEASEGROUP_CREATE,
+	"RELEASEGROUP_DELETE":                   EDIT_RELEASEGROUP_DELETE,
+	"RELEASEGROUP_DELETE_ALIAS":             EDIT_RELEASEGROUP_DELETE_ALIAS,
+	"RELEASEGROUP_EDIT":                     EDIT_RELEASEGROUP_EDIT,
+	"RELEASEGROUP_EDIT_ALIAS":               EDIT_RELEASEGROUP_EDIT_ALIAS,
+	"RELEASEGROUP_MERGE":                    EDIT_RELEASEGROUP_MERGE,
+	"RELEASEGROUP_SET_COVER_ART":            EDIT_RELEASEGROUP_SET_COVER_ART,
+	"RELEASE_ADDRELEASELABEL":               EDIT_RELEASE_ADDRELEASELABEL,
+	"RELEASE_ADD_ALIAS":                     EDIT_RELEASE_ADD_ALIAS,
+	"RELEASE_ADD_ANNOTATION":                EDIT_RELEASE_ADD_ANNOTATION,
+	"RELEASE_ADD_COVER_ART":                 EDIT_RELEASE_ADD_COVER_ART,
+	"RELEASE_ARTIST":                        EDIT_RELEASE_ARTIST,
+	"RELEASE_CHANGE_QUALITY":                EDIT_RELEASE_CHANGE_QUALITY,
+	"RELEASE_CREATE":                        EDIT_RELEASE_CREATE,
+	"RELEASE_DELETE":                        EDIT_RELEASE_DELETE,
+	"RELEASE_DELETERELEASELABEL":            EDIT_RELEASE_DELETERELEASELABEL,
+	"RELEASE_DELETE_ALIAS":                  EDIT_RELEASE_DELETE_ALIAS,
+	"RELEASE_EDIT":                          EDIT_RELEASE_EDIT,
+	"RELEASE_EDITRELEASELABEL":              EDIT_RELEASE_EDITRELEASELABEL,
+	"RELEASE_EDIT_ALIAS":                    EDIT_RELEASE_EDIT_ALIAS,
+	"RELEASE_EDIT_BARCODES":                 EDIT_RELEASE_EDIT_BARCODES,
+	"RELEASE_EDIT_COVER_ART":                EDIT_RELEASE_EDIT_COVER_ART,
+	"RELEASE_MERGE":                         EDIT_RELEASE_MERGE,
+	"RELEASE_MOVE":                          EDIT_RELEASE_MOVE,
+	"RELEASE_REMOVE_COVER_ART":              EDIT_RELEASE_REMOVE_COVER_ART,
+	"RELEASE_REORDER_COVER_ART":             EDIT_RELEASE_REORDER_COVER_ART,
+	"RELEASE_REORDER_MEDIUMS":               EDIT_RELEASE_REORDER_MEDIUMS,
+	"SERIES_ADD_ALIAS":                      EDIT_SERIES_ADD_ALIAS,
+	"SERIES_ADD_ANNOTATION":                 EDIT_SERIES_ADD_ANNOTATION,
+	"SERIES_CREATE":                         EDIT_SERIES_CREATE,
+	"SERIES_DELETE":                         EDIT_SERIES_DELETE,
+	"SERIES_DELETE_ALIAS":                   EDIT_SERIES_DELETE_ALIAS,
+	"SERIES_EDIT":                           EDIT_SERIES_EDIT,
+	"SERIES_EDIT_ALIAS":                     EDIT_SERIES_EDIT_ALIAS,
+	"SERIES_MERGE":                          EDIT_SERIES_MERGE,
+	"SET_TRACK_LENGTHS":                     EDIT_SET_TRACK_LENGTHS,
+	"URL_EDIT":                              EDIT_URL_EDIT,
+	"WIKIDOC_CHANGE":                        EDIT_WIKIDOC_CHANGE,
+	"WORK_ADD_ALIAS":                        EDIT_WORK_ADD_ALIAS,
+	"WORK_ADD_ANNOTATION":                   EDIT_WORK_ADD_ANNOTATION,
+	"WORK_ADD_ISWCS":                        EDIT_WORK_ADD_ISWCS,
+	"WORK_CREATE":                           EDIT_WORK_CREATE,
+	"WORK_DELETE":                           EDIT_WORK_DELETE,
+	"WORK_DELETE_ALIAS":                     EDIT_WORK_DELETE_ALIAS,
+	"WORK_EDIT":                             EDIT_WORK_EDIT,
+	"WORK_EDIT_ALIAS":                       EDIT_WORK_EDIT_ALIAS,
+	"WORK_MERGE":                            EDIT_WORK_MERGE,
+	"WORK_REMOVE_ISWC":                      EDIT_WORK_REMOVE_ISWC,
+}
+
+// editTypeInfos contains the entity and action for each edit type.
+var editTypeInfos = map[EditType][2]string{
+	EDIT_AREA_ADD_ALIAS:                        {"AREA", "ADD_ALIAS"},
+	EDIT_AREA_ADD_ANNOTATION:                   {"AREA", "ADD_ANNOTATION"},
+	EDIT_AREA_CREATE:                           {"AREA", "CREATE"},
+	EDIT_AREA_DELETE:                           {"AREA", "DELETE"},
+	EDIT_AREA_DELETE_ALIAS:                     {"AREA", "DELETE_ALIAS"},
+	EDIT_AREA_EDIT:                             {"AREA", "EDIT"},
+	EDIT_AREA_EDIT_ALIAS:                       {"AREA", "EDIT_ALIAS"},
+	EDIT_AREA_MERGE:                            {"AREA", "MERGE"},
+	EDIT_ARTIST_ADD_ALIAS:                      {"ARTIST", "ADD_ALIAS"},
+	EDIT_ARTIST_ADD_ANNOTATION:                 {"ARTIST", "ADD_ANNOTATION"},
+	EDIT_ARTIST_CREATE:                         {"ARTIST", "CREATE"},
+	EDIT_ARTIST_DELETE:                         {"ARTIST", "DELETE"},
+	EDIT_ARTIST_DELETE_ALIAS:                   {"ARTIST", "DELETE_ALIAS"},
+	EDIT_ARTIST_EDIT:                           {"ARTIST", "EDIT"},
+	EDIT_ARTIST_EDITCREDIT:                     {"ARTIST", "EDITCREDIT"},
+	EDIT_ARTIST_EDIT_ALIAS:                     {"ARTIST", "EDIT_ALIAS"},
+	EDIT_ARTIST_MERGE:                          {"ARTIST", "MERGE"},
+	EDIT_EVENT_ADD_ALIAS:                       {"EVENT", "ADD_ALIAS"},
+	EDIT_EVENT_ADD_ANNOTATION:                  {"EVENT", "ADD_ANNOTATION"},
+	EDIT_EVENT_CREATE:                          {"EVENT", "CREATE"},
+	EDIT_EVENT_DELETE:                          {"EVENT", "DELETE"},
+	EDIT_EVENT_DELETE_ALIAS:                    {"EVENT", "DELETE_ALIAS"},
+	EDIT_EVENT_EDIT:                            {"EVENT", "EDIT"},
+	EDIT_EVENT_EDIT_ALIAS:                      {"EVENT", "EDIT_ALIAS"},
+	EDIT_EVENT_MERGE:                           {"EVENT", "MERGE"},
+	EDIT_GENRE_ADD_ALIAS:                       {"GENRE", "ADD_ALIAS"},
+	EDIT_GENRE_ADD_ANNOTATION:                  {"GENRE", "ADD_ANNOTATION"},
+	EDIT_GENRE_CREATE:                          {"GENRE", "CREATE"},
+	EDIT_GENRE_DELETE:                          {"GENRE", "DELETE"},
+	EDIT_GENRE_DELETE_ALIAS:                    {"GENRE", "DELETE_ALIAS"},
+	EDIT_GENRE_EDIT:                            {"GENRE", "EDIT"},
+	EDIT_GENRE_EDIT_ALIAS:                      {"GENRE", "EDIT_ALIAS"},
+	EDIT_HISTORIC_ADD_DISCID:                   {"MEDIUM", "ADD_DISCID"},
+	EDIT_HISTORIC_ADD_LINK:                     {"RELATIONSHIP", "ADD"},
+	EDIT_HISTORIC_ADD_RELEASE:                  {"RELEASE", "ADD"},
+	EDIT_HISTORIC_ADD_RELEASE_ANNOTATION:       {"RELEASE", "ADD_ANNOTATION"},
+	EDIT_HISTORIC_ADD_RELEASE_EVENTS:           {"RELEASE", "ADD_EVENTS"},
+	EDIT_HISTORIC_ADD_TRACK:                    {"RECORDING", "ADD"},
+	EDIT_HISTORIC_ADD_TRACK_KV:                 {"RECORDING", "ADD_KV"},
+	EDIT_HISTORIC_CHANGE_ARTIST_QUALITY:        {"ARTIST", "CHANGE_QUALITY"},
+	EDIT_HISTORIC_CHANGE_RELEASE_GROUP:         {"RELEASE", "CHANGE_RELEASEGROUP"},
+	EDIT_HISTORIC_CHANGE_RELEASE_QUALITY:       {"RELEASE", "CHANGE_QUALITY"},
+	EDIT_HISTORIC_CHANGE_TRACK_ARTIST:          {"RECORDING", "CHANGE_ARTIST"},
+	EDIT_HISTORIC_EDIT_LINK:                    {"RELATIONSHIP", "EDIT"},
+	EDIT_HISTORIC_EDIT_LINK_TYPE:               {"RELATIONSHIP", "EDIT_LINK_TYPE"},
+	EDIT_HISTORIC_EDIT_RELEASE_ATTRS:           {"RELEASE", "EDIT_ATTRS"},
+	EDIT_HISTORIC_EDIT_RELEASE_EVENTS:          {"RELEASE", "EDIT_EVENTS"},
+	EDIT_HISTORIC_EDIT_RELEASE_EVENTS_OLD:      {"RELEASE", "EDIT_EVENTS_OLD"},
+	EDIT_HISTORIC_EDIT_RELEASE_LANGUAGE:        {"RELEASE", "EDIT_LANGUAGE"},
+	EDIT_HISTORIC_EDIT_RELEASE_NAME:            {"RELEASE", "EDIT_NAME"},
+	EDIT_HISTORIC_EDIT_TRACKNAME:               {"RECORDING", "EDIT_NAME"},
+	EDIT_HISTORIC_EDIT_TRACKNUM:                {"MEDIUM", "EDIT_TRACKNUM"},
+	EDIT_HISTORIC_EDIT_TRACK_LENGTH:            {"RECORDING", "EDIT_LENGTH"},
+	EDIT_HISTORIC_MAC_TO_SAC:                   {"RELEASE", "MAC_TO_SAC"},
+	EDIT_HISTORIC_MERGE_RELEASE:                {"RELEASE", "MERGE"},
+	EDIT_HISTORIC_MERGE_RELEASE_MAC:            {"RELEASE", "MERGE_MAC"},
+	EDIT_HISTORIC_MOVE_DISCID:                  {"MEDIUM", "MOVE_DISCID"},
+	EDIT_HISTORIC_MOVE_RELEASE:                 {"RELEASE", "MOVE"},
+	EDIT_HISTORIC_REMOVE_DISCID:                {"MEDIUM", "REMOVE_DISCID"},
+	EDIT_HISTORIC_REMOVE_LABEL_ALIAS:           {"LABEL", "REMOVE_ALIAS"},
+	EDIT_HISTORIC_REMOVE_LINK:                  {"RELATIONSHIP", "REMOVE"},
+	EDIT_HISTORIC_REMOVE_LINK_TYPE:             {"RELATIONSHIP", "REMOVE_LINK_TYPE"},
+	EDIT_HISTORIC_REMOVE_RELEASE:               {"RELEASE", "REMOVE"},
+	EDIT_HISTORIC_REMOVE_RELEASES:              {"RELEASE", "REMOVE_MULTIPLE"},
+	EDIT_HISTORIC_REMOVE_RELEASE_EVENTS:        {"RELEASE", "REMOVE_EVENTS"},
+	EDIT_HISTORIC_REMOVE_TRACK:                 {"RECORDING", "REMOVE"},
+	EDIT_HISTORIC_SAC_TO_MAC:                   {"RELEASE", "SAC_TO_MAC"},
+	EDIT_HISTORIC_SET_TRACK_LENGTHS_FROM_CDTOC: {"MEDIUM", "SET_TRACK_LENGTHS_FROM_CDTOC"},
+	EDIT_INSTRUMENT_ADD_ALIAS:                  {"INSTRUMENT", "ADD_ALIAS"},
+	EDIT_INSTRUMENT_ADD_ANNOTATION:             {"INSTRUMENT", "ADD_ANNOTATION"},
+	EDIT_INSTRUMENT_CREATE:                     {"INSTRUMENT", "CREATE"},
+	EDIT_INSTRUMENT_DELETE:                     {"INSTRUMENT", "DELETE"},
+	EDIT_INSTRUMENT_DELETE_ALIAS:               {"INSTRUMENT", "DELETE_ALIAS"},
+	EDIT_INSTRUMENT_EDIT:                       {"INSTRUMENT", "EDIT"},
+	EDIT_INSTRUMENT_EDIT_ALIAS:                 {"INSTRUMENT", "EDIT_ALIAS"},
+	EDIT_INSTRUMENT_MERGE:                      {"INSTRUMENT", "MERGE"},
+	EDIT_LABEL_ADD_ALIAS:                       {"LABEL", "ADD_ALIAS"},
+	EDIT_LABEL_ADD_ANNOTATION:                  {"LABEL", "ADD_ANNOTATION"},
+	EDIT_LABEL_CREATE:                          {"LABEL", "CREATE"},
+	EDIT_LABEL_DELETE:                          {"LABEL", "DELETE"},
+	EDIT_LABEL_DELETE_ALIAS:                    {"LABEL", "DELETE_ALIAS"},
+	EDIT_LABEL_EDIT:                            {"LABEL", "EDIT"},
+	EDIT_LABEL_EDIT_ALIAS:                      {"LABEL", "EDIT_ALIAS"},
+	EDIT_LABEL_MERGE:                           {"LABEL", "MERGE"},
+	EDIT_MEDIUM_ADD_DISCID:                     {"MEDIUM", "ADD_DISCID"},
+	EDIT_MEDIUM_CREATE:                         {"MEDIUM", "CREATE"},
+	EDIT_MEDIUM_DELETE:                         {"MEDIUM", "DELETE"},
+	EDIT_MEDIUM_EDIT:                           {"MEDIUM", "EDIT"},
+	EDIT_MEDIUM_MOVE_DISCID:                    {"MEDIUM", "MOVE_DISCID"},
+	EDIT_MEDIUM_REMOVE_DISCID:                  {"MEDIUM", "REMOVE_DISCID"},
+	EDIT_PLACE_ADD_ALIAS:                       {"PLACE", "ADD_ALIAS"},
+	EDIT_PLACE_ADD_ANNOTATION:                  {"PLACE", "ADD_ANNOTATION"},
+	EDIT_PLACE_CREATE:                          {"PLACE", "CREATE"},
+	EDIT_PLACE_DELETE:                          {"PLACE", "DELETE"},
+	EDIT_PLACE_DELETE_ALIAS:                    {"PLACE", "DELETE_ALIAS"},
+	EDIT_PLACE_EDIT:                            {"PLACE", "EDIT"},
+	EDIT_PLACE_EDIT_ALIAS:                      {"PLACE", "EDIT_ALIAS"},
+	EDIT_PLACE_MERGE:                           {"PLACE", "MERGE"},
+	EDIT_RECORDING_ADD_ALIAS:                   {"RECORDING", "ADD_ALIAS"},
+	EDIT_RECORDING_ADD_ANNOTATION:              {"RECORDING", "ADD_ANNOTATION"},
+	EDIT_RECORDING_ADD_ISRCS:                   {"RECORDING", "ADD_ISRCS"},
+	EDIT_RECORDING_CREATE:                      {"RECORDING", "CREATE"},
+	EDIT_RECORDING_DELETE:                      {"RECORDING", "DELETE"},
+	EDIT_RECORDING_DELETE_ALIAS:                {"RECORDING", "DELETE_ALIAS"},
+	EDIT_RECORDING_EDIT:                        {"RECORDING", "EDIT"},
+	EDIT_RECORDING_EDIT_ALIAS:                  {"RECORDING", "EDIT_ALIAS"},
+	EDIT_RECORDING_MERGE:                       {"RECORDING", "MERGE"},
+	EDIT_RECORDING_REMOVE_ISRC:                 {"RECORDING", "REMOVE_ISRC"},
+	EDIT_RELATIONSHIPS_REORDER:                 {"RELATIONSHIP", "REORDER"},
+	EDIT_RELATIONSHIP_ADD_ATTRIBUTE:            {"RELATIONSHIP", "ADD_ATTRIBUTE"},
+	EDIT_RELATIONSHIP_ADD_TYPE:                 {"RELATIONSHIP", "ADD_TYPE"},
+	EDIT_RELATIONSHIP_ATTRIBUTE:                {"RELATIONSHIP", "ATTRIBUTE"},
+	EDIT_RELATIONSHIP_CREATE:                   {"RELATIONSHIP", "CREATE"},
+	EDIT_RELATIONSHIP_DELETE:                   {"RELATIONSHIP", "DELETE"},
+	EDIT_RELATIONSHIP_EDIT:                     {"RELATIONSHIP", "EDIT"},
+	EDIT_RELATIONSHIP_EDIT_LINK_TYPE:           {"RELATIONSHIP", "EDIT_LINK_TYPE"},
+	EDIT_RELATIONSHIP_REMOVE_LINK_ATTRIBUTE:    {"RELATIONSHIP", "REMOVE_LINK_ATTRIBUTE"},
+	EDIT_RELATIONSHIP_REMOVE_LINK_TYPE:         {"RELATIONSHIP", "REMOVE_LINK_TYPE"},
+	EDIT_RELEASEGROUP_ADD_ALIAS:                {"RELEASEGROUP", "ADD_ALIAS"},
+	EDIT_RELEASEGROUP_ADD_ANNOTATION:           {"RELEASEGROUP", "ADD_ANNOTATION"},
+	EDIT_RELEASEGROUP_CREATE:                   {"RELEASEGROUP", "CREATE"},
+	EDIT_RELEASEGROUP_DELETE:                   {"RELEASEGROUP", "DELETE"},
+	EDIT_RELEASEGROUP_DELETE_ALIAS:             {"RELEASEGROUP", "DELETE_ALIAS"},
+	EDIT_RELEASEGROUP_EDIT:                     {"RELEASEGROUP", "EDIT"},
+	EDIT_RELEASEGROUP_EDIT_ALIAS:               {"RELEASEGROUP", "EDIT_ALIAS"},
+	EDIT_RELEASEGROUP_MERGE:                    {"RELEASEGROUP", "MERGE"},
+	EDIT_RELEASEGROUP_SET_COVER_ART:            {"RELEASEGROUP", "SET_COVER_ART"},
+	EDIT_RELEASE_ADDRELEASELABEL:               {"RELEASE", "ADDRELEASELABEL"},
+	EDIT_RELEASE_ADD_ALIAS:                     {"RELEASE", "ADD_ALIAS"},
+	EDIT_RELEASE_ADD_ANNOTATION:                {"RELEASE", "ADD_ANNOTATION"},
+	EDIT_RELEASE_ADD_COVER_ART:                 {"RELEASE", "ADD_COVER_ART"},
+	EDIT_RELEASE_ARTIST:                        {"RELEASE", "ARTIST"},
+	EDIT_RELEASE_CHANGE_QUALITY:                {"RELEASE", "CHANGE_QUALITY"},
+	EDIT_RELEASE_CREATE:                        {"RELEASE", "CREATE"},
+	EDIT_RELEASE_DELETE:                        {"RELEASE", "DELETE"},
+	EDIT_RELEASE_DELETERELEASELABEL:            {"RELEASE", "DELETERELEASELABEL"},
+	EDIT_RELEASE_DELETE_ALIAS:                  {"RELEASE", "DELETE_ALIAS"},
+	EDIT_RELEASE_EDIT:                          {"RELEASE", "EDIT"},
+	EDIT_RELEASE_EDITRELEASELABEL:              {"RELEASE", "EDITRELEASELABEL"},
+	EDIT_RELEASE_EDIT_ALIAS:                    {"RELEASE", "EDIT_ALIAS"},
+	EDIT_RELEASE_EDIT_BARCODES:                 {"RELEASE", "EDIT_BARCODES"},
+	EDIT_RELEASE_EDIT_COVER_ART:                {"RELEASE", "EDIT_COVER_ART"},
+	EDIT_RELEASE_MERGE:                         {"RELEASE", "MERGE"},
+	EDIT_RELEASE_MOVE:                          {"RELEASE", "MOVE"},
+	EDIT_RELEASE_REMOVE_COVER_ART:              {"RELEASE", "REMOVE_COVER_ART"},
+	EDIT_RELEASE_REORDER_COVER_ART:             {"RELEASE", "REORDER_COVER_ART"},
+	EDIT_RELEASE_REORDER_MEDIUMS:               {"RELEASE", "REORDER_MEDIUMS"},
+	EDIT_SERIES_ADD_ALIAS:                      {"SERIES", "ADD_ALIAS"},
+	EDIT_SERIES_ADD_ANNOTATION:                 {"SERIES", "ADD_ANNOTATION"},
+	EDIT_SERIES_CREATE:                         {"SERIES", "CREATE"},
+	EDIT_SERIES_DELETE:                         {"SERIES", "DELETE"},
+	EDIT_SERIES_DELETE_ALIAS:                   {"SERIES", "DELETE_ALIAS"},
+	EDIT_SERIES_EDIT:                           {"SERIES", "EDIT"},
+	EDIT_SERIES_EDIT_ALIAS:                     {"SERIES", "EDIT_ALIAS"},
+	EDIT_SERIES_MERGE:                          {"SERIES", "MERGE"},
+	EDIT_SET_TRACK_LENGTHS:                     {"MEDIUM", "SET_TRACK_LENGTHS"},
+	EDIT_URL_EDIT:                              {"URL", "EDIT"},
+	EDIT_WIKIDOC_CHANGE:                        {"WIKIDOC", "CHANGE"},
+	EDIT_WORK_ADD_ALIAS:                        {"WORK", "ADD_ALIAS"},
+	EDIT_WORK_ADD_ANNOTATION:                   {"WORK", "ADD_ANNOTATION"},
+	EDIT_WORK_ADD_ISWCS:                        {"WORK", "ADD_ISWCS"},
+	EDIT_WORK_CREATE:                           {"WORK", "CREATE"},
+	EDIT_WORK_DELETE:                           {"WORK", "DELETE"},
+	EDIT_WORK_DELETE_ALIAS:                     {"WORK", "DELETE_ALIAS"},
+	EDIT_WORK_EDIT:                             {"WORK", "EDIT"},
+	EDIT_WORK_EDIT_ALIAS:                       {"WORK", "EDIT_ALIAS"},
+	EDIT_WORK_MERGE:                            {"WORK", "MERGE"},
+	EDIT_WORK_REMOVE_ISWC:                      {"WORK", "REMOVE_ISWC"},
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbstats
+
+import "testing"
+
+func TestEditTypeInfo(t *testing.T) {
+	for _, tc := range []struct {
+		et             EditType
+		entity, action string
+	}{
+		{EDIT_ARTIST_CREATE, "ARTIST", "CREATE"},
+		{EDIT_RELEASEGROUP_ADD_ALIAS, "RELEASEGROUP", "ADD_ALIAS"},
+		{EDIT_RELEASE_CREATE, "RELEASE", "CREATE"},
+		{EDIT_RELATIONSHIPS_REORDER, "RELATIONSHIP", "REORDER"},
+		{EDIT_HISTORIC_ADD_RELEASE, "RELEASE", "ADD"},
+		{EDIT_HISTORIC_EDIT_TRACKNAME, "RECORDING", "EDIT_NAME"},
+		{EDIT_HISTORIC_ADD_DISCID, "MEDIUM", "ADD_DISCID"},
+		{EDIT_HISTORIC_REMOVE_LINK, "RELATIONSHIP", "REMOVE"},
+		{EditType(-1), "", ""},
+	} {
+		if entity, action := EditTypeInfo(tc.et); entity != tc.entity || action != tc.action {
+			t.Errorf("EditTypeInfo(%d) = %q, %q; want %q, %q", tc.et, entity, action, tc.entity, tc.action)
+		}
+	}
+
+	// Every known type should have an entity, including historic types.
+	for _, et := range EditTypes() {
+		if entity, action := EditTypeInfo(et); entity == "" || action == "" {
+			t.Errorf("EditTypeInfo(%v) = %q, %q", EditTypeName(et), entity, action)
+		}
+	}
+}
+
+func TestCheckEditTypes(t *testing.T) {
+	if errs := CheckEditTypes(); len(errs) != 0 {
+		t.Errorf("CheckEditTypes() = %v", errs)
+	}
+
+	// Make sure that problems are actually detected.
+	for _, tc := range []struct {
+		desc   string
+		modify func() (restore func())
+	}{
+		{"missing name in name map", func() func() {
+			delete(editTypeIDs, "ARTIST_CREATE")
+			return func() { editTypeIDs["ARTIST_CREATE"] = EDIT_ARTIST_CREATE }
+		}},
+		{"extra name in name map", func() func() {
+			editTypeIDs["BOGUS"] = EDIT_ARTIST_CREATE
+			return func() { delete(editTypeIDs, "BOGUS") }
+		}},
+		{"alias cycle", func() func() {
+			editTypeAliases["RELEASEGROUP"] = "RELEASE_GROUP"
+			return func() { delete(editTypeAliases, "RELEASEGROUP") }
+		}},
+		{"self alias", func() func() {
+			editTypeAliases["ARTIST"] = "ARTIST"
+			return func() { delete(editTypeAliases, "ARTIST") }
+		}},
+		{"unused alias", func() func() {
+			editTypeAliases["FOO"] = "BAR"
+			return func() { delete(editTypeAliases, "FOO") }
+		}},
+		{"missing entity", func() func() {
+			orig := editTypeInfos[EDIT_HISTORIC_ADD_TRACK]
+			editTypeInfos[EDIT_HISTORIC_ADD_TRACK] = [2]string{"", "ADD"}
+			return func() { editTypeInfos[EDIT_HISTORIC_ADD_TRACK] = orig }
+		}},
+		{"info for unknown type", func() func() {
+			editTypeInfos[EditType(-1)] = [2]string{"ARTIST", "CREATE"}
+			return func() { delete(editTypeInfos, EditType(-1)) }
+		}},
+	} {
+		restore := tc.modify()
+		if errs := CheckEditTypes(); len(errs) == 0 {
+			t.Errorf("CheckEditTypes() didn't report %v", tc.desc)
+		}
+		restore()
+	}
+}
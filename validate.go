@@ -0,0 +1,264 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// maxFileProblems is the maximum number of problems reported for a single file by ValidateDir.
+const maxFileProblems = 10
+
+// ValidateDir checks the stats directory dir (as written by read-mbdump) for problems
+// that would otherwise only show up as strange results: missing or outdated metadata,
+// files that can't be parsed, bad or duplicate records, editors whose information
+// differs between files or is missing from EditorsFile, and an inconsistent editor
+// index. An error describing each problem is returned; the returned slice is empty
+// if no problems were found.
+func ValidateDir(dir string) []error {
+	var pl problemList
+
+	mp := filepath.Join(dir, MetadataFile)
+	_, err := os.Stat(mp)
+	hasMetadata := !os.IsNotExist(err)
+	if !hasMetadata {
+		pl.add(mp, "missing")
+	}
+	md, err := ReadMetadata(dir)
+	if err != nil {
+		pl.errs = append(pl.errs, err)
+		return pl.finish() // records can't be decoded without knowing the version
+	}
+	if hasMetadata && md.Version < StatsVersion {
+		pl.add(mp, "stats version %d is older than current version %d (rerun read-mbdump)",
+			md.Version, StatsVersion)
+	}
+
+	ep := filepath.Join(dir, EditorsFile)
+	editorIDs := make(map[EditorID]bool) // IDs in EditorsFile
+	if err := forEachRecord(ep, func(id EditorID) {
+		if id <= 0 {
+			pl.add(ep, "record with bad editor ID %d", id)
+		} else if editorIDs[id] {
+			pl.add(ep, "duplicate records for editor %d", id)
+		}
+		editorIDs[id] = true
+	}); err != nil && !os.IsNotExist(err) {
+		pl.errs = append(pl.errs, err)
+	}
+	editors, err := ReadEditors(dir)
+	if err != nil {
+		editors = nil // already reported above
+	}
+	// Newer stats files omit editor information, so it must come from EditorsFile
+	// (if the editor table was read at all).
+	checkEditors := md.Version >= 2 && len(editorIDs) > 0
+	missingEditors := make(map[EditorID]bool) // reported as missing from EditorsFile
+
+	files, err := EditorStatsFiles(dir)
+	if err != nil {
+		pl.errs = append(pl.errs, err)
+		return pl.finish()
+	}
+	if files.Len() == 0 {
+		pl.add(dir, "no editor stats files")
+	}
+
+	// editorInfo holds information that should be the same in all of an editor's records.
+	type editorInfo struct {
+		name    string
+		bot     bool
+		created time.Time
+		per     Period // period in which the editor was first seen
+	}
+	infos := make(map[EditorID]editorInfo)
+	yearIDs := make(map[int]map[EditorID]bool) // IDs in yearly files, keyed by year
+
+	files.Each(func(per Period, p string) error {
+		// Months follow the years containing them, so the yearly IDs are already known.
+		var yids map[EditorID]bool
+		if per.IsMonth() {
+			yids = yearIDs[md.Year(md.PeriodStart(per))]
+		}
+		ids := make(map[EditorID]bool)
+		if err := readEditorStatsFile(p, md.Version, editors, func(es EditorStats) error {
+			if es.ID <= 0 {
+				pl.add(p, "record with bad editor ID %d", es.ID)
+				return nil
+			}
+			if ids[es.ID] {
+				pl.add(p, "duplicate records for editor %d", es.ID)
+			}
+			ids[es.ID] = true
+			if yids != nil && !yids[es.ID] {
+				pl.add(p, "editor %d missing from yearly stats", es.ID)
+			}
+			if checkEditors && !editorIDs[es.ID] && !missingEditors[es.ID] {
+				pl.add(p, "editor %d missing from %v", es.ID, EditorsFile)
+				missingEditors[es.ID] = true
+			}
+
+			if len(es.Edits) == 0 {
+				pl.add(p, "editor %d has no edits", es.ID)
+			}
+			for et, n := range es.Edits {
+				if n <= 0 {
+					pl.add(p, "editor %d has %d %v edit(s)", es.ID, n, EditTypeName(et))
+				}
+			}
+			for et, n := range es.AutoEdits {
+				if n > es.Edits[et] {
+					pl.add(p, "editor %d has %d %v autoedit(s) but %d edit(s)",
+						es.ID, n, EditTypeName(et), es.Edits[et])
+				}
+			}
+
+			info := editorInfo{name: es.Name, bot: es.Bot, created: es.Created, per: per}
+			if prev, ok := infos[es.ID]; !ok {
+				infos[es.ID] = info
+			} else if info.name != prev.name || info.bot != prev.bot || !info.created.Equal(prev.created) {
+				pl.add(p, "editor %d is %q (bot %v, created %v) but was %q (bot %v, created %v) in %v",
+					es.ID, info.name, info.bot, info.created.Format(time.RFC3339),
+					prev.name, prev.bot, prev.created.Format(time.RFC3339), prev.per)
+			}
+			return nil
+		}); err != nil {
+			pl.errs = append(pl.errs, err)
+		}
+
+		if !per.IsMonth() {
+			yearIDs[per.Year] = ids
+		}
+		return nil
+	})
+
+	for _, pattern := range []string{"voters-????.json", "notes-????.json"} {
+		paths, err := GlobStats(dir, pattern)
+		if err != nil {
+			pl.errs = append(pl.errs, err)
+			continue
+		}
+		for _, p := range paths {
+			ids := make(map[EditorID]bool)
+			if err := forEachRecord(p, func(id EditorID) {
+				if id <= 0 {
+					pl.add(p, "record with bad editor ID %d", id)
+				} else if ids[id] {
+					pl.add(p, "duplicate records for editor %d", id)
+				}
+				ids[id] = true
+			}); err != nil {
+				pl.errs = append(pl.errs, err)
+			}
+		}
+	}
+
+	validateIndex(dir, yearIDs, &pl)
+	return pl.finish()
+}
+
+// validateIndex checks dir's EditorIndexFile (if present) against yearIDs,
+// which contains the editor IDs from each yearly stats file.
+func validateIndex(dir string, yearIDs map[int]map[EditorID]bool, pl *problemList) {
+	ip := filepath.Join(dir, EditorIndexFile)
+	f, err := os.Open(ip)
+	if os.IsNotExist(err) {
+		return
+	} else if err != nil {
+		pl.errs = append(pl.errs, err)
+		return
+	}
+	defer f.Close()
+
+	counts := make(map[int]int) // number of indexed editors, keyed by year
+	dec := json.NewDecoder(f)
+	for {
+		var ie EditorIndexEntry
+		if err := dec.Decode(&ie); err == io.EOF {
+			break
+		} else if err != nil {
+			pl.add(ip, "%v", err)
+			return
+		}
+		for year := range ie.Offsets {
+			if ids, ok := yearIDs[year]; !ok {
+				pl.add(ip, "editor %d indexed in %d, which has no stats", ie.ID, year)
+			} else if !ids[ie.ID] {
+				pl.add(ip, "editor %d indexed in %d but missing from stats", ie.ID, year)
+			}
+			counts[year]++
+		}
+	}
+	years := make([]int, 0, len(yearIDs))
+	for year := range yearIDs {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+	for _, year := range years {
+		if n := len(yearIDs[year]); counts[year] != n {
+			pl.add(ip, "%d of %d editor(s) indexed in %d", counts[year], n, year)
+		}
+	}
+}
+
+// forEachRecord calls fn with the editor ID from each JSON object in the stats
+// file at p, e.g. a file containing VoterStats or NoteStats objects.
+func forEachRecord(p string, fn func(id EditorID)) error {
+	f, _, err := OpenStatsFile(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	for {
+		var rec struct {
+			ID EditorID `json:"id"`
+		}
+		if err := dec.Decode(&rec); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%v: %v", p, err)
+		}
+		fn(rec.ID)
+	}
+}
+
+// problemList accumulates problems found by ValidateDir.
+// At most maxFileProblems problems are reported for each file.
+type problemList struct {
+	errs   []error
+	files  []string       // files with problems, in the order they were first seen
+	counts map[string]int // number of problems, keyed by file
+}
+
+// add records a problem in the named file.
+func (pl *problemList) add(file, format string, args ...interface{}) {
+	if pl.counts == nil {
+		pl.counts = make(map[string]int)
+	}
+	n := pl.counts[file]
+	if n == 0 {
+		pl.files = append(pl.files, file)
+	}
+	pl.counts[file] = n + 1
+	if n < maxFileProblems {
+		pl.errs = append(pl.errs, fmt.Errorf("%v: %v", file, fmt.Sprintf(format, args...)))
+	}
+}
+
+// finish returns all recorded problems, noting any that were omitted.
+func (pl *problemList) finish() []error {
+	for _, file := range pl.files {
+		if n := pl.counts[file]; n > maxFileProblems {
+			pl.errs = append(pl.errs, fmt.Errorf("%v: %d more problem(s)", file, n-maxFileProblems))
+		}
+	}
+	return pl.errs
+}
@@ -0,0 +1,196 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/derat/mbstats/internal/fileutil"
+)
+
+// EditorStatsFile returns the name of the file within a stats directory that
+// contains EditorStats objects for per, e.g. "editors-2020.json" for 2020
+// or "editors-2020-03.json" for March 2020.
+func EditorStatsFile(per Period) string {
+	return fmt.Sprintf("editors-%v.json", per)
+}
+
+// EditorStatsWriter writes EditorStats objects in the format read by ReadEditorStats.
+type EditorStatsWriter struct {
+	cw  *countWriter
+	enc *json.Encoder
+}
+
+// NewEditorStatsWriter returns a new EditorStatsWriter that writes to w.
+func NewEditorStatsWriter(w io.Writer) *EditorStatsWriter {
+	cw := &countWriter{w: w}
+	return &EditorStatsWriter{cw: cw, enc: json.NewEncoder(cw)}
+}
+
+// editorCounts is the subset of EditorStats written to stats files.
+// Editor information is written separately to EditorsFile.
+type editorCounts struct {
+	ID        EditorID           `json:"id"`
+	Edits     map[EditType]int32 `json:"edits"`
+	AutoEdits map[EditType]int32 `json:"autoEdits,omitempty"`
+}
+
+// Write writes es's ID and edit counts.
+func (w *EditorStatsWriter) Write(es *EditorStats) error {
+	return w.enc.Encode(editorCounts{ID: es.ID, Edits: es.Edits, AutoEdits: es.AutoEdits})
+}
+
+// Offset returns the number of bytes that have been written,
+// i.e. the byte offset at which the next record will be written.
+func (w *EditorStatsWriter) Offset() int64 {
+	return w.cw.nbytes
+}
+
+// WriteEditorStats writes stats to w in the format read by ReadEditorStats.
+func WriteEditorStats(w io.Writer, stats []EditorStats) error {
+	sw := NewEditorStatsWriter(w)
+	for i := range stats {
+		if err := sw.Write(&stats[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EditorStatsDirWriter writes per-period files named by EditorStatsFile to a
+// stats directory. An index of the records in yearly files is written to
+// EditorIndexFile and the directory's Metadata is written to MetadataFile
+// when the writer is closed. Each file is written to a temporary path and
+// then renamed so that partially-written files are never left in dir.
+type EditorStatsDirWriter struct {
+	dir   string
+	md    Metadata
+	index map[EditorID]*EditorIndexEntry
+}
+
+// NewEditorStatsDirWriter returns a new EditorStatsDirWriter that writes to dir,
+// which is created if it doesn't already exist. md describes how the stats were
+// generated; its Version field is ignored and StatsVersion is written instead.
+func NewEditorStatsDirWriter(dir string, md *Metadata) (*EditorStatsDirWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	dw := &EditorStatsDirWriter{dir: dir, md: *md, index: make(map[EditorID]*EditorIndexEntry)}
+	dw.md.Version = StatsVersion
+	return dw, nil
+}
+
+// WritePeriod writes stats to the file for per.
+// The file is replaced if it already exists.
+func (dw *EditorStatsDirWriter) WritePeriod(per Period, stats []EditorStats) error {
+	of, err := fileutil.CreateOutputFile(filepath.Join(dw.dir, EditorStatsFile(per)))
+	if err != nil {
+		return err
+	}
+	indexed := !per.IsMonth() // monthly files aren't indexed
+
+	sw := NewEditorStatsWriter(of)
+	for i := range stats {
+		es := &stats[i]
+		if indexed {
+			ie := dw.index[es.ID]
+			if ie == nil {
+				ie = &EditorIndexEntry{ID: es.ID, Name: es.Name, Offsets: make(map[int]int64)}
+				dw.index[es.ID] = ie
+			}
+			ie.Offsets[per.Year] = sw.Offset()
+		}
+		if err := sw.Write(es); err != nil {
+			of.Abort()
+			return err
+		}
+	}
+	return of.Commit()
+}
+
+// Close writes the index of the records in yearly files, sorted by ascending
+// editor ID, followed by the metadata file. The index is only written if
+// yearly files were written.
+func (dw *EditorStatsDirWriter) Close() error {
+	if err := dw.writeIndex(); err != nil {
+		return err
+	}
+	return WriteMetadata(dw.dir, &dw.md)
+}
+
+// writeIndex writes dw.index to EditorIndexFile if it is non-empty.
+func (dw *EditorStatsDirWriter) writeIndex() error {
+	if len(dw.index) == 0 {
+		return nil
+	}
+	entries := make([]*EditorIndexEntry, 0, len(dw.index))
+	for _, ie := range dw.index {
+		entries = append(entries, ie)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+
+	of, err := fileutil.CreateOutputFile(filepath.Join(dw.dir, EditorIndexFile))
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(of)
+	for _, ie := range entries {
+		if err := enc.Encode(ie); err != nil {
+			of.Abort()
+			return err
+		}
+	}
+	return of.Commit()
+}
+
+// WriteMetadata writes md to dir's MetadataFile, creating dir if needed.
+// The file is replaced if it already exists.
+func WriteMetadata(dir string, md *Metadata) error {
+	of, err := fileutil.CreateOutputFile(filepath.Join(dir, MetadataFile))
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(of).Encode(md); err != nil {
+		of.Abort()
+		return err
+	}
+	return of.Commit()
+}
+
+// WriteEditors writes editors to dir's EditorsFile in ascending order by ID.
+// The file is replaced if it already exists.
+func WriteEditors(dir string, editors []Editor) error {
+	sorted := append([]Editor(nil), editors...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	of, err := fileutil.CreateOutputFile(filepath.Join(dir, EditorsFile))
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(of)
+	for i := range sorted {
+		if err := enc.Encode(&sorted[i]); err != nil {
+			of.Abort()
+			return err
+		}
+	}
+	return of.Commit()
+}
+
+// countWriter wraps an io.Writer and counts the number of bytes that have been written.
+type countWriter struct {
+	w      io.Writer
+	nbytes int64
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.nbytes += int64(n)
+	return n, err
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 Daniel Erat.
+// All rights reserved.
+
+package mbstats
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEditorStatsDirWriter_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	created := time.Date(2015, 4, 1, 0, 0, 0, 0, time.UTC)
+	active := time.Date(2021, 6, 2, 0, 0, 0, 0, time.UTC)
+	editors := []Editor{
+		{ID: 1, Name: "alice", Created: created, Active: active},
+		{ID: 2, Name: "bot", Created: created, Privs: BotFlag},
+	}
+	if err := WriteEditors(dir, editors); err != nil {
+		t.Fatal("WriteEditors failed: ", err)
+	}
+
+	// Editor 3 isn't in editors.json, so only its ID and counts are read back.
+	type rec struct {
+		per Period
+		es  EditorStats
+	}
+	alice := EditorStats{ID: 1, Name: "alice", Created: created, Active: active}
+	bot := EditorStats{ID: 2, Name: "bot", Created: created, Bot: true}
+	withEdits := func(es EditorStats, edits, auto map[EditType]int32) EditorStats {
+		es.Edits, es.AutoEdits = edits, auto
+		return es
+	}
+	want := []rec{
+		{YearPeriod(2020), withEdits(alice, map[EditType]int32{1: 3, 2: 1}, map[EditType]int32{2: 1})},
+		{YearPeriod(2020), withEdits(bot, map[EditType]int32{5: 100}, nil)},
+		{MonthPeriod(2020, time.March), withEdits(alice, map[EditType]int32{1: 2}, nil)},
+		{YearPeriod(2021), withEdits(alice, map[EditType]int32{1: 1}, nil)},
+		{YearPeriod(2021), EditorStats{ID: 3, Edits: map[EditType]int32{3: 4}}},
+	}
+
+	dumpTime := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	md := Metadata{YearStartMonth: time.July, DumpTime: dumpTime}
+	dw, err := NewEditorStatsDirWriter(dir, &md)
+	if err != nil {
+		t.Fatal("NewEditorStatsDirWriter failed: ", err)
+	}
+	byPer := make(map[Period][]EditorStats)
+	var pers []Period
+	for _, r := range want {
+		if _, ok := byPer[r.per]; !ok {
+			pers = append(pers, r.per)
+		}
+		byPer[r.per] = append(byPer[r.per], r.es)
+	}
+	for _, per := range pers {
+		if err := dw.WritePeriod(per, byPer[per]); err != nil {
+			t.Fatalf("WritePeriod(%v) failed: %v", per, err)
+		}
+	}
+	if err := dw.Close(); err != nil {
+		t.Fatal("Close failed: ", err)
+	}
+
+	if got, err := ReadMetadata(dir); err != nil {
+		t.Error("ReadMetadata failed: ", err)
+	} else if exp := (Metadata{YearStartMonth: time.July, Version: StatsVersion, DumpTime: dumpTime}); !reflect.DeepEqual(*got, exp) {
+		t.Errorf("ReadMetadata returned %+v; want %+v", *got, exp)
+	}
+
+	var got []rec
+	if err := WalkEditorStats(dir, func(per Period, es EditorStats) error {
+		got = append(got, rec{per, es})
+		return nil
+	}); err != nil {
+		t.Fatal("WalkEditorStats failed: ", err)
+	}
+	// WalkEditorStats visits periods in ascending order, so the March 2020
+	// record comes after the yearly 2020 records.
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkEditorStats returned:\n%+v\nwant:\n%+v", got, want)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			t.Errorf("Temporary file %v left in dir", e.Name())
+		}
+	}
+}
+
+func TestEditorStatsDirWriter_NoYearlyFiles(t *testing.T) {
+	dir := t.TempDir()
+	dw, err := NewEditorStatsDirWriter(dir, &Metadata{})
+	if err != nil {
+		t.Fatal("NewEditorStatsDirWriter failed: ", err)
+	}
+	if err := dw.WritePeriod(MonthPeriod(2020, time.January), nil); err != nil {
+		t.Fatal("WritePeriod failed: ", err)
+	}
+	if err := dw.Close(); err != nil {
+		t.Fatal("Close failed: ", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, EditorIndexFile)); !os.IsNotExist(err) {
+		t.Errorf("%v was written without yearly files (err = %v)", EditorIndexFile, err)
+	}
+	if md, err := ReadMetadata(dir); err != nil {
+		t.Error("ReadMetadata failed: ", err)
+	} else if md.Version != StatsVersion {
+		t.Errorf("ReadMetadata returned version %v; want %v", md.Version, StatsVersion)
+	}
+}